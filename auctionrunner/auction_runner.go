@@ -1,6 +1,7 @@
 package auctionrunner
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -12,6 +13,53 @@ import (
 	"github.com/cloudfoundry/gunk/workpool"
 )
 
+const maxStaleStateRetries = 3
+const defaultStaleStateTTL = 30 * time.Second
+
+type Config struct {
+	// StateFetchTimeout bounds how long an auction waits for cells to report
+	// their state.  The auction goes ahead with the cells that answered in
+	// time.  Zero waits for every cell.
+	StateFetchTimeout time.Duration
+
+	// HedgeStateAfter sends a second State request to cells that have not
+	// answered within it.  Zero disables hedging.
+	HedgeStateAfter time.Duration
+
+	// StaleStateAfter is a soft deadline for state fetches: cells that have not
+	// answered by then are scheduled from the state they reported within the
+	// last StaleStateTTL, which defaults to 30 seconds.  Zero disables the
+	// fallback.
+	StaleStateAfter time.Duration
+	StaleStateTTL   time.Duration
+
+	// CircuitBreaker keeps cells that keep failing out of auctions for a
+	// while.  It is disabled by default.
+	CircuitBreaker CircuitBreakerConfig
+
+	// Deterministic schedules identical requests on identical cells in the
+	// same way, settling equal scores with TieBreak, or TieBreakByGuid if it is
+	// nil.  See Scheduler.Deterministic.
+	Deterministic bool
+	TieBreak      TieBreakPolicy
+
+	// ScoringShards scores each auction's candidate cells in up to this many
+	// shards on the work pool.  Placements are the same as scoring
+	// sequentially, which is what zero or one does.
+	ScoringShards int
+
+	// AuditSink, if set, is given an AuditRecord for every completed auction.
+	// Auditing records the scheduler's decisions, which bypasses its index:
+	// every auction scores every cell, at O(cells) per auction.  See
+	// Scheduler.RecordDecisions.
+	AuditSink AuditSink
+
+	// RoundRecorder, if set, is given the inputs and placements of every
+	// scheduling pass, for Replay.  Like auditing, recording bypasses the
+	// scheduler's index and scores every cell for every auction.
+	RoundRecorder RoundRecorder
+}
+
 type auctionRunner struct {
 	delegate      auctiontypes.AuctionRunnerDelegate
 	metricEmitter auctiontypes.AuctionMetricEmitterDelegate
@@ -19,6 +67,15 @@ type auctionRunner struct {
 	clock         clock.Clock
 	workPool      *workpool.WorkPool
 	logger        lager.Logger
+	config        Config
+	breakers      *CircuitBreakers
+	stateCache    *StateCache
+
+	// settle, if set, is given the results of every auction and returns the
+	// ones that are settled.  Only those are reported to the metric emitter,
+	// the delegate and the audit sink; NewSharded uses it to hand auctions
+	// that failed in one shard to the next.
+	settle func(auctiontypes.AuctionResults) auctiontypes.AuctionResults
 }
 
 func New(
@@ -28,17 +85,57 @@ func New(
 	workPool *workpool.WorkPool,
 	logger lager.Logger,
 ) *auctionRunner {
-	return &auctionRunner{
+	return NewWithConfig(delegate, metricEmitter, clock, workPool, logger, Config{})
+}
+
+func NewWithConfig(
+	delegate auctiontypes.AuctionRunnerDelegate,
+	metricEmitter auctiontypes.AuctionMetricEmitterDelegate,
+	clock clock.Clock,
+	workPool *workpool.WorkPool,
+	logger lager.Logger,
+	config Config,
+) *auctionRunner {
+	runner := &auctionRunner{
 		delegate:      delegate,
 		metricEmitter: metricEmitter,
 		batch:         NewBatch(clock),
 		clock:         clock,
 		workPool:      workPool,
 		logger:        logger,
+		config:        config,
+	}
+
+	if config.StaleStateAfter > 0 {
+		ttl := config.StaleStateTTL
+		if ttl <= 0 {
+			ttl = defaultStaleStateTTL
+		}
+		runner.stateCache = NewStateCache(ttl, clock)
 	}
+
+	if config.CircuitBreaker.FailureThreshold > 0 {
+		runner.breakers = NewCircuitBreakers(config.CircuitBreaker, clock, metricEmitter, logger)
+	}
+
+	return runner
 }
 
 func (a *auctionRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	// cancel in-flight state fetches as soon as we are signaled
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+			close(stop)
+		case <-ctx.Done():
+		}
+	}()
+
 	close(ready)
 
 	var hasWork chan struct{}
@@ -60,13 +157,29 @@ func (a *auctionRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) err
 			}
 			logger.Info("fetched-cell-reps", lager.Data{"cell-reps-count": len(clients)})
 
+			if a.breakers != nil {
+				fetched := len(clients)
+				clients = a.breakers.Admit(clients)
+				if len(clients) < fetched {
+					logger.Info("skipping-cells-with-open-circuit-breakers", lager.Data{"skipped-cell-count": fetched - len(clients)})
+				}
+			}
+
 			hasWork = a.batch.HasWork
 
+			if stopRequested(stop) {
+				logger.Info("stopping-before-auction")
+				return nil
+			}
+
 			logger.Info("fetching-zone-state")
 			fetchStatesStartTime := time.Now()
-			zones := FetchStateAndBuildZones(logger, a.workPool, clients)
+			zones := a.fetchStateAndBuildZones(ctx, logger, clients)
 			fetchStateDuration := time.Since(fetchStatesStartTime)
 			a.metricEmitter.FetchStatesCompleted(fetchStateDuration)
+			if capacityEmitter, ok := a.metricEmitter.(auctiontypes.ZoneCapacityMetricEmitter); ok {
+				capacityEmitter.ZoneCapacitiesFetched(zoneCapacities(zones))
+			}
 			cellCount := 0
 			for zone, cells := range zones {
 				logger.Info("zone-state", lager.Data{"zone": zone, "cell-count": len(cells)})
@@ -78,6 +191,11 @@ func (a *auctionRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) err
 				"duration":            fetchStateDuration.String(),
 			})
 
+			if stopRequested(stop) {
+				logger.Info("stopping-before-auction")
+				return nil
+			}
+
 			logger.Info("fetching-auctions")
 			lrpAuctions, taskAuctions := a.batch.DedupeAndDrain()
 			logger.Info("fetched-auctions", lager.Data{
@@ -95,23 +213,46 @@ func (a *auctionRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) err
 				Tasks: taskAuctions,
 			}
 
-			scheduler := NewScheduler(a.workPool, zones, a.clock)
-			auctionResults := scheduler.Schedule(auctionRequest)
+			var trail *auditTrail
+			if a.config.AuditSink != nil {
+				trail = newAuditTrail()
+			}
+
+			auctionResults := a.schedule(zones, auctionRequest, trail)
+			auctionResults = a.rescheduleStaleWork(ctx, logger, clients, auctionResults, trail)
 			logger.Info("scheduled", lager.Data{
 				"successful-lrp-start-auctions": len(auctionResults.SuccessfulLRPs),
 				"successful-task-auctions":      len(auctionResults.SuccessfulTasks),
 				"failed-lrp-start-auctions":     len(auctionResults.FailedLRPs),
 				"failed-task-auctions":          len(auctionResults.FailedTasks),
+				"already-running-lrp-auctions":  len(auctionResults.AlreadyRunningLRPs),
+				"already-running-task-auctions": len(auctionResults.AlreadyRunningTasks),
 			})
 
+			if a.settle != nil {
+				auctionResults = a.settle(auctionResults)
+			}
+
 			a.metricEmitter.AuctionCompleted(auctionResults)
 			a.delegate.AuctionCompleted(auctionResults)
-		case <-signals:
+			a.audit(trail, auctionResults)
+		case <-stop:
 			return nil
 		}
 	}
 }
 
+// stopRequested lets a signal, such as the one sent when the auctioneer loses
+// its lease, interrupt an auction between steps
+func stopRequested(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
 func (a *auctionRunner) ScheduleLRPsForAuctions(lrpStarts []models.LRPStartRequest) {
 	a.batch.AddLRPStarts(lrpStarts)
 }
@@ -119,3 +260,125 @@ func (a *auctionRunner) ScheduleLRPsForAuctions(lrpStarts []models.LRPStartReque
 func (a *auctionRunner) ScheduleTasksForAuctions(tasks []models.Task) {
 	a.batch.AddTasks(tasks)
 }
+
+func (a *auctionRunner) QueueStatus() auctiontypes.QueueStatus {
+	return a.batch.Status()
+}
+
+func (a *auctionRunner) fetchStateAndBuildZones(ctx context.Context, logger lager.Logger, clients map[string]auctiontypes.CellRep) map[string]Zone {
+	if a.config.StateFetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.config.StateFetchTimeout)
+		defer cancel()
+	}
+
+	return FetchStateAndBuildZonesWithOptions(ctx, logger, a.workPool, a.clock, clients, StateFetchOptions{
+		HedgeAfter:   a.config.HedgeStateAfter,
+		SoftDeadline: a.config.StaleStateAfter,
+		Cache:        a.stateCache,
+	})
+}
+
+func (a *auctionRunner) schedule(zones map[string]Zone, auctionRequest auctiontypes.AuctionRequest, trail *auditTrail) auctiontypes.AuctionResults {
+	recorder := a.config.RoundRecorder
+
+	var round Round
+	if recorder != nil {
+		round = Round{
+			Time:          a.clock.Now(),
+			Cells:         snapshotCells(zones),
+			Request:       auctionRequest,
+			Deterministic: a.config.Deterministic,
+		}
+	}
+
+	scheduler := NewScheduler(a.workPool, zones, a.clock)
+	if a.config.Deterministic {
+		scheduler.Deterministic(a.config.TieBreak)
+	}
+	scheduler.ScoreInParallel(a.config.ScoringShards)
+	if trail != nil || recorder != nil {
+		scheduler.RecordDecisions()
+	}
+
+	results := scheduler.Schedule(auctionRequest)
+	trail.add(scheduler)
+
+	if recorder != nil {
+		round.Placements = placementsFor(auctionRequest, scheduler)
+		recorder.RecordRound(round)
+	}
+
+	return results
+}
+
+func (a *auctionRunner) audit(trail *auditTrail, results auctiontypes.AuctionResults) {
+	if trail == nil {
+		return
+	}
+
+	for _, record := range trail.records(a.clock.Now(), results) {
+		a.config.AuditSink.RecordAuction(record)
+	}
+}
+
+func (a *auctionRunner) rescheduleStaleWork(ctx context.Context, logger lager.Logger, clients map[string]auctiontypes.CellRep, results auctiontypes.AuctionResults, trail *auditTrail) auctiontypes.AuctionResults {
+	for retry := 1; retry <= maxStaleStateRetries; retry++ {
+		var staleRequest auctiontypes.AuctionRequest
+		staleRequest, results = extractStaleWork(results)
+		if len(staleRequest.LRPs) == 0 && len(staleRequest.Tasks) == 0 {
+			break
+		}
+
+		logger.Info("rescheduling-stale-work", lager.Data{
+			"lrp-start-auctions": len(staleRequest.LRPs),
+			"task-auctions":      len(staleRequest.Tasks),
+			"retry":              retry,
+		})
+
+		zones := a.fetchStateAndBuildZones(ctx, logger, clients)
+		results = mergeResults(results, a.schedule(zones, staleRequest, trail))
+	}
+
+	return results
+}
+
+func extractStaleWork(results auctiontypes.AuctionResults) (auctiontypes.AuctionRequest, auctiontypes.AuctionResults) {
+	staleRequest := auctiontypes.AuctionRequest{}
+	stalePlacementError := auctiontypes.ErrorStaleCellState.Error()
+
+	failedLRPs := []auctiontypes.LRPAuction{}
+	for _, lrpAuction := range results.FailedLRPs {
+		if lrpAuction.PlacementError != stalePlacementError {
+			failedLRPs = append(failedLRPs, lrpAuction)
+			continue
+		}
+		lrpAuction.PlacementError = ""
+		staleRequest.LRPs = append(staleRequest.LRPs, lrpAuction)
+	}
+
+	failedTasks := []auctiontypes.TaskAuction{}
+	for _, taskAuction := range results.FailedTasks {
+		if taskAuction.PlacementError != stalePlacementError {
+			failedTasks = append(failedTasks, taskAuction)
+			continue
+		}
+		taskAuction.PlacementError = ""
+		staleRequest.Tasks = append(staleRequest.Tasks, taskAuction)
+	}
+
+	results.FailedLRPs = failedLRPs
+	results.FailedTasks = failedTasks
+
+	return staleRequest, results
+}
+
+func mergeResults(results, other auctiontypes.AuctionResults) auctiontypes.AuctionResults {
+	results.SuccessfulLRPs = append(results.SuccessfulLRPs, other.SuccessfulLRPs...)
+	results.SuccessfulTasks = append(results.SuccessfulTasks, other.SuccessfulTasks...)
+	results.FailedLRPs = append(results.FailedLRPs, other.FailedLRPs...)
+	results.FailedTasks = append(results.FailedTasks, other.FailedTasks...)
+	results.AlreadyRunningLRPs = append(results.AlreadyRunningLRPs, other.AlreadyRunningLRPs...)
+	results.AlreadyRunningTasks = append(results.AlreadyRunningTasks, other.AlreadyRunningTasks...)
+	return results
+}
@@ -0,0 +1,405 @@
+package auctionrunner_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuctionRunner", func() {
+	var delegate *fakeRunnerDelegate
+	var cellRep *fakes.FakeSimulationCellRep
+	var workPool *workpool.WorkPool
+	var config auctionrunner.Config
+	var metricEmitter auctiontypes.AuctionMetricEmitterDelegate
+	var runner auctiontypes.AuctionRunner
+	var process ifrit.Process
+
+	BeforeEach(func() {
+		cellRep = &fakes.FakeSimulationCellRep{}
+		cellRep.StateReturns(BuildCellState("the-zone", 100, 100, 100, false, lucidOnlyRootFSProviders, nil), nil)
+
+		delegate = newFakeRunnerDelegate(map[string]auctiontypes.CellRep{"the-cell": cellRep})
+		workPool = workpool.NewWorkPool(5)
+		config = auctionrunner.Config{}
+		metricEmitter = fakeMetricEmitter{}
+	})
+
+	JustBeforeEach(func() {
+		runner = auctionrunner.NewWithConfig(delegate, metricEmitter, clock.NewClock(), workPool, lagertest.NewTestLogger("test"), config)
+		process = ifrit.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+		workPool.Stop()
+	})
+
+	It("schedules the work on the cells", func() {
+		runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+
+		Eventually(delegate.ResultSize).Should(Equal(1))
+		Expect(delegate.Results().SuccessfulLRPs).To(HaveLen(1))
+		Expect(cellRep.PerformCallCount()).To(Equal(1))
+	})
+
+	Context("when the metric emitter wants zone capacities", func() {
+		var emitter *capacityRecordingEmitter
+
+		BeforeEach(func() {
+			emitter = &capacityRecordingEmitter{lock: &sync.Mutex{}}
+			metricEmitter = emitter
+		})
+
+		It("reports the capacity of each zone after fetching state", func() {
+			runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+
+			Eventually(emitter.Capacities).Should(HaveKey("the-zone"))
+			Expect(emitter.Capacities()["the-zone"].Cells).To(Equal(1))
+			Expect(emitter.Capacities()["the-zone"].AvailableResources.MemoryMB).To(Equal(100))
+		})
+	})
+
+	Context("when an audit sink is configured", func() {
+		var sink *recordingAuditSink
+
+		BeforeEach(func() {
+			sink = &recordingAuditSink{lock: &sync.Mutex{}}
+			config.AuditSink = sink
+		})
+
+		It("records how each auction was decided", func() {
+			lrpStart := BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)
+			lrpStart.DesiredLRP.Domain = "the-domain"
+			runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{lrpStart})
+			runner.ScheduleTasksForAuctions([]models.Task{BuildTask("tg-1", lucidRootFSURL, 1000, 1000)})
+
+			Eventually(sink.Records).Should(HaveLen(2))
+			records := map[string]auctionrunner.AuditRecord{}
+			for _, record := range sink.Records() {
+				records[record.Identifier] = record
+			}
+
+			lrpRecord := records["pg-1.0"]
+			Expect(lrpRecord.Kind).To(Equal(auctionrunner.AuditKindLRP))
+			Expect(lrpRecord.Domain).To(Equal("the-domain"))
+			Expect(lrpRecord.MemoryMB).To(Equal(10))
+			Expect(lrpRecord.Outcome).To(Equal(auctiontypes.AuctionSucceeded))
+			Expect(lrpRecord.Winner).To(Equal("the-cell"))
+			Expect(lrpRecord.Attempts).To(Equal(1))
+			Expect(lrpRecord.Candidates).To(HaveLen(1))
+			Expect(lrpRecord.Candidates[0].CellGuid).To(Equal("the-cell"))
+			Expect(lrpRecord.Candidates[0].Zone).To(Equal("the-zone"))
+			Expect(lrpRecord.Score).To(Equal(lrpRecord.Candidates[0].Score))
+
+			taskRecord := records["tg-1"]
+			Expect(taskRecord.Kind).To(Equal(auctionrunner.AuditKindTask))
+			Expect(taskRecord.Outcome).To(Equal(auctiontypes.AuctionFailed))
+			Expect(taskRecord.Winner).To(BeEmpty())
+			Expect(taskRecord.PlacementError).To(Equal(auctiontypes.ErrorInsufficientResources.Error()))
+			Expect(taskRecord.Candidates).To(HaveLen(1))
+			Expect(taskRecord.Candidates[0].Error).To(Equal(auctiontypes.ErrorInsufficientResources.Error()))
+		})
+	})
+
+	Context("when a round recorder is configured", func() {
+		var recorder *recordingRoundRecorder
+
+		BeforeEach(func() {
+			recorder = &recordingRoundRecorder{lock: &sync.Mutex{}}
+			config.RoundRecorder = recorder
+		})
+
+		It("records the state, request and placements of the round", func() {
+			runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+
+			Eventually(recorder.Rounds).Should(HaveLen(1))
+			round := recorder.Rounds()[0]
+			Expect(round.Cells).To(HaveLen(1))
+			Expect(round.Cells[0].Guid).To(Equal("the-cell"))
+			Expect(round.Cells[0].State.AvailableResources.MemoryMB).To(Equal(100))
+			Expect(round.Request.LRPs).To(HaveLen(1))
+			Expect(round.Placements.LRPs).To(Equal(map[string]string{"pg-1.0": "the-cell"}))
+		})
+	})
+
+	Context("when a cell is slower than the state fetch timeout", func() {
+		var slowCellRep *fakes.FakeSimulationCellRep
+		var blockForever chan struct{}
+
+		BeforeEach(func() {
+			blockForever = make(chan struct{})
+			slowCellRep = &fakes.FakeSimulationCellRep{}
+			slowCellRep.StateStub = func() (auctiontypes.CellState, error) {
+				<-blockForever
+				return BuildCellState("the-zone", 1000, 1000, 100, false, lucidOnlyRootFSProviders, nil), nil
+			}
+			delegate.cells["slow-cell"] = slowCellRep
+
+			config.StateFetchTimeout = 50 * time.Millisecond
+		})
+
+		AfterEach(func() {
+			close(blockForever)
+		})
+
+		It("holds the auction with the cells that answered in time", func() {
+			runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+
+			Eventually(delegate.ResultSize).Should(Equal(1))
+			results := delegate.Results()
+			Expect(results.SuccessfulLRPs).To(HaveLen(1))
+			Expect(results.SuccessfulLRPs[0].Winner).To(Equal("the-cell"))
+			Expect(slowCellRep.PerformCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when a cell keeps failing", func() {
+		var brokenCellRep *fakes.FakeSimulationCellRep
+
+		BeforeEach(func() {
+			brokenCellRep = &fakes.FakeSimulationCellRep{}
+			brokenCellRep.StateReturns(auctiontypes.CellState{}, errors.New("timed out"))
+			delegate.cells["broken-cell"] = brokenCellRep
+
+			config.CircuitBreaker = auctionrunner.CircuitBreakerConfig{FailureThreshold: 2, CoolOff: time.Hour}
+		})
+
+		It("stops asking it for its state once its circuit breaker opens", func() {
+			for i, processGuid := range []string{"pg-1", "pg-2", "pg-3"} {
+				runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest(processGuid, []uint{0}, lucidRootFSURL, 10, 10)})
+				Eventually(delegate.ResultSize).Should(Equal(i + 1))
+			}
+
+			Expect(brokenCellRep.StateCallCount()).To(Equal(2))
+			Expect(delegate.Results().SuccessfulLRPs).To(HaveLen(3))
+		})
+	})
+
+	Context("when signaled while an auction is in progress", func() {
+		var fetching, proceed chan struct{}
+
+		BeforeEach(func() {
+			fetching = make(chan struct{})
+			proceed = make(chan struct{})
+			delegate.fetching = func() {
+				close(fetching)
+				<-proceed
+			}
+		})
+
+		It("stops before performing any work", func() {
+			runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+
+			Eventually(fetching).Should(BeClosed())
+			process.Signal(os.Interrupt)
+			close(proceed)
+
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(cellRep.StateCallCount()).To(Equal(0))
+			Expect(cellRep.PerformCallCount()).To(Equal(0))
+			Expect(delegate.ResultSize()).To(Equal(0))
+		})
+	})
+
+	Context("when a cell rejects the work as stale", func() {
+		var lock *sync.Mutex
+		var staleRejections int
+
+		BeforeEach(func() {
+			lock = &sync.Mutex{}
+			staleRejections = 1
+
+			cellRep.PerformStub = func(auctiontypes.Work) (auctiontypes.Work, error) {
+				lock.Lock()
+				defer lock.Unlock()
+
+				if staleRejections > 0 {
+					staleRejections--
+					return auctiontypes.Work{}, auctiontypes.ErrorStaleCellState
+				}
+				return auctiontypes.Work{}, nil
+			}
+		})
+
+		It("re-auctions the work on freshly fetched state", func() {
+			runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+
+			Eventually(delegate.ResultSize).Should(Equal(1))
+			results := delegate.Results()
+			Expect(results.FailedLRPs).To(BeEmpty())
+			Expect(results.SuccessfulLRPs).To(HaveLen(1))
+			Expect(results.SuccessfulLRPs[0].Attempts).To(Equal(2))
+
+			Expect(cellRep.StateCallCount()).To(Equal(2))
+			Expect(cellRep.PerformCallCount()).To(Equal(2))
+		})
+
+		Context("when the cell keeps rejecting the work", func() {
+			BeforeEach(func() {
+				staleRejections = 100
+			})
+
+			It("eventually gives up and reports the work as failed", func() {
+				runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+
+				Eventually(delegate.ResultSize).Should(Equal(1))
+				results := delegate.Results()
+				Expect(results.SuccessfulLRPs).To(BeEmpty())
+				Expect(results.FailedLRPs).To(HaveLen(1))
+				Expect(results.FailedLRPs[0].PlacementError).To(Equal(auctiontypes.ErrorStaleCellState.Error()))
+
+				Expect(cellRep.PerformCallCount()).To(Equal(4))
+			})
+		})
+	})
+})
+
+type fakeRunnerDelegate struct {
+	cells    map[string]auctiontypes.CellRep
+	fetching func()
+	results  auctiontypes.AuctionResults
+	lock     *sync.Mutex
+}
+
+func newFakeRunnerDelegate(cells map[string]auctiontypes.CellRep) *fakeRunnerDelegate {
+	return &fakeRunnerDelegate{
+		cells: cells,
+		lock:  &sync.Mutex{},
+	}
+}
+
+func (d *fakeRunnerDelegate) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	if d.fetching != nil {
+		d.fetching()
+	}
+	return d.cells, nil
+}
+
+func (d *fakeRunnerDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.results.SuccessfulLRPs = append(d.results.SuccessfulLRPs, results.SuccessfulLRPs...)
+	d.results.SuccessfulTasks = append(d.results.SuccessfulTasks, results.SuccessfulTasks...)
+	d.results.FailedLRPs = append(d.results.FailedLRPs, results.FailedLRPs...)
+	d.results.FailedTasks = append(d.results.FailedTasks, results.FailedTasks...)
+	d.results.AlreadyRunningLRPs = append(d.results.AlreadyRunningLRPs, results.AlreadyRunningLRPs...)
+	d.results.AlreadyRunningTasks = append(d.results.AlreadyRunningTasks, results.AlreadyRunningTasks...)
+}
+
+func (d *fakeRunnerDelegate) Results() auctiontypes.AuctionResults {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.results
+}
+
+func (d *fakeRunnerDelegate) ResultSize() int {
+	results := d.Results()
+	return len(results.SuccessfulLRPs) + len(results.SuccessfulTasks) +
+		len(results.FailedLRPs) + len(results.FailedTasks) +
+		len(results.AlreadyRunningLRPs) + len(results.AlreadyRunningTasks)
+}
+
+type fakeMetricEmitter struct{}
+
+func (fakeMetricEmitter) FetchStatesCompleted(time.Duration)           {}
+func (fakeMetricEmitter) AuctionCompleted(auctiontypes.AuctionResults) {}
+
+type capacityRecordingEmitter struct {
+	fakeMetricEmitter
+
+	capacities map[string]auctiontypes.ZoneCapacity
+	lock       *sync.Mutex
+}
+
+func (e *capacityRecordingEmitter) ZoneCapacitiesFetched(capacities map[string]auctiontypes.ZoneCapacity) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.capacities = capacities
+}
+
+func (e *capacityRecordingEmitter) Capacities() map[string]auctiontypes.ZoneCapacity {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.capacities
+}
+
+type resultsRecordingEmitter struct {
+	fakeMetricEmitter
+
+	results []auctiontypes.AuctionResults
+	lock    *sync.Mutex
+}
+
+func (e *resultsRecordingEmitter) AuctionCompleted(results auctiontypes.AuctionResults) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.results = append(e.results, results)
+}
+
+func (e *resultsRecordingEmitter) Results() auctiontypes.AuctionResults {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	all := auctiontypes.AuctionResults{}
+	for _, results := range e.results {
+		all.SuccessfulLRPs = append(all.SuccessfulLRPs, results.SuccessfulLRPs...)
+		all.SuccessfulTasks = append(all.SuccessfulTasks, results.SuccessfulTasks...)
+		all.FailedLRPs = append(all.FailedLRPs, results.FailedLRPs...)
+		all.FailedTasks = append(all.FailedTasks, results.FailedTasks...)
+		all.AlreadyRunningLRPs = append(all.AlreadyRunningLRPs, results.AlreadyRunningLRPs...)
+		all.AlreadyRunningTasks = append(all.AlreadyRunningTasks, results.AlreadyRunningTasks...)
+	}
+	return all
+}
+
+type recordingAuditSink struct {
+	records []auctionrunner.AuditRecord
+	lock    *sync.Mutex
+}
+
+func (s *recordingAuditSink) RecordAuction(record auctionrunner.AuditRecord) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records = append(s.records, record)
+}
+
+func (s *recordingAuditSink) Records() []auctionrunner.AuditRecord {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]auctionrunner.AuditRecord{}, s.records...)
+}
+
+type recordingRoundRecorder struct {
+	rounds []auctionrunner.Round
+	lock   *sync.Mutex
+}
+
+func (r *recordingRoundRecorder) RecordRound(round auctionrunner.Round) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.rounds = append(r.rounds, round)
+}
+
+func (r *recordingRoundRecorder) Rounds() []auctionrunner.Round {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]auctionrunner.Round{}, r.rounds...)
+}
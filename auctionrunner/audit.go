@@ -0,0 +1,137 @@
+package auctionrunner
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+const (
+	AuditKindLRP  = "lrp"
+	AuditKindTask = "task"
+)
+
+// AuditSink receives one AuditRecord for every auction the runner completes.
+// Set it in Config to find out why work was placed where it was.
+type AuditSink interface {
+	RecordAuction(AuditRecord)
+}
+
+// AuditRecord describes how one auction was decided.  Candidates lists every
+// cell the auction was scored against in its last scheduling attempt; it is
+// empty for auctions that no cell could run or that were already running.
+type AuditRecord struct {
+	Time       time.Time                   `json:"time"`
+	Kind       string                      `json:"kind"`
+	Identifier string                      `json:"identifier"`
+	Domain     string                      `json:"domain"`
+	RootFS     string                      `json:"rootfs"`
+	MemoryMB   int                         `json:"memory_mb"`
+	DiskMB     int                         `json:"disk_mb"`
+	Outcome    auctiontypes.AuctionOutcome `json:"outcome"`
+	Candidates []AuditCandidate            `json:"candidates"`
+
+	Winner         string        `json:"winner,omitempty"`
+	Score          float64       `json:"score"`
+	PlacementError string        `json:"placement_error,omitempty"`
+	Attempts       int           `json:"attempts"`
+	WaitDuration   time.Duration `json:"wait_duration_ns"`
+}
+
+type AuditCandidate struct {
+	CellGuid string  `json:"cell_guid"`
+	Zone     string  `json:"zone"`
+	Score    float64 `json:"score"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// auditTrail collects the decisions of every scheduler an auction round
+// runs, so that rescheduled work is audited with its latest decision
+type auditTrail struct {
+	lrps  map[string]Decision
+	tasks map[string]Decision
+}
+
+func newAuditTrail() *auditTrail {
+	return &auditTrail{
+		lrps:  map[string]Decision{},
+		tasks: map[string]Decision{},
+	}
+}
+
+func (t *auditTrail) add(scheduler *Scheduler) {
+	if t == nil {
+		return
+	}
+
+	for identifier, decision := range scheduler.LRPDecisions() {
+		t.lrps[identifier] = decision
+	}
+	for identifier, decision := range scheduler.TaskDecisions() {
+		t.tasks[identifier] = decision
+	}
+}
+
+func (t *auditTrail) records(now time.Time, results auctiontypes.AuctionResults) []AuditRecord {
+	records := []AuditRecord{}
+
+	lrpRecords := func(outcome auctiontypes.AuctionOutcome, lrpAuctions []auctiontypes.LRPAuction) {
+		for _, lrpAuction := range lrpAuctions {
+			identifier := lrpAuction.Identifier()
+			record := AuditRecord{
+				Time:       now,
+				Kind:       AuditKindLRP,
+				Identifier: identifier,
+				Domain:     lrpAuction.DesiredLRP.Domain,
+				RootFS:     lrpAuction.DesiredLRP.RootFS,
+				MemoryMB:   lrpAuction.DesiredLRP.MemoryMB,
+				DiskMB:     lrpAuction.DesiredLRP.DiskMB,
+				Outcome:    outcome,
+			}
+			records = append(records, t.complete(record, t.lrps[identifier], lrpAuction.AuctionRecord))
+		}
+	}
+
+	taskRecords := func(outcome auctiontypes.AuctionOutcome, taskAuctions []auctiontypes.TaskAuction) {
+		for _, taskAuction := range taskAuctions {
+			identifier := taskAuction.Identifier()
+			record := AuditRecord{
+				Time:       now,
+				Kind:       AuditKindTask,
+				Identifier: identifier,
+				Domain:     taskAuction.Task.Domain,
+				RootFS:     taskAuction.Task.RootFS,
+				MemoryMB:   taskAuction.Task.MemoryMB,
+				DiskMB:     taskAuction.Task.DiskMB,
+				Outcome:    outcome,
+			}
+			records = append(records, t.complete(record, t.tasks[identifier], taskAuction.AuctionRecord))
+		}
+	}
+
+	lrpRecords(auctiontypes.AuctionSucceeded, results.SuccessfulLRPs)
+	lrpRecords(auctiontypes.AuctionFailed, results.FailedLRPs)
+	lrpRecords(auctiontypes.AuctionAlreadyRunning, results.AlreadyRunningLRPs)
+	taskRecords(auctiontypes.AuctionSucceeded, results.SuccessfulTasks)
+	taskRecords(auctiontypes.AuctionFailed, results.FailedTasks)
+	taskRecords(auctiontypes.AuctionAlreadyRunning, results.AlreadyRunningTasks)
+
+	return records
+}
+
+func (t *auditTrail) complete(record AuditRecord, decision Decision, auctionRecord auctiontypes.AuctionRecord) AuditRecord {
+	record.Candidates = make([]AuditCandidate, 0, len(decision.Candidates))
+	for _, candidate := range decision.Candidates {
+		record.Candidates = append(record.Candidates, AuditCandidate(candidate))
+	}
+
+	record.Winner = auctionRecord.Winner
+	if record.Outcome == auctiontypes.AuctionSucceeded {
+		record.Score = decision.Score
+	}
+	record.PlacementError = auctionRecord.PlacementError
+	record.Attempts = auctionRecord.Attempts
+	record.WaitDuration = auctionRecord.WaitDuration
+
+	return record
+}
@@ -64,6 +64,20 @@ func (b *Batch) AddTasks(tasks []models.Task) {
 	b.lock.Unlock()
 }
 
+func (b *Batch) AddLRPAuctions(lrpAuctions []auctiontypes.LRPAuction) {
+	b.lock.Lock()
+	b.lrpAuctions = append(b.lrpAuctions, lrpAuctions...)
+	b.claimToHaveWork()
+	b.lock.Unlock()
+}
+
+func (b *Batch) AddTaskAuctions(taskAuctions []auctiontypes.TaskAuction) {
+	b.lock.Lock()
+	b.taskAuctions = append(b.taskAuctions, taskAuctions...)
+	b.claimToHaveWork()
+	b.lock.Unlock()
+}
+
 func (b *Batch) DedupeAndDrain() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
 	b.lock.Lock()
 	lrpAuctions := b.lrpAuctions
@@ -101,6 +115,18 @@ func (b *Batch) DedupeAndDrain() ([]auctiontypes.LRPAuction, []auctiontypes.Task
 	return dedupedLRPAuctions, dedupedTaskAuctions
 }
 
+// Status counts the auctions waiting to be drained.  Duplicates are counted
+// until they are deduped.
+func (b *Batch) Status() auctiontypes.QueueStatus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return auctiontypes.QueueStatus{
+		LRPAuctions:  len(b.lrpAuctions),
+		TaskAuctions: len(b.taskAuctions),
+	}
+}
+
 func (b *Batch) claimToHaveWork() {
 	select {
 	case b.HasWork <- struct{}{}:
@@ -62,6 +62,41 @@ var _ = Describe("Batch", func() {
 				Expect(batch.HasWork).To(Receive())
 			})
 		})
+
+		It("counts the queued auctions until they are drained", func() {
+			batch.AddLRPStarts([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0, 1}, "lucid64", 10, 10)})
+			batch.AddTasks([]models.Task{BuildTask("tg-1", "lucid64", 10, 10)})
+			Expect(batch.Status()).To(Equal(auctiontypes.QueueStatus{LRPAuctions: 2, TaskAuctions: 1}))
+
+			batch.DedupeAndDrain()
+			Expect(batch.Status()).To(Equal(auctiontypes.QueueStatus{}))
+		})
+	})
+
+	Describe("re-adding auctions", func() {
+		var lrpAuction auctiontypes.LRPAuction
+		var taskAuction auctiontypes.TaskAuction
+
+		BeforeEach(func() {
+			lrpAuction = BuildLRPAuction("pg-1", 1, "lucid64", 10, 10, clock.Now())
+			lrpAuction.Attempts = 2
+			taskAuction = BuildTaskAuction(BuildTask("tg-1", "lucid64", 10, 10), clock.Now())
+			taskAuction.Attempts = 3
+
+			clock.Increment(time.Minute)
+			batch.AddLRPAuctions([]auctiontypes.LRPAuction{lrpAuction})
+			batch.AddTaskAuctions([]auctiontypes.TaskAuction{taskAuction})
+		})
+
+		It("preserves their auction records", func() {
+			lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+			Expect(lrpAuctions).To(ConsistOf(lrpAuction))
+			Expect(taskAuctions).To(ConsistOf(taskAuction))
+		})
+
+		It("should have work", func() {
+			Expect(batch.HasWork).To(Receive())
+		})
 	})
 
 	Describe("DedupeAndDrain", func() {
@@ -5,10 +5,20 @@ import (
 	"github.com/cloudfoundry-incubator/runtime-schema/models"
 )
 
+// staleScorePenalty is added to the scores of cells built from cached state,
+// so they only win auctions that fresh cells can't take as well
+const staleScorePenalty = 1.0
+
 type Cell struct {
 	Guid   string
 	client auctiontypes.CellRep
 	state  auctiontypes.CellState
+	stale  bool
+
+	// instances counts the cell's LRPs by ProcessGuid, and rootFSMatches
+	// caches MatchRootFS; both are built on first use
+	instances     map[string]int
+	rootFSMatches map[string]bool
 
 	workToCommit auctiontypes.Work
 }
@@ -21,23 +31,55 @@ func NewCell(guid string, client auctiontypes.CellRep, state auctiontypes.CellSt
 	}
 }
 
+// NewStaleCell builds a cell from state the cell reported in an earlier
+// auction.  It scores worse than an equivalent fresh cell.  Work committed to
+// it is rejected as stale if the cell has changed since.
+func NewStaleCell(guid string, client auctiontypes.CellRep, state auctiontypes.CellState) *Cell {
+	cell := NewCell(guid, client, state)
+	cell.stale = true
+	return cell
+}
+
+func (c *Cell) Stale() bool {
+	return c.stale
+}
+
 func (c *Cell) MatchRootFS(rootFS string) bool {
-	return c.state.MatchRootFS(rootFS)
+	if match, ok := c.rootFSMatches[rootFS]; ok {
+		return match
+	}
+
+	if c.rootFSMatches == nil {
+		c.rootFSMatches = map[string]bool{}
+	}
+	match := c.state.MatchRootFS(rootFS)
+	c.rootFSMatches[rootFS] = match
+	return match
+}
+
+// Instances returns how many instances of the process the cell is running,
+// including those reserved on it.
+func (c *Cell) Instances(processGuid string) int {
+	if c.instances == nil {
+		c.instances = make(map[string]int, len(c.state.LRPs))
+		for _, lrp := range c.state.LRPs {
+			c.instances[lrp.ProcessGuid]++
+		}
+	}
+
+	return c.instances[processGuid]
 }
 
 func (c *Cell) ScoreForLRPAuction(lrpAuction auctiontypes.LRPAuction) (float64, error) {
+	return c.scoreForLRPAuction(lrpAuction, c.Instances(lrpAuction.DesiredLRP.ProcessGuid))
+}
+
+func (c *Cell) scoreForLRPAuction(lrpAuction auctiontypes.LRPAuction, numberOfInstancesWithMatchingProcessGuid int) (float64, error) {
 	err := c.canHandleLRPAuction(lrpAuction)
 	if err != nil {
 		return 0, err
 	}
 
-	numberOfInstancesWithMatchingProcessGuid := 0
-	for _, lrp := range c.state.LRPs {
-		if lrp.ProcessGuid == lrpAuction.DesiredLRP.ProcessGuid {
-			numberOfInstancesWithMatchingProcessGuid++
-		}
-	}
-
 	remainingResources := c.state.AvailableResources
 	remainingResources.MemoryMB -= lrpAuction.DesiredLRP.MemoryMB
 	remainingResources.DiskMB -= lrpAuction.DesiredLRP.DiskMB
@@ -76,6 +118,9 @@ func (c *Cell) ReserveLRP(lrpAuction auctiontypes.LRPAuction) error {
 		MemoryMB:    lrpAuction.DesiredLRP.MemoryMB,
 		DiskMB:      lrpAuction.DesiredLRP.DiskMB,
 	})
+	if c.instances != nil {
+		c.instances[lrpAuction.DesiredLRP.ProcessGuid]++
+	}
 
 	c.state.AvailableResources.MemoryMB -= lrpAuction.DesiredLRP.MemoryMB
 	c.state.AvailableResources.DiskMB -= lrpAuction.DesiredLRP.DiskMB
@@ -107,18 +152,56 @@ func (c *Cell) ReserveTask(task models.Task) error {
 	return nil
 }
 
-func (c *Cell) Commit() auctiontypes.Work {
+func (c *Cell) Commit() (auctiontypes.Work, error) {
 	if len(c.workToCommit.LRPs) == 0 && len(c.workToCommit.Tasks) == 0 {
-		return auctiontypes.Work{}
+		return auctiontypes.Work{}, nil
 	}
 
+	c.workToCommit.Generation = c.state.Generation
+
 	failedWork, err := c.client.Perform(c.workToCommit)
+	if err == auctiontypes.ErrorStaleCellState {
+		//the cell changed since we fetched its state and did not perform any of the work
+		return c.workToCommit, err
+	}
 	if err != nil {
 		//an error may indicate partial failure
-		//in this case we don't reschedule work in order to make sure we don't
-		//create duplicates of things -- we'll let the converger figure things out for us later
-		return auctiontypes.Work{}
+		//ask the cell what actually landed and fail the rest
+		return c.reconcile(), nil
+	}
+	return failedWork, nil
+}
+
+func (c *Cell) reconcile() auctiontypes.Work {
+	state, err := c.client.State()
+	if err != nil {
+		//we can't tell what landed, so we fail all of it
+		//anything that did land is reported as already running when it is next auctioned
+		return c.workToCommit
 	}
+
+	landedLRPs := map[string]bool{}
+	for _, lrp := range state.LRPs {
+		landedLRPs[lrp.Identifier()] = true
+	}
+
+	landedTasks := map[string]bool{}
+	for _, task := range state.Tasks {
+		landedTasks[task.TaskGuid] = true
+	}
+
+	failedWork := auctiontypes.Work{}
+	for _, lrpAuction := range c.workToCommit.LRPs {
+		if !landedLRPs[lrpAuction.Identifier()] {
+			failedWork.LRPs = append(failedWork.LRPs, lrpAuction)
+		}
+	}
+	for _, task := range c.workToCommit.Tasks {
+		if !landedTasks[task.TaskGuid] {
+			failedWork.Tasks = append(failedWork.Tasks, task)
+		}
+	}
+
 	return failedWork
 }
 
@@ -163,6 +246,9 @@ func (c *Cell) computeScore(remainingResources auctiontypes.Resources, numInstan
 
 	resourceScore := (fractionUsedMemory + fractionUsedDisk + fractionUsedContainers) / 3.0
 	resourceScore += float64(numInstances)
+	if c.stale {
+		resourceScore += staleScorePenalty
+	}
 
 	return resourceScore
 }
@@ -173,6 +259,9 @@ func (c *Cell) computeTaskScore(remainingResources auctiontypes.Resources) float
 	fractionUsedContainers := 1.0 - float64(remainingResources.Containers)/float64(c.state.TotalResources.Containers)
 
 	resourceScore := (fractionUsedMemory + fractionUsedDisk + fractionUsedContainers) / 3.0
+	if c.stale {
+		resourceScore += staleScorePenalty
+	}
 
 	return resourceScore
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry-incubator/auction/auctionrunner"
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
 	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -104,6 +105,18 @@ var _ = Describe("Cell", func() {
 			Expect(oneMatchesScore).To(BeNumerically("<", twoMatchesScore))
 		})
 
+		It("scores cells built from stale state worse than a fuller fresh cell", func() {
+			instance := BuildLRPAuction("pg-new", 0, lucidRootFSURL, 10, 10, time.Now())
+			staleEmptyCell := auctionrunner.NewStaleCell("stale-cell", client, BuildCellState("the-zone", 100, 200, 50, false, lucidOnlyRootFSProviders, nil))
+			Expect(staleEmptyCell.Stale()).To(BeTrue())
+
+			staleScore, err := staleEmptyCell.ScoreForLRPAuction(instance)
+			Expect(err).NotTo(HaveOccurred())
+			score, err := cell.ScoreForLRPAuction(instance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(score).To(BeNumerically("<", staleScore))
+		})
+
 		Context("when the LRP does not fit", func() {
 			Context("because of memory constraints", func() {
 				It("should error", func() {
@@ -677,7 +690,8 @@ var _ = Describe("Cell", func() {
 	Describe("Commit", func() {
 		Context("with nothing to commit", func() {
 			It("does nothing and returns empty", func() {
-				failedWork := cell.Commit()
+				failedWork, err := cell.Commit()
+				Expect(err).NotTo(HaveOccurred())
 				Expect(failedWork).To(BeZero())
 				Expect(client.PerformCallCount()).To(Equal(0))
 			})
@@ -701,6 +715,37 @@ var _ = Describe("Cell", func() {
 
 			})
 
+			Context("when the cell state has a generation", func() {
+				BeforeEach(func() {
+					state := BuildCellState("the-zone", 100, 200, 50, false, lucidOnlyRootFSProviders, nil)
+					state.Generation = 7
+					cell = auctionrunner.NewCell("the-cell", client, state)
+					Expect(cell.ReserveLRP(lrpAuction)).To(Succeed())
+				})
+
+				It("echoes the generation back with the work", func() {
+					cell.Commit()
+					Expect(client.PerformArgsForCall(0).Generation).To(BeEquivalentTo(7))
+				})
+			})
+
+			Context("when the client rejects the work as stale", func() {
+				BeforeEach(func() {
+					client.PerformReturns(auctiontypes.Work{}, auctiontypes.ErrorStaleCellState)
+				})
+
+				It("returns all of the work along with the error", func() {
+					failedWork, err := cell.Commit()
+					Expect(err).To(Equal(auctiontypes.ErrorStaleCellState))
+					Expect(failedWork.LRPs).To(ConsistOf(lrpAuction))
+				})
+
+				It("does not reconcile against the cell's state", func() {
+					cell.Commit()
+					Expect(client.StateCallCount()).To(Equal(0))
+				})
+			})
+
 			Context("when the client returns some failed work", func() {
 				It("forwards the failed work", func() {
 					failedWork := auctiontypes.Work{
@@ -712,9 +757,58 @@ var _ = Describe("Cell", func() {
 			})
 
 			Context("when the client returns an error", func() {
-				It("does not return any failed work", func() {
+				var task models.Task
+
+				BeforeEach(func() {
+					task = BuildTask("tg-new", lucidRootFSURL, 10, 10)
+					Expect(cell.ReserveTask(task)).To(Succeed())
+
 					client.PerformReturns(auctiontypes.Work{}, errors.New("boom"))
-					Expect(cell.Commit()).To(BeZero())
+				})
+
+				It("asks the client for its state", func() {
+					cell.Commit()
+					Expect(client.StateCallCount()).To(Equal(1))
+				})
+
+				Context("when all of the work landed", func() {
+					BeforeEach(func() {
+						client.StateReturns(auctiontypes.CellState{
+							LRPs:  []auctiontypes.LRP{{"pg-new", 0, 20, 10}},
+							Tasks: []auctiontypes.Task{{"tg-new", 10, 10}},
+						}, nil)
+					})
+
+					It("does not return any failed work", func() {
+						Expect(cell.Commit()).To(BeZero())
+					})
+				})
+
+				Context("when only some of the work landed", func() {
+					BeforeEach(func() {
+						client.StateReturns(auctiontypes.CellState{
+							Tasks: []auctiontypes.Task{{"tg-new", 10, 10}},
+						}, nil)
+					})
+
+					It("returns the work that did not land as failed", func() {
+						Expect(cell.Commit()).To(Equal(auctiontypes.Work{
+							LRPs: []auctiontypes.LRPAuction{lrpAuction},
+						}))
+					})
+				})
+
+				Context("when the state cannot be fetched", func() {
+					BeforeEach(func() {
+						client.StateReturns(auctiontypes.CellState{}, errors.New("kaboom"))
+					})
+
+					It("returns all of the work as failed", func() {
+						Expect(cell.Commit()).To(Equal(auctiontypes.Work{
+							LRPs:  []auctiontypes.LRPAuction{lrpAuction},
+							Tasks: []models.Task{task},
+						}))
+					})
 				})
 			})
 		})
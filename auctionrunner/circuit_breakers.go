@@ -0,0 +1,173 @@
+package auctionrunner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+type CircuitBreakerConfig struct {
+	// FailureThreshold consecutive failed State or Perform calls open a
+	// cell's breaker.  Zero disables the breakers.
+	FailureThreshold int
+
+	// CoolOff is how long an open breaker keeps its cell out of auctions
+	// before letting the next auction probe it.
+	CoolOff time.Duration
+}
+
+type breaker struct {
+	state    auctiontypes.CircuitBreakerState
+	failures int
+	openedAt time.Time
+}
+
+/*
+CircuitBreakers keeps a breaker per cell so that a cell that keeps failing
+stops costing every auction a timeout.
+
+A breaker opens after FailureThreshold consecutive failures, and its cell is
+skipped until CoolOff has passed.  The breaker is then half-open: the cell
+takes part in the next auction, and its first call closes the breaker again if
+it succeeds or reopens it if it fails.  Stale cell state is not a failure.
+*/
+type CircuitBreakers struct {
+	config        CircuitBreakerConfig
+	clock         clock.Clock
+	metricEmitter auctiontypes.CircuitBreakerMetricEmitter
+	logger        lager.Logger
+
+	breakers map[string]*breaker
+	lock     *sync.Mutex
+}
+
+// NewCircuitBreakers returns breakers that emit their transitions to
+// metricEmitter if it is a CircuitBreakerMetricEmitter.
+func NewCircuitBreakers(config CircuitBreakerConfig, clock clock.Clock, metricEmitter auctiontypes.AuctionMetricEmitterDelegate, logger lager.Logger) *CircuitBreakers {
+	breakerMetricEmitter, _ := metricEmitter.(auctiontypes.CircuitBreakerMetricEmitter)
+
+	return &CircuitBreakers{
+		config:        config,
+		clock:         clock,
+		metricEmitter: breakerMetricEmitter,
+		logger:        logger.Session("circuit-breakers"),
+		breakers:      map[string]*breaker{},
+		lock:          &sync.Mutex{},
+	}
+}
+
+// Admit returns the clients whose breakers let them take part in an auction,
+// wrapped so that the outcome of their calls is recorded.
+func (c *CircuitBreakers) Admit(clients map[string]auctiontypes.CellRep) map[string]auctiontypes.CellRep {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for guid := range c.breakers {
+		if _, ok := clients[guid]; !ok {
+			delete(c.breakers, guid)
+		}
+	}
+
+	now := c.clock.Now()
+	admitted := map[string]auctiontypes.CellRep{}
+	for guid, client := range clients {
+		b, ok := c.breakers[guid]
+		if !ok {
+			b = &breaker{state: auctiontypes.CircuitBreakerClosed}
+			c.breakers[guid] = b
+		}
+
+		if b.state == auctiontypes.CircuitBreakerOpen {
+			if now.Sub(b.openedAt) < c.config.CoolOff {
+				continue
+			}
+			c.transition(guid, b, auctiontypes.CircuitBreakerHalfOpen)
+		}
+
+		admitted[guid] = &breakerCellRep{breakers: c, guid: guid, client: client}
+	}
+
+	return admitted
+}
+
+func (c *CircuitBreakers) record(guid string, err error) {
+	if err == context.Canceled {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	b, ok := c.breakers[guid]
+	if !ok {
+		return
+	}
+
+	if err == nil || err == auctiontypes.ErrorStaleCellState {
+		b.failures = 0
+		if b.state != auctiontypes.CircuitBreakerClosed {
+			c.transition(guid, b, auctiontypes.CircuitBreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	switch b.state {
+	case auctiontypes.CircuitBreakerHalfOpen:
+		b.openedAt = c.clock.Now()
+		c.transition(guid, b, auctiontypes.CircuitBreakerOpen)
+	case auctiontypes.CircuitBreakerClosed:
+		if b.failures >= c.config.FailureThreshold {
+			b.openedAt = c.clock.Now()
+			c.transition(guid, b, auctiontypes.CircuitBreakerOpen)
+		}
+	}
+}
+
+// transition must be called with the lock held
+func (c *CircuitBreakers) transition(guid string, b *breaker, to auctiontypes.CircuitBreakerState) {
+	from := b.state
+	b.state = to
+
+	c.logger.Info("transitioned", lager.Data{
+		"cell-guid": guid,
+		"from":      from,
+		"to":        to,
+		"failures":  b.failures,
+	})
+
+	if c.metricEmitter != nil {
+		c.metricEmitter.CircuitBreakerTransitioned(guid, from, to)
+	}
+}
+
+type breakerCellRep struct {
+	breakers *CircuitBreakers
+	guid     string
+	client   auctiontypes.CellRep
+}
+
+func (r *breakerCellRep) State() (auctiontypes.CellState, error) {
+	return r.StateContext(context.Background())
+}
+
+func (r *breakerCellRep) StateContext(ctx context.Context) (auctiontypes.CellState, error) {
+	state, err := auctiontypes.StateWithContext(ctx, r.client)
+	r.breakers.record(r.guid, err)
+	return state, err
+}
+
+func (r *breakerCellRep) Perform(work auctiontypes.Work) (auctiontypes.Work, error) {
+	return r.PerformContext(context.Background(), work)
+}
+
+func (r *breakerCellRep) PerformContext(ctx context.Context, work auctiontypes.Work) (auctiontypes.Work, error) {
+	failedWork, err := auctiontypes.PerformWithContext(ctx, r.client, work)
+	r.breakers.record(r.guid, err)
+	return failedWork, err
+}
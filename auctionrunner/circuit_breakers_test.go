@@ -0,0 +1,133 @@
+package auctionrunner_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CircuitBreakers", func() {
+	var clock *fakeclock.FakeClock
+	var emitter *transitionRecordingEmitter
+	var breakers *auctionrunner.CircuitBreakers
+	var cellRep *fakes.FakeSimulationCellRep
+	var clients map[string]auctiontypes.CellRep
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Now())
+		emitter = &transitionRecordingEmitter{lock: &sync.Mutex{}}
+		breakers = auctionrunner.NewCircuitBreakers(auctionrunner.CircuitBreakerConfig{
+			FailureThreshold: 3,
+			CoolOff:          time.Minute,
+		}, clock, emitter, lagertest.NewTestLogger("test"))
+
+		cellRep = &fakes.FakeSimulationCellRep{}
+		cellRep.StateReturns(auctiontypes.CellState{}, errors.New("timed out"))
+		clients = map[string]auctiontypes.CellRep{"cell": cellRep}
+	})
+
+	failAuctions := func(count int) {
+		for i := 0; i < count; i++ {
+			admitted := breakers.Admit(clients)
+			Expect(admitted).To(HaveKey("cell"))
+			_, err := admitted["cell"].State()
+			Expect(err).To(HaveOccurred())
+		}
+	}
+
+	It("admits cells until they fail FailureThreshold times in a row", func() {
+		failAuctions(2)
+		Expect(breakers.Admit(clients)).To(HaveKey("cell"))
+
+		failAuctions(1)
+		Expect(breakers.Admit(clients)).To(BeEmpty())
+		Expect(emitter.Transitions()).To(Equal([]string{"cell:closed->open"}))
+	})
+
+	It("resets the count when a call succeeds", func() {
+		failAuctions(2)
+
+		cellRep.StateReturns(auctiontypes.CellState{}, nil)
+		_, err := breakers.Admit(clients)["cell"].State()
+		Expect(err).NotTo(HaveOccurred())
+
+		cellRep.StateReturns(auctiontypes.CellState{}, errors.New("timed out"))
+		failAuctions(2)
+		Expect(breakers.Admit(clients)).To(HaveKey("cell"))
+	})
+
+	It("does not count stale state as a failure", func() {
+		cellRep.PerformReturns(auctiontypes.Work{}, auctiontypes.ErrorStaleCellState)
+		for i := 0; i < 3; i++ {
+			_, err := breakers.Admit(clients)["cell"].Perform(auctiontypes.Work{})
+			Expect(err).To(Equal(auctiontypes.ErrorStaleCellState))
+		}
+
+		Expect(breakers.Admit(clients)).To(HaveKey("cell"))
+	})
+
+	Context("once the breaker is open", func() {
+		BeforeEach(func() {
+			failAuctions(3)
+		})
+
+		It("probes the cell half-open after the cool-off", func() {
+			clock.Increment(59 * time.Second)
+			Expect(breakers.Admit(clients)).To(BeEmpty())
+
+			clock.Increment(time.Second)
+			Expect(breakers.Admit(clients)).To(HaveKey("cell"))
+			Expect(emitter.Transitions()).To(Equal([]string{"cell:closed->open", "cell:open->half-open"}))
+		})
+
+		It("closes the breaker when the probe succeeds", func() {
+			clock.Increment(time.Minute)
+			cellRep.StateReturns(auctiontypes.CellState{}, nil)
+			_, err := breakers.Admit(clients)["cell"].State()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(emitter.Transitions()).To(ContainElement("cell:half-open->closed"))
+
+			cellRep.StateReturns(auctiontypes.CellState{}, errors.New("timed out"))
+			failAuctions(2)
+			Expect(breakers.Admit(clients)).To(HaveKey("cell"))
+		})
+
+		It("reopens the breaker when the probe fails", func() {
+			clock.Increment(time.Minute)
+			failAuctions(1)
+
+			Expect(emitter.Transitions()).To(ContainElement("cell:half-open->open"))
+			Expect(breakers.Admit(clients)).To(BeEmpty())
+		})
+	})
+})
+
+type transitionRecordingEmitter struct {
+	fakeMetricEmitter
+
+	transitions []string
+	lock        *sync.Mutex
+}
+
+func (e *transitionRecordingEmitter) CircuitBreakerTransitioned(cellGuid string, from, to auctiontypes.CircuitBreakerState) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.transitions = append(e.transitions, cellGuid+":"+string(from)+"->"+string(to))
+}
+
+func (e *transitionRecordingEmitter) Transitions() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.transitions
+}
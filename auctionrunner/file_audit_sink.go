@@ -0,0 +1,141 @@
+package auctionrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const defaultAuditMaxFiles = 5
+
+// FileAuditSink appends AuditRecords to a file, one JSON object per line.
+// Once the file would grow past maxBytes it is renamed to path.1, path.1 to
+// path.2 and so on; only the newest maxFiles rotated files are kept.
+type FileAuditSink struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+	logger   lager.Logger
+
+	lock *sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewFileAuditSink(path string, maxBytes int64, maxFiles int, logger lager.Logger) (*FileAuditSink, error) {
+	if maxFiles <= 0 {
+		maxFiles = defaultAuditMaxFiles
+	}
+
+	sink := &FileAuditSink{
+		path:     path,
+		maxBytes: maxBytes,
+		maxFiles: maxFiles,
+		logger:   logger.Session("file-audit-sink", lager.Data{"path": path}),
+		lock:     &sync.Mutex{},
+	}
+
+	err := sink.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (s *FileAuditSink) RecordAuction(record AuditRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		s.logger.Error("failed-to-marshal-record", err, lager.Data{"identifier": record.Identifier})
+		return
+	}
+	line = append(line, '\n')
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.file == nil {
+		return
+	}
+
+	if s.maxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxBytes {
+		err := s.rotate()
+		if err != nil {
+			s.logger.Error("failed-to-rotate", err)
+			return
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		s.logger.Error("failed-to-write-record", err, lager.Data{"identifier": record.Identifier})
+	}
+}
+
+func (s *FileAuditSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileAuditSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate always leaves a file open at path, so that a failed rotation only
+// delays the next one
+func (s *FileAuditSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		s.open()
+		return err
+	}
+
+	err = s.shift()
+	openErr := s.open()
+	if err != nil {
+		return err
+	}
+	return openErr
+}
+
+func (s *FileAuditSink) shift() error {
+	os.Remove(s.rotatedPath(s.maxFiles))
+	for i := s.maxFiles - 1; i >= 1; i-- {
+		err := os.Rename(s.rotatedPath(i), s.rotatedPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.Rename(s.path, s.rotatedPath(1))
+}
+
+func (s *FileAuditSink) rotatedPath(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
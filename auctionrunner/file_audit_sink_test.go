@@ -0,0 +1,117 @@
+package auctionrunner_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileAuditSink", func() {
+	var dir string
+	var path string
+	var maxBytes int64
+	var sink *auctionrunner.FileAuditSink
+
+	record := func(identifier string) auctionrunner.AuditRecord {
+		return auctionrunner.AuditRecord{
+			Kind:       auctionrunner.AuditKindLRP,
+			Identifier: identifier,
+			Domain:     "the-domain",
+			Outcome:    auctiontypes.AuctionSucceeded,
+			Candidates: []auctionrunner.AuditCandidate{{CellGuid: "the-cell", Zone: "the-zone", Score: 0.5}},
+			Winner:     "the-cell",
+			Score:      0.5,
+			Attempts:   1,
+		}
+	}
+
+	readRecords := func(path string) []auctionrunner.AuditRecord {
+		file, err := os.Open(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		records := []auctionrunner.AuditRecord{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var record auctionrunner.AuditRecord
+			Expect(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+			records = append(records, record)
+		}
+		Expect(scanner.Err()).NotTo(HaveOccurred())
+		return records
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "audit")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "auctions.jsonl")
+		maxBytes = 0
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		sink, err = auctionrunner.NewFileAuditSink(path, maxBytes, 2, lagertest.NewTestLogger("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(sink.Close()).To(Succeed())
+		os.RemoveAll(dir)
+	})
+
+	It("writes one JSON record per line", func() {
+		sink.RecordAuction(record("pg-1.0"))
+		sink.RecordAuction(record("pg-1.1"))
+
+		records := readRecords(path)
+		Expect(records).To(HaveLen(2))
+		Expect(records[0]).To(Equal(record("pg-1.0")))
+		Expect(records[1].Identifier).To(Equal("pg-1.1"))
+	})
+
+	Context("when the file grows past the maximum size", func() {
+		BeforeEach(func() {
+			line, err := json.Marshal(record("pg-1.0"))
+			Expect(err).NotTo(HaveOccurred())
+			maxBytes = int64(len(line)+1) * 2
+		})
+
+		It("rotates it, keeping only the newest files", func() {
+			for _, identifier := range []string{"pg-1.0", "pg-1.1", "pg-1.2", "pg-1.3", "pg-1.4", "pg-1.5", "pg-1.6"} {
+				sink.RecordAuction(record(identifier))
+			}
+
+			Expect(readRecords(path)).To(HaveLen(1))
+			Expect(readRecords(path)[0].Identifier).To(Equal("pg-1.6"))
+			Expect(readRecords(path + ".1")).To(HaveLen(2))
+			Expect(readRecords(path + ".1")[0].Identifier).To(Equal("pg-1.4"))
+			Expect(readRecords(path + ".2")[0].Identifier).To(Equal("pg-1.2"))
+			Expect(path + ".3").NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("when the file already exists", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(path, []byte(`{"identifier":"old"}`+"\n"), 0644)).To(Succeed())
+		})
+
+		It("appends to it", func() {
+			sink.RecordAuction(record("pg-1.0"))
+
+			records := readRecords(path)
+			Expect(records).To(HaveLen(2))
+			Expect(records[0].Identifier).To(Equal("old"))
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package auctionrunner
+
+import (
+	"os"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/lease"
+)
+
+type leasedRunner struct {
+	auctiontypes.AuctionRunner
+	leaseRunner ifrit.Runner
+}
+
+/*
+NewLeased wraps runner so that it only holds auctions while this auctioneer
+holds the lease in backend.  Auctions scheduled on a standby are batched up and
+held as soon as the lease is acquired.
+
+If the lease is lost, runner stops before its next auction step and Run returns
+lease.ErrLeaseLost.
+*/
+func NewLeased(
+	runner auctiontypes.AuctionRunner,
+	backend lease.Backend,
+	config lease.Config,
+	clock clock.Clock,
+	logger lager.Logger,
+) auctiontypes.AuctionRunner {
+	return &leasedRunner{
+		AuctionRunner: runner,
+		leaseRunner:   lease.NewRunner(backend, config, runner, clock, logger),
+	}
+}
+
+func (l *leasedRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	return l.leaseRunner.Run(signals, ready)
+}
+
+// QueueStatus is the wrapped runner's, if it is a QueueStatusReporter.
+func (l *leasedRunner) QueueStatus() auctiontypes.QueueStatus {
+	reporter, ok := l.AuctionRunner.(auctiontypes.QueueStatusReporter)
+	if !ok {
+		return auctiontypes.QueueStatus{}
+	}
+	return reporter.QueueStatus()
+}
@@ -0,0 +1,84 @@
+package auctionrunner_test
+
+import (
+	"os"
+	"time"
+
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/lease"
+	leasefakes "github.com/cloudfoundry-incubator/auction/lease/fakes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LeasedRunner", func() {
+	var delegate *fakeRunnerDelegate
+	var cellRep *fakes.FakeSimulationCellRep
+	var backend *leasefakes.FakeBackend
+	var clock *fakeclock.FakeClock
+	var workPool *workpool.WorkPool
+	var runner auctiontypes.AuctionRunner
+	var process ifrit.Process
+
+	BeforeEach(func() {
+		cellRep = &fakes.FakeSimulationCellRep{}
+		cellRep.StateReturns(BuildCellState("the-zone", 100, 100, 100, false, lucidOnlyRootFSProviders, nil), nil)
+		delegate = newFakeRunnerDelegate(map[string]auctiontypes.CellRep{"the-cell": cellRep})
+
+		backend = &leasefakes.FakeBackend{}
+		backend.AcquireReturns(false, nil)
+		backend.RenewReturns(true, nil)
+
+		clock = fakeclock.NewFakeClock(time.Now())
+		workPool = workpool.NewWorkPool(5)
+
+		logger := lagertest.NewTestLogger("test")
+		config := lease.Config{Owner: "auctioneer-1", TTL: 30 * time.Second}
+		runner = auctionrunner.NewLeased(
+			auctionrunner.New(delegate, fakeMetricEmitter{}, clock, workPool, logger),
+			backend,
+			config,
+			clock,
+			logger,
+		)
+		process = ifrit.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+		workPool.Stop()
+	})
+
+	It("holds auctions batched up while standing by once it acquires the lease", func() {
+		Eventually(backend.AcquireCallCount).Should(Equal(1))
+
+		runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0}, lucidRootFSURL, 10, 10)})
+		Consistently(delegate.ResultSize).Should(Equal(0))
+
+		backend.AcquireReturns(true, nil)
+		clock.Increment(10 * time.Second)
+
+		Eventually(delegate.ResultSize).Should(Equal(1))
+		Expect(delegate.Results().SuccessfulLRPs).To(HaveLen(1))
+	})
+
+	It("reports the queue of the runner it wraps", func() {
+		Eventually(backend.AcquireCallCount).Should(Equal(1))
+
+		runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest("pg-1", []uint{0, 1}, lucidRootFSURL, 10, 10)})
+
+		reporter, ok := runner.(auctiontypes.QueueStatusReporter)
+		Expect(ok).To(BeTrue())
+		Expect(reporter.QueueStatus()).To(Equal(auctiontypes.QueueStatus{LRPAuctions: 2}))
+	})
+})
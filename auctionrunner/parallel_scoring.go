@@ -0,0 +1,95 @@
+package auctionrunner
+
+import "sync"
+
+// minCandidatesPerShard keeps small candidate lists, which are cheaper to
+// score than to hand to the work pool, on the scheduler's goroutine
+const minCandidatesPerShard = 64
+
+// scoredCell is the best cell found so far for the auction being scheduled
+type scoredCell struct {
+	cell  *Cell
+	score float64
+	zone  string
+}
+
+// ScoreInParallel spreads the scoring of each auction's candidate cells over
+// up to shards goroutines from the scheduler's work pool.  Work is still
+// reserved one auction at a time, and shards are combined in order, so the
+// results are the same as scoring sequentially.  One or fewer shards scores
+// sequentially, which is the default.
+func (s *Scheduler) ScoreInParallel(shards int) {
+	s.scoringShards = shards
+}
+
+// scan visits n candidates in order, starting from the current winner, and
+// returns the new winner.  visit must only touch the candidate it is given,
+// and record at most one Candidate for it.
+//
+// When scoring in parallel each shard starts from the current winner and
+// records into its own slice of the decision's candidates; a shard's winner
+// replaces the winner of the shards before it only if it beats it, which is
+// how the sequential scan would have settled it too.
+func (s *Scheduler) scan(n int, winner scoredCell, decision *Decision, visit func(i int, winner *scoredCell, decision *Decision)) scoredCell {
+	shards := s.scoringShards
+	if n/minCandidatesPerShard < shards {
+		shards = n / minCandidatesPerShard
+	}
+
+	if shards <= 1 {
+		for i := 0; i < n; i++ {
+			visit(i, &winner, decision)
+		}
+		return winner
+	}
+
+	size := (n + shards - 1) / shards
+	shards = (n + size - 1) / size
+	winners := make([]scoredCell, shards)
+	decisions := make([]*Decision, shards)
+
+	base := 0
+	if decision != nil {
+		base = len(decision.Candidates)
+		if cap(decision.Candidates) < base+n {
+			candidates := make([]Candidate, base, base+n)
+			copy(candidates, decision.Candidates)
+			decision.Candidates = candidates
+		}
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(shards)
+	for shard := 0; shard < shards; shard++ {
+		shard := shard
+		start, end := shard*size, (shard+1)*size
+		if end > n {
+			end = n
+		}
+		if decision != nil {
+			decisions[shard] = &Decision{Candidates: decision.Candidates[base+start : base+start : base+end]}
+		}
+
+		s.workPool.Submit(func() {
+			defer wg.Done()
+			shardWinner := winner
+			for i := start; i < end; i++ {
+				visit(i, &shardWinner, decisions[shard])
+			}
+			winners[shard] = shardWinner
+		})
+	}
+	wg.Wait()
+
+	for shard, shardWinner := range winners {
+		if decision != nil {
+			// shards that recorded every candidate are already in place
+			decision.Candidates = append(decision.Candidates, decisions[shard].Candidates...)
+		}
+		if shardWinner.cell != nil && shardWinner.cell != winner.cell && s.beats(shardWinner.cell, shardWinner.score, winner.cell, winner.score) {
+			winner = shardWinner
+		}
+	}
+
+	return winner
+}
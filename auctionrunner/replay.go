@@ -0,0 +1,99 @@
+package auctionrunner
+
+import (
+	"sort"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
+)
+
+// Replay schedules a recorded Round again against in-memory cells that hold
+// the recorded state and accept all work, and returns the new placements.
+// Compare them to round.Placements with DiffPlacements to see how a change to
+// the scheduler would have placed real traffic.  Deterministic rounds are
+// replayed deterministically, settling ties with TieBreakByGuid; other rounds
+// may place tied work differently from run to run.
+func Replay(round Round, workPool *workpool.WorkPool, clock clock.Clock) Placements {
+	zones := map[string]Zone{}
+	for _, roundCell := range round.Cells {
+		rep := &replayCellRep{state: roundCell.State}
+		if roundCell.Stale {
+			zones[roundCell.Zone] = append(zones[roundCell.Zone], NewStaleCell(roundCell.Guid, rep, roundCell.State))
+		} else {
+			zones[roundCell.Zone] = append(zones[roundCell.Zone], NewCell(roundCell.Guid, rep, roundCell.State))
+		}
+	}
+
+	request := auctiontypes.AuctionRequest{
+		LRPs:  append([]auctiontypes.LRPAuction{}, round.Request.LRPs...),
+		Tasks: append([]auctiontypes.TaskAuction{}, round.Request.Tasks...),
+	}
+
+	scheduler := NewScheduler(workPool, zones, clock)
+	if round.Deterministic {
+		scheduler.Deterministic(TieBreakByGuid)
+	}
+	scheduler.RecordDecisions()
+	scheduler.Schedule(request)
+
+	return placementsFor(round.Request, scheduler)
+}
+
+// PlacementDiff is an auction that Replay placed differently from the
+// recorded round.
+type PlacementDiff struct {
+	Kind       string
+	Identifier string
+	Recorded   string
+	Replayed   string
+}
+
+// DiffPlacements returns the auctions placed differently, sorted by kind and
+// identifier.
+func DiffPlacements(recorded, replayed Placements) []PlacementDiff {
+	diffs := []PlacementDiff{}
+
+	diff := func(kind string, recorded, replayed map[string]string) {
+		for identifier, winner := range recorded {
+			if replayed[identifier] != winner {
+				diffs = append(diffs, PlacementDiff{Kind: kind, Identifier: identifier, Recorded: winner, Replayed: replayed[identifier]})
+			}
+		}
+		for identifier, winner := range replayed {
+			if _, ok := recorded[identifier]; !ok && winner != "" {
+				diffs = append(diffs, PlacementDiff{Kind: kind, Identifier: identifier, Replayed: winner})
+			}
+		}
+	}
+
+	diff(AuditKindLRP, recorded.LRPs, replayed.LRPs)
+	diff(AuditKindTask, recorded.Tasks, replayed.Tasks)
+
+	sort.Sort(placementDiffsByIdentifier(diffs))
+	return diffs
+}
+
+type placementDiffsByIdentifier []PlacementDiff
+
+func (d placementDiffsByIdentifier) Len() int      { return len(d) }
+func (d placementDiffsByIdentifier) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d placementDiffsByIdentifier) Less(i, j int) bool {
+	if d[i].Kind != d[j].Kind {
+		return d[i].Kind < d[j].Kind
+	}
+	return d[i].Identifier < d[j].Identifier
+}
+
+// replayCellRep is a CellRep that reports a fixed state and accepts all work
+type replayCellRep struct {
+	state auctiontypes.CellState
+}
+
+func (r *replayCellRep) State() (auctiontypes.CellState, error) {
+	return r.state, nil
+}
+
+func (r *replayCellRep) Perform(auctiontypes.Work) (auctiontypes.Work, error) {
+	return auctiontypes.Work{}, nil
+}
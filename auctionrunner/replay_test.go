@@ -0,0 +1,137 @@
+package auctionrunner_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recording and replaying rounds", func() {
+	var workPool *workpool.WorkPool
+	var clock *fakeclock.FakeClock
+	var round auctionrunner.Round
+
+	BeforeEach(func() {
+		workPool = workpool.NewWorkPool(5)
+		clock = fakeclock.NewFakeClock(time.Now())
+
+		round = auctionrunner.Round{
+			Time: clock.Now(),
+			Cells: []auctionrunner.RoundCell{
+				{
+					Guid:  "A-cell",
+					Zone:  "A-zone",
+					State: BuildCellState("A-zone", 100, 100, 100, false, lucidOnlyRootFSProviders, []auctiontypes.LRP{{"pg-1", 0, 10, 10}}),
+				},
+				{
+					Guid:  "B-cell",
+					Zone:  "B-zone",
+					State: BuildCellState("B-zone", 100, 100, 100, false, lucidOnlyRootFSProviders, nil),
+				},
+			},
+			Request: auctiontypes.AuctionRequest{
+				LRPs: []auctiontypes.LRPAuction{BuildLRPAuction("pg-1", 1, lucidRootFSURL, 10, 10, clock.Now())},
+				Tasks: []auctiontypes.TaskAuction{
+					BuildTaskAuction(BuildTask("tg-1", lucidRootFSURL, 10, 10), clock.Now()),
+					BuildTaskAuction(BuildTask("tg-2", windowsRootFSURL, 10, 10), clock.Now()),
+				},
+			},
+			Placements: auctionrunner.Placements{
+				LRPs:  map[string]string{"pg-1.1": "B-cell"},
+				Tasks: map[string]string{"tg-1": "B-cell", "tg-2": ""},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		workPool.Stop()
+	})
+
+	Describe("Replay", func() {
+		It("places the work as the recorded round did", func() {
+			replayed := auctionrunner.Replay(round, workPool, clock)
+			Expect(auctionrunner.DiffPlacements(round.Placements, replayed)).To(BeEmpty())
+		})
+
+		It("does not reorder the recorded request", func() {
+			round.Request.LRPs = append(round.Request.LRPs, BuildLRPAuction("pg-2", 0, lucidRootFSURL, 10, 10, clock.Now()))
+			auctionrunner.Replay(round, workPool, clock)
+			Expect(round.Request.LRPs[0].Identifier()).To(Equal("pg-1.1"))
+		})
+
+		Context("when the scheduler would now place work elsewhere", func() {
+			BeforeEach(func() {
+				round.Placements.LRPs["pg-1.1"] = "A-cell"
+			})
+
+			It("reports the difference", func() {
+				replayed := auctionrunner.Replay(round, workPool, clock)
+				Expect(auctionrunner.DiffPlacements(round.Placements, replayed)).To(Equal([]auctionrunner.PlacementDiff{
+					{Kind: auctionrunner.AuditKindLRP, Identifier: "pg-1.1", Recorded: "A-cell", Replayed: "B-cell"},
+				}))
+			})
+		})
+	})
+
+	Describe("FileRoundRecorder", func() {
+		var dir string
+		var recorder *auctionrunner.FileRoundRecorder
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "rounds")
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder, err = auctionrunner.NewFileRoundRecorder(filepath.Join(dir, "rounds"), 2, lagertest.NewTestLogger("test"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("writes rounds that can be read back and replayed", func() {
+			recorder.RecordRound(round)
+
+			paths, err := auctionrunner.RoundFiles(filepath.Join(dir, "rounds"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(HaveLen(1))
+
+			recorded, err := auctionrunner.ReadRound(paths[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorded.Placements).To(Equal(round.Placements))
+			Expect(recorded.Cells).To(HaveLen(2))
+
+			replayed := auctionrunner.Replay(recorded, workPool, clock)
+			Expect(auctionrunner.DiffPlacements(recorded.Placements, replayed)).To(BeEmpty())
+		})
+
+		It("keeps only the newest rounds", func() {
+			for i := 0; i < 3; i++ {
+				round.Time = clock.Now()
+				round.Placements.LRPs["pg-1.1"] = []string{"first", "second", "third"}[i]
+				recorder.RecordRound(round)
+				clock.Increment(time.Second)
+			}
+
+			paths, err := auctionrunner.RoundFiles(filepath.Join(dir, "rounds"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(HaveLen(2))
+
+			oldest, err := auctionrunner.ReadRound(paths[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(oldest.Placements.LRPs["pg-1.1"]).To(Equal("second"))
+		})
+	})
+})
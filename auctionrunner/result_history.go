@@ -0,0 +1,101 @@
+package auctionrunner
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+/*
+ResultHistory is an AuctionRunnerDelegate that remembers the outcome of the
+most recent auctions, by identifier, before passing results on to delegate.
+
+It keeps up to size LRP results and size task results; the oldest are
+forgotten first.  An auction that is held again, for instance after failing,
+replaces its earlier result.
+*/
+type ResultHistory struct {
+	delegate auctiontypes.AuctionRunnerDelegate
+	size     int
+
+	lrpResults  map[string]auctiontypes.LRPAuctionResult
+	lrpOrder    []string
+	taskResults map[string]auctiontypes.TaskAuctionResult
+	taskOrder   []string
+	lock        *sync.Mutex
+}
+
+func NewResultHistory(delegate auctiontypes.AuctionRunnerDelegate, size int) *ResultHistory {
+	return &ResultHistory{
+		delegate:    delegate,
+		size:        size,
+		lrpResults:  map[string]auctiontypes.LRPAuctionResult{},
+		taskResults: map[string]auctiontypes.TaskAuctionResult{},
+		lock:        &sync.Mutex{},
+	}
+}
+
+func (h *ResultHistory) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	return h.delegate.FetchCellReps()
+}
+
+func (h *ResultHistory) AuctionCompleted(results auctiontypes.AuctionResults) {
+	h.lock.Lock()
+	h.recordLRPs(auctiontypes.AuctionSucceeded, results.SuccessfulLRPs)
+	h.recordLRPs(auctiontypes.AuctionFailed, results.FailedLRPs)
+	h.recordLRPs(auctiontypes.AuctionAlreadyRunning, results.AlreadyRunningLRPs)
+	h.recordTasks(auctiontypes.AuctionSucceeded, results.SuccessfulTasks)
+	h.recordTasks(auctiontypes.AuctionFailed, results.FailedTasks)
+	h.recordTasks(auctiontypes.AuctionAlreadyRunning, results.AlreadyRunningTasks)
+	h.lock.Unlock()
+
+	h.delegate.AuctionCompleted(results)
+}
+
+func (h *ResultHistory) LRPResult(identifier string) (auctiontypes.LRPAuctionResult, bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	result, ok := h.lrpResults[identifier]
+	return result, ok
+}
+
+func (h *ResultHistory) TaskResult(identifier string) (auctiontypes.TaskAuctionResult, bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	result, ok := h.taskResults[identifier]
+	return result, ok
+}
+
+// recordLRPs and recordTasks must be called with the lock held
+
+func (h *ResultHistory) recordLRPs(outcome auctiontypes.AuctionOutcome, lrpAuctions []auctiontypes.LRPAuction) {
+	for _, lrpAuction := range lrpAuctions {
+		identifier := lrpAuction.Identifier()
+		if _, ok := h.lrpResults[identifier]; !ok {
+			h.lrpOrder = append(h.lrpOrder, identifier)
+		}
+		h.lrpResults[identifier] = auctiontypes.LRPAuctionResult{Outcome: outcome, Auction: lrpAuction}
+	}
+
+	for len(h.lrpOrder) > h.size {
+		delete(h.lrpResults, h.lrpOrder[0])
+		h.lrpOrder = h.lrpOrder[1:]
+	}
+}
+
+func (h *ResultHistory) recordTasks(outcome auctiontypes.AuctionOutcome, taskAuctions []auctiontypes.TaskAuction) {
+	for _, taskAuction := range taskAuctions {
+		identifier := taskAuction.Identifier()
+		if _, ok := h.taskResults[identifier]; !ok {
+			h.taskOrder = append(h.taskOrder, identifier)
+		}
+		h.taskResults[identifier] = auctiontypes.TaskAuctionResult{Outcome: outcome, Auction: taskAuction}
+	}
+
+	for len(h.taskOrder) > h.size {
+		delete(h.taskResults, h.taskOrder[0])
+		h.taskOrder = h.taskOrder[1:]
+	}
+}
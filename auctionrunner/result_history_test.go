@@ -0,0 +1,63 @@
+package auctionrunner_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResultHistory", func() {
+	var delegate *fakeRunnerDelegate
+	var history *auctionrunner.ResultHistory
+
+	BeforeEach(func() {
+		delegate = newFakeRunnerDelegate(map[string]auctiontypes.CellRep{})
+		history = auctionrunner.NewResultHistory(delegate, 2)
+	})
+
+	It("records the outcome of each auction and passes the results on", func() {
+		succeeded := BuildLRPAuction("pg-1", 0, lucidRootFSURL, 10, 10, time.Now())
+		failed := BuildTaskAuction(BuildTask("tg-1", lucidRootFSURL, 10, 10), time.Now())
+
+		history.AuctionCompleted(auctiontypes.AuctionResults{
+			SuccessfulLRPs: []auctiontypes.LRPAuction{succeeded},
+			FailedTasks:    []auctiontypes.TaskAuction{failed},
+		})
+
+		lrpResult, ok := history.LRPResult(succeeded.Identifier())
+		Expect(ok).To(BeTrue())
+		Expect(lrpResult).To(Equal(auctiontypes.LRPAuctionResult{Outcome: auctiontypes.AuctionSucceeded, Auction: succeeded}))
+
+		taskResult, ok := history.TaskResult("tg-1")
+		Expect(ok).To(BeTrue())
+		Expect(taskResult.Outcome).To(Equal(auctiontypes.AuctionFailed))
+
+		Expect(delegate.ResultSize()).To(Equal(2))
+	})
+
+	It("replaces earlier results for the same auction", func() {
+		lrpAuction := BuildLRPAuction("pg-1", 0, lucidRootFSURL, 10, 10, time.Now())
+		history.AuctionCompleted(auctiontypes.AuctionResults{FailedLRPs: []auctiontypes.LRPAuction{lrpAuction}})
+		history.AuctionCompleted(auctiontypes.AuctionResults{SuccessfulLRPs: []auctiontypes.LRPAuction{lrpAuction}})
+
+		result, _ := history.LRPResult(lrpAuction.Identifier())
+		Expect(result.Outcome).To(Equal(auctiontypes.AuctionSucceeded))
+	})
+
+	It("forgets the oldest results beyond its size", func() {
+		for _, processGuid := range []string{"pg-1", "pg-2", "pg-3"} {
+			history.AuctionCompleted(auctiontypes.AuctionResults{
+				SuccessfulLRPs: []auctiontypes.LRPAuction{BuildLRPAuction(processGuid, 0, lucidRootFSURL, 10, 10, time.Now())},
+			})
+		}
+
+		_, ok := history.LRPResult("pg-1.0")
+		Expect(ok).To(BeFalse())
+		_, ok = history.LRPResult("pg-3.0")
+		Expect(ok).To(BeTrue())
+	})
+})
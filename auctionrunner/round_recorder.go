@@ -0,0 +1,184 @@
+package auctionrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/pivotal-golang/lager"
+)
+
+const roundFilePrefix = "round-"
+const roundFileSuffix = ".json"
+
+// RoundRecorder is given a Round every time the runner schedules work.  Set it
+// in Config to capture production rounds for Replay.
+type RoundRecorder interface {
+	RecordRound(Round)
+}
+
+// Round is everything the scheduler saw in one scheduling pass: the cells'
+// state before any work was reserved, the auctions, and where the scheduler
+// placed each of them.  Rounds that reschedule stale work are recorded
+// separately.  Deterministic is set if the round was scheduled
+// deterministically.
+type Round struct {
+	Time          time.Time                   `json:"time"`
+	Cells         []RoundCell                 `json:"cells"`
+	Request       auctiontypes.AuctionRequest `json:"request"`
+	Placements    Placements                  `json:"placements"`
+	Deterministic bool                        `json:"deterministic,omitempty"`
+}
+
+type RoundCell struct {
+	Guid  string                 `json:"guid"`
+	Zone  string                 `json:"zone"`
+	Stale bool                   `json:"stale,omitempty"`
+	State auctiontypes.CellState `json:"state"`
+}
+
+// Placements maps auction identifiers to the guid of the cell the scheduler
+// picked, or to "" when it could not place the work.  A placement is the
+// scheduler's decision: the cell may still have rejected the work.
+type Placements struct {
+	LRPs  map[string]string `json:"lrps"`
+	Tasks map[string]string `json:"tasks"`
+}
+
+func snapshotCells(zones map[string]Zone) []RoundCell {
+	cells := []RoundCell{}
+	for zone, zoneCells := range zones {
+		for _, cell := range zoneCells {
+			cells = append(cells, RoundCell{
+				Guid:  cell.Guid,
+				Zone:  zone,
+				Stale: cell.stale,
+				State: cell.state,
+			})
+		}
+	}
+
+	return cells
+}
+
+func placementsFor(request auctiontypes.AuctionRequest, scheduler *Scheduler) Placements {
+	placements := Placements{
+		LRPs:  map[string]string{},
+		Tasks: map[string]string{},
+	}
+
+	for _, lrpAuction := range request.LRPs {
+		identifier := lrpAuction.Identifier()
+		placements.LRPs[identifier] = scheduler.LRPDecisions()[identifier].Winner
+	}
+	for _, taskAuction := range request.Tasks {
+		identifier := taskAuction.Identifier()
+		placements.Tasks[identifier] = scheduler.TaskDecisions()[identifier].Winner
+	}
+
+	return placements
+}
+
+// FileRoundRecorder writes every Round to its own JSON file in a directory,
+// keeping only the newest maxRounds files.  Zero keeps every round.
+type FileRoundRecorder struct {
+	dir       string
+	maxRounds int
+	logger    lager.Logger
+
+	lock     *sync.Mutex
+	sequence int
+	written  []string
+}
+
+func NewFileRoundRecorder(dir string, maxRounds int, logger lager.Logger) (*FileRoundRecorder, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := RoundFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileRoundRecorder{
+		dir:       dir,
+		maxRounds: maxRounds,
+		logger:    logger.Session("file-round-recorder", lager.Data{"dir": dir}),
+		lock:      &sync.Mutex{},
+		written:   existing,
+	}, nil
+}
+
+func (r *FileRoundRecorder) RecordRound(round Round) {
+	payload, err := json.Marshal(round)
+	if err != nil {
+		r.logger.Error("failed-to-marshal-round", err)
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.sequence++
+	name := fmt.Sprintf("%s%020d-%06d%s", roundFilePrefix, round.Time.UnixNano(), r.sequence%1000000, roundFileSuffix)
+	path := filepath.Join(r.dir, name)
+
+	err = ioutil.WriteFile(path, payload, 0644)
+	if err != nil {
+		r.logger.Error("failed-to-write-round", err, lager.Data{"path": path})
+		return
+	}
+	r.written = append(r.written, path)
+
+	for r.maxRounds > 0 && len(r.written) > r.maxRounds {
+		err := os.Remove(r.written[0])
+		if err != nil && !os.IsNotExist(err) {
+			r.logger.Error("failed-to-remove-round", err, lager.Data{"path": r.written[0]})
+		}
+		r.written = r.written[1:]
+	}
+}
+
+// RoundFiles lists the rounds recorded in dir, oldest first.
+func RoundFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, roundFilePrefix) || !strings.HasSuffix(name, roundFileSuffix) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func ReadRound(path string) (Round, error) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Round{}, err
+	}
+
+	var round Round
+	err = json.Unmarshal(payload, &round)
+	if err != nil {
+		return Round{}, err
+	}
+
+	return round, nil
+}
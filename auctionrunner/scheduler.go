@@ -28,6 +28,47 @@ type Scheduler struct {
 	workPool *workpool.WorkPool
 	zones    map[string]Zone
 	clock    clock.Clock
+
+	tieBreak      TieBreakPolicy
+	scoringShards int
+
+	// indexes built on first use and kept up to date as work is reserved, so
+	// that an auction scores each bucket of identical cells once
+	zoneNames     []string
+	rootFSIndexes map[string][]*zoneIndex
+	cellIndexes   map[*Cell][]*zoneIndex
+	zoneInstances map[string]map[string]int
+
+	recordDecisions bool
+	lrpDecisions    map[string]Decision
+	taskDecisions   map[string]Decision
+}
+
+// TieBreakPolicy settles an auction between two cells with the same score.
+// It returns true if candidate should win instead of current, and must order
+// cells strictly, as a less function would.
+type TieBreakPolicy func(candidate, current *Cell) bool
+
+// TieBreakByGuid prefers the cell with the lowest guid.
+func TieBreakByGuid(candidate, current *Cell) bool {
+	return candidate.Guid < current.Guid
+}
+
+// Decision records how the scheduler placed one auction: every cell it
+// scored, and the cell it picked.
+type Decision struct {
+	Candidates []Candidate
+	Winner     string
+	Score      float64
+}
+
+// Candidate is a cell an auction was scored against.  Error is set when the
+// cell could not take the work at all.
+type Candidate struct {
+	CellGuid string
+	Zone     string
+	Score    float64
+	Error    string
 }
 
 func NewScheduler(
@@ -42,13 +83,60 @@ func NewScheduler(
 	}
 }
 
+// Deterministic makes the scheduler place identical requests on identical cells
+// identically.  Zones are considered in name order and cells in guid order, and
+// equal scores are settled by tieBreak, or by TieBreakByGuid if it is nil.
+// Otherwise ties go to whichever cell happens to be scored first.
+func (s *Scheduler) Deterministic(tieBreak TieBreakPolicy) {
+	if tieBreak == nil {
+		tieBreak = TieBreakByGuid
+	}
+	s.tieBreak = tieBreak
+
+	zones := make(map[string]Zone, len(s.zones))
+	for name, zone := range s.zones {
+		cells := append(Zone{}, zone...)
+		sort.Sort(cellsByGuid(cells))
+		zones[name] = cells
+	}
+	s.zones = zones
+	s.zoneNames = nil
+	s.rootFSIndexes = nil
+	s.cellIndexes = nil
+}
+
+// RecordDecisions makes the scheduler keep a Decision for every auction it
+// places.  A Decision lists every candidate cell, so recording bypasses the
+// capacity index and its pruning and scores every cell: each auction costs
+// O(cells) rather than O(buckets), and allocates for every cell scored.  It
+// is off by default; BenchmarkScheduleRecordingDecisions shows the cost.
+func (s *Scheduler) RecordDecisions() {
+	s.recordDecisions = true
+	s.lrpDecisions = map[string]Decision{}
+	s.taskDecisions = map[string]Decision{}
+}
+
+// LRPDecisions and TaskDecisions return the recorded decisions by auction
+// identifier.  They are empty unless RecordDecisions was called.
+func (s *Scheduler) LRPDecisions() map[string]Decision {
+	return s.lrpDecisions
+}
+
+func (s *Scheduler) TaskDecisions() map[string]Decision {
+	return s.taskDecisions
+}
+
 /*
 Schedule takes in a set of job requests (LRP start auctions and task starts) and
 assigns the work to available cells according to the diego scoring algorithm. The
-scheduler is single-threaded.  It determines scheduling of jobs one at a time so
-that each calculation reflects available resources correctly.  It commits the
+scheduler determines scheduling of jobs one at a time so that each calculation
+reflects available resources correctly; with ScoreInParallel, the cells are
+scored for each job in parallel.  It commits the
 work in batches at the end, for better network performance.  Schedule returns
 AuctionResults, indicating the success or failure of each requested job.
+
+Jobs that are already running on one of the cells are not scheduled again; they
+are reported as AlreadyRunningLRPs and AlreadyRunningTasks instead.
 */
 func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auctiontypes.AuctionResults {
 	results := auctiontypes.AuctionResults{}
@@ -65,6 +153,8 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 		return s.markResults(results)
 	}
 
+	auctionRequest, results.AlreadyRunningLRPs, results.AlreadyRunningTasks = s.removeRunningWork(auctionRequest)
+
 	var successfulLRPs = map[string]auctiontypes.LRPAuction{}
 	var lrpStartAuctionLookup = map[string]auctiontypes.LRPAuction{}
 	var successfulTasks = map[string]auctiontypes.TaskAuction{}
@@ -103,7 +193,7 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 
 	auctionLRP(lrpsAfterTasks)
 
-	failedWorks := s.commitCells()
+	failedWorks, staleWorks := s.commitCells()
 	for _, failedWork := range failedWorks {
 		for _, failedStart := range failedWork.LRPs {
 			identifier := failedStart.Identifier()
@@ -118,6 +208,24 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 		}
 	}
 
+	for _, staleWork := range staleWorks {
+		for _, staleStart := range staleWork.LRPs {
+			identifier := staleStart.Identifier()
+			delete(successfulLRPs, identifier)
+			lrpAuction := lrpStartAuctionLookup[identifier]
+			lrpAuction.PlacementError = auctiontypes.ErrorStaleCellState.Error()
+			results.FailedLRPs = append(results.FailedLRPs, lrpAuction)
+		}
+
+		for _, staleTask := range staleWork.Tasks {
+			identifier := auctiontypes.IdentifierForTask(staleTask)
+			delete(successfulTasks, identifier)
+			taskAuction := taskAuctionLookup[identifier]
+			taskAuction.PlacementError = auctiontypes.ErrorStaleCellState.Error()
+			results.FailedTasks = append(results.FailedTasks, taskAuction)
+		}
+	}
+
 	for _, successfulStart := range successfulLRPs {
 		results.SuccessfulLRPs = append(results.SuccessfulLRPs, successfulStart)
 	}
@@ -149,6 +257,47 @@ func (s *Scheduler) markResults(results auctiontypes.AuctionResults) auctiontype
 	return results
 }
 
+func (s *Scheduler) removeRunningWork(auctionRequest auctiontypes.AuctionRequest) (auctiontypes.AuctionRequest, []auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
+	runningLRPs := map[string]string{}
+	runningTasks := map[string]string{}
+	for _, zone := range s.zones {
+		for _, cell := range zone {
+			for _, lrp := range cell.state.LRPs {
+				runningLRPs[lrp.Identifier()] = cell.Guid
+			}
+			for _, task := range cell.state.Tasks {
+				runningTasks[task.TaskGuid] = cell.Guid
+			}
+		}
+	}
+
+	remaining := auctiontypes.AuctionRequest{}
+	var alreadyRunningLRPs []auctiontypes.LRPAuction
+	var alreadyRunningTasks []auctiontypes.TaskAuction
+
+	for _, lrpAuction := range auctionRequest.LRPs {
+		cellGuid, ok := runningLRPs[lrpAuction.Identifier()]
+		if !ok {
+			remaining.LRPs = append(remaining.LRPs, lrpAuction)
+			continue
+		}
+		lrpAuction.Winner = cellGuid
+		alreadyRunningLRPs = append(alreadyRunningLRPs, lrpAuction)
+	}
+
+	for _, taskAuction := range auctionRequest.Tasks {
+		cellGuid, ok := runningTasks[taskAuction.Identifier()]
+		if !ok {
+			remaining.Tasks = append(remaining.Tasks, taskAuction)
+			continue
+		}
+		taskAuction.Winner = cellGuid
+		alreadyRunningTasks = append(alreadyRunningTasks, taskAuction)
+	}
+
+	return remaining, alreadyRunningLRPs, alreadyRunningTasks
+}
+
 func splitLRPS(lrps []auctiontypes.LRPAuction) ([]auctiontypes.LRPAuction, []auctiontypes.LRPAuction) {
 	const pivot = 0
 
@@ -161,7 +310,7 @@ func splitLRPS(lrps []auctiontypes.LRPAuction) ([]auctiontypes.LRPAuction, []auc
 	return lrps[:0], lrps[0:]
 }
 
-func (s *Scheduler) commitCells() []auctiontypes.Work {
+func (s *Scheduler) commitCells() ([]auctiontypes.Work, []auctiontypes.Work) {
 	wg := &sync.WaitGroup{}
 	for _, cells := range s.zones {
 		wg.Add(len(cells))
@@ -169,50 +318,86 @@ func (s *Scheduler) commitCells() []auctiontypes.Work {
 
 	lock := &sync.Mutex{}
 	failedWorks := []auctiontypes.Work{}
+	staleWorks := []auctiontypes.Work{}
 
 	for _, cells := range s.zones {
 		for _, cell := range cells {
 			cell := cell
 			s.workPool.Submit(func() {
 				defer wg.Done()
-				failedWork := cell.Commit()
+				failedWork, err := cell.Commit()
 
 				lock.Lock()
-				failedWorks = append(failedWorks, failedWork)
+				if err == auctiontypes.ErrorStaleCellState {
+					staleWorks = append(staleWorks, failedWork)
+				} else {
+					failedWorks = append(failedWorks, failedWork)
+				}
 				lock.Unlock()
 			})
 		}
 	}
 
 	wg.Wait()
-	return failedWorks
+	return failedWorks, staleWorks
 }
 
 func (s *Scheduler) scheduleLRPAuction(lrpAuction auctiontypes.LRPAuction) (auctiontypes.LRPAuction, error) {
-	var winnerCell *Cell
-	winnerScore := 1e20
+	winner := scoredCell{score: 1e20}
 
-	zones := accumulateZonesByInstances(s.zones, lrpAuction)
+	processGuid := lrpAuction.DesiredLRP.ProcessGuid
+	indexes := s.indexesFor(lrpAuction.DesiredLRP.RootFS)
 
-	filteredZones := filterZonesByRootFS(zones, lrpAuction.DesiredLRP.RootFS)
-
-	if len(filteredZones) == 0 {
+	if len(indexes) == 0 {
 		return auctiontypes.LRPAuction{}, auctiontypes.ErrorCellMismatch
 	}
 
-	sortedZones := sortZonesByInstances(filteredZones)
+	zones := make([]lrpByZone, 0, len(indexes))
+	for _, index := range indexes {
+		zones = append(zones, lrpByZone{
+			name:      index.name,
+			zone:      index.cells,
+			index:     index,
+			instances: s.instancesInZone(index.name, processGuid),
+		})
+	}
+	sortedZones := sortZonesByInstances(zones)
+
+	var decision *Decision
+	if s.recordDecisions {
+		decision = &Decision{}
+		defer func() { s.lrpDecisions[lrpAuction.Identifier()] = *decision }()
+	}
 
 	for zoneIndex, lrpByZone := range sortedZones {
-		for _, cell := range lrpByZone.zone {
-			score, err := cell.ScoreForLRPAuction(lrpAuction)
-			if err != nil {
-				continue
-			}
+		lrpByZone := lrpByZone
 
-			if score < winnerScore {
-				winnerScore = score
-				winnerCell = cell
-			}
+		if s.recordDecisions {
+			winner = s.scan(len(lrpByZone.zone), winner, decision, func(i int, winner *scoredCell, decision *Decision) {
+				cell := lrpByZone.zone[i]
+				score, err := cell.ScoreForLRPAuction(lrpAuction)
+				s.consider(winner, decision, lrpByZone.name, cell, score, err)
+			})
+		} else {
+			buckets := lrpByZone.index.buckets
+			winner = s.scan(len(buckets), winner, nil, func(i int, winner *scoredCell, _ *Decision) {
+				bucket := buckets[i]
+
+				// no cell in the bucket scores better than one without instances
+				bound, err := bucket.cells[0].scoreForLRPAuction(lrpAuction, 0)
+				if err != nil || !s.mightWin(bound, winner.score) {
+					return
+				}
+
+				if lrpByZone.instances == 0 {
+					s.consider(winner, nil, lrpByZone.name, bucket.cells[0], bound, nil)
+					return
+				}
+
+				cell := fewestInstances(bucket.cells, processGuid)
+				score, err := cell.ScoreForLRPAuction(lrpAuction)
+				s.consider(winner, nil, lrpByZone.name, cell, score, err)
+			})
 		}
 
 		if zoneIndex+1 < len(sortedZones) &&
@@ -220,64 +405,215 @@ func (s *Scheduler) scheduleLRPAuction(lrpAuction auctiontypes.LRPAuction) (auct
 			continue
 		}
 
-		if winnerCell != nil {
+		if winner.cell != nil {
 			break
 		}
 	}
 
-	if winnerCell == nil {
+	if winner.cell == nil {
 		return auctiontypes.LRPAuction{}, auctiontypes.ErrorInsufficientResources
 	}
 
-	err := winnerCell.ReserveLRP(lrpAuction)
+	key := bucketKeyFor(winner.cell)
+	err := winner.cell.ReserveLRP(lrpAuction)
 	if err != nil {
 		return auctiontypes.LRPAuction{}, err
 	}
+	s.reindex(winner.cell, key)
+	s.zoneInstances[winner.zone][processGuid]++
+	decision.pick(winner.cell, winner.score)
 
-	lrpAuction.Winner = winnerCell.Guid
+	lrpAuction.Winner = winner.cell.Guid
 	return lrpAuction, nil
 }
 
 func (s *Scheduler) scheduleTaskAuction(taskAuction auctiontypes.TaskAuction) (auctiontypes.TaskAuction, error) {
-	var winnerCell *Cell
-	winnerScore := 1e20
+	winner := scoredCell{score: 1e20}
 
-	filteredZones := []Zone{}
+	indexes := s.indexesFor(taskAuction.Task.RootFS)
 
-	for _, zone := range s.zones {
-		cells := zone.FilterCells(taskAuction.Task.RootFS)
-		if len(cells) > 0 {
-			filteredZones = append(filteredZones, Zone(cells))
-		}
+	if len(indexes) == 0 {
+		return auctiontypes.TaskAuction{}, auctiontypes.ErrorCellMismatch
 	}
 
-	if len(filteredZones) == 0 {
-		return auctiontypes.TaskAuction{}, auctiontypes.ErrorCellMismatch
+	var decision *Decision
+	if s.recordDecisions {
+		decision = &Decision{}
+		defer func() { s.taskDecisions[taskAuction.Identifier()] = *decision }()
 	}
 
-	for _, zone := range filteredZones {
-		for _, cell := range zone {
-			score, err := cell.ScoreForTask(taskAuction.Task)
-			if err != nil {
-				continue
-			}
+	for _, index := range indexes {
+		index := index
 
-			if score < winnerScore {
-				winnerScore = score
-				winnerCell = cell
-			}
+		if s.recordDecisions {
+			winner = s.scan(len(index.cells), winner, decision, func(i int, winner *scoredCell, decision *Decision) {
+				cell := index.cells[i]
+				score, err := cell.ScoreForTask(taskAuction.Task)
+				s.consider(winner, decision, index.name, cell, score, err)
+			})
+			continue
 		}
+
+		buckets := index.buckets
+		winner = s.scan(len(buckets), winner, nil, func(i int, winner *scoredCell, _ *Decision) {
+			// every cell in the bucket scores the same, and the first is the
+			// one the tie-break policy prefers
+			cell := buckets[i].cells[0]
+			score, err := cell.ScoreForTask(taskAuction.Task)
+			if err == nil && s.mightWin(score, winner.score) {
+				s.consider(winner, nil, index.name, cell, score, nil)
+			}
+		})
 	}
 
-	if winnerCell == nil {
+	if winner.cell == nil {
 		return auctiontypes.TaskAuction{}, auctiontypes.ErrorInsufficientResources
 	}
 
-	err := winnerCell.ReserveTask(taskAuction.Task)
+	key := bucketKeyFor(winner.cell)
+	err := winner.cell.ReserveTask(taskAuction.Task)
 	if err != nil {
 		return auctiontypes.TaskAuction{}, err
 	}
+	s.reindex(winner.cell, key)
+	decision.pick(winner.cell, winner.score)
 
-	taskAuction.Winner = winnerCell.Guid
+	taskAuction.Winner = winner.cell.Guid
 	return taskAuction, nil
 }
+
+// consider records a scored cell and makes it the winner if it beats the
+// current one
+func (s *Scheduler) consider(winner *scoredCell, decision *Decision, zone string, cell *Cell, score float64, err error) {
+	decision.consider(cell, score, err)
+	if err == nil && s.beats(cell, score, winner.cell, winner.score) {
+		*winner = scoredCell{cell: cell, score: score, zone: zone}
+	}
+}
+
+func (s *Scheduler) beats(cell *Cell, score float64, winnerCell *Cell, winnerScore float64) bool {
+	if score < winnerScore {
+		return true
+	}
+
+	return s.tieBreak != nil && winnerCell != nil && score == winnerScore && s.tieBreak(cell, winnerCell)
+}
+
+// mightWin is false when a cell scoring no better than bound can't beat the
+// current winner, so that scoring it can be skipped
+func (s *Scheduler) mightWin(bound float64, winnerScore float64) bool {
+	if s.tieBreak != nil {
+		return bound <= winnerScore
+	}
+	return bound < winnerScore
+}
+
+// fewestInstances returns the first of the cells running the fewest instances
+// of the process
+func fewestInstances(cells []*Cell, processGuid string) *Cell {
+	fewest := cells[0]
+	for _, cell := range cells {
+		if cell.Instances(processGuid) < fewest.Instances(processGuid) {
+			fewest = cell
+		}
+		if fewest.Instances(processGuid) == 0 {
+			break
+		}
+	}
+	return fewest
+}
+
+// indexesFor returns an index of the cells in each zone that can run rootFS,
+// keeping only zones with such cells.  Zones are listed in name order when the
+// scheduler is deterministic, and in map order otherwise.
+func (s *Scheduler) indexesFor(rootFS string) []*zoneIndex {
+	if indexes, ok := s.rootFSIndexes[rootFS]; ok {
+		return indexes
+	}
+
+	if s.zoneNames == nil {
+		s.zoneNames = make([]string, 0, len(s.zones))
+		for name := range s.zones {
+			s.zoneNames = append(s.zoneNames, name)
+		}
+		if s.tieBreak != nil {
+			sort.Strings(s.zoneNames)
+		}
+	}
+
+	if s.rootFSIndexes == nil {
+		s.rootFSIndexes = map[string][]*zoneIndex{}
+		s.cellIndexes = map[*Cell][]*zoneIndex{}
+	}
+
+	indexes := []*zoneIndex{}
+	for _, name := range s.zoneNames {
+		zone := s.zones[name]
+		cells := zone.FilterCells(rootFS)
+		if len(cells) == 0 {
+			continue
+		}
+
+		index := newZoneIndex(name, cells, s.tieBreak)
+		for _, cell := range cells {
+			s.cellIndexes[cell] = append(s.cellIndexes[cell], index)
+		}
+		indexes = append(indexes, index)
+	}
+
+	s.rootFSIndexes[rootFS] = indexes
+	return indexes
+}
+
+// reindex moves a cell that work was reserved on to its new buckets
+func (s *Scheduler) reindex(cell *Cell, old bucketKey) {
+	for _, index := range s.cellIndexes[cell] {
+		index.update(cell, old)
+	}
+}
+
+// instancesInZone counts the instances of the process on every cell in the
+// zone, whatever rootfs the cells support
+func (s *Scheduler) instancesInZone(zone, processGuid string) int {
+	if s.zoneInstances == nil {
+		s.zoneInstances = make(map[string]map[string]int, len(s.zones))
+		for name, cells := range s.zones {
+			instances := map[string]int{}
+			for _, cell := range cells {
+				for _, lrp := range cell.state.LRPs {
+					instances[lrp.ProcessGuid]++
+				}
+			}
+			s.zoneInstances[name] = instances
+		}
+	}
+
+	return s.zoneInstances[zone][processGuid]
+}
+
+type cellsByGuid []*Cell
+
+func (c cellsByGuid) Len() int           { return len(c) }
+func (c cellsByGuid) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c cellsByGuid) Less(i, j int) bool { return c[i].Guid < c[j].Guid }
+
+func (d *Decision) consider(cell *Cell, score float64, err error) {
+	if d == nil {
+		return
+	}
+
+	candidate := Candidate{CellGuid: cell.Guid, Zone: cell.state.Zone, Score: score}
+	if err != nil {
+		candidate.Error = err.Error()
+	}
+	d.Candidates = append(d.Candidates, candidate)
+}
+
+func (d *Decision) pick(cell *Cell, score float64) {
+	if d == nil {
+		return
+	}
+
+	d.Winner = cell.Guid
+	d.Score = score
+}
@@ -0,0 +1,243 @@
+package auctionrunner_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+)
+
+const benchmarkProcesses = 1000
+const benchmarkRunningLRPsPerCell = 10
+
+var benchmarkSizes = []struct{ cells, auctions int }{
+	{100, 1000},
+	{1000, 10000},
+	{10000, 100000},
+}
+
+func BenchmarkSchedule(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		size := size
+		b.Run(fmt.Sprintf("%d cells, %d auctions", size.cells, size.auctions), func(b *testing.B) {
+			benchmarkSchedule(b, size.cells, size.auctions, false, false)
+		})
+	}
+}
+
+func BenchmarkScheduleDeterministic(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		size := size
+		b.Run(fmt.Sprintf("%d cells, %d auctions", size.cells, size.auctions), func(b *testing.B) {
+			benchmarkSchedule(b, size.cells, size.auctions, true, false)
+		})
+	}
+}
+
+// BenchmarkScheduleRecordingDecisions schedules as BenchmarkSchedule does, but
+// records decisions, as an audit sink or round recorder does.  Recording
+// bypasses the index and scores every cell, so it costs O(cells) per auction.
+// It skips the largest size, whose decisions would list a billion candidates.
+func BenchmarkScheduleRecordingDecisions(b *testing.B) {
+	for _, size := range benchmarkSizes[:len(benchmarkSizes)-1] {
+		size := size
+		b.Run(fmt.Sprintf("%d cells, %d auctions", size.cells, size.auctions), func(b *testing.B) {
+			benchmarkSchedule(b, size.cells, size.auctions, false, true)
+		})
+	}
+}
+
+// BenchmarkScoreInParallel compares sequential and parallel scoring on the
+// simulation's "Large Cold LRPStarts" workload.  Recording decisions scores
+// every cell, which is where sharding pays off; otherwise most cells are
+// pruned by the index.
+func BenchmarkScoreInParallel(b *testing.B) {
+	for _, numCells := range []int{100, 1000} {
+		for _, recordDecisions := range []bool{false, true} {
+			for _, shards := range []int{1, 8} {
+				numCells, recordDecisions, shards := numCells, recordDecisions, shards
+				name := fmt.Sprintf("%d cells, recording decisions %t, %d shards", numCells, recordDecisions, shards)
+				b.Run(name, func(b *testing.B) {
+					benchmarkScoreInParallel(b, numCells, recordDecisions, shards)
+				})
+			}
+		}
+	}
+}
+
+func benchmarkScoreInParallel(b *testing.B, numCells int, recordDecisions bool, shards int) {
+	workPool := workpool.NewWorkPool(100)
+	defer workPool.Stop()
+
+	request := simulationAuctionRequest()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		zones := simulationZones(numCells)
+		request := auctiontypes.AuctionRequest{
+			LRPs: append([]auctiontypes.LRPAuction{}, request.LRPs...),
+		}
+		b.StartTimer()
+
+		scheduler := auctionrunner.NewScheduler(workPool, zones, clock.NewClock())
+		scheduler.Deterministic(nil)
+		if recordDecisions {
+			scheduler.RecordDecisions()
+		}
+		scheduler.ScoreInParallel(shards)
+		scheduler.Schedule(request)
+	}
+}
+
+func benchmarkSchedule(b *testing.B, numCells, numAuctions int, deterministic, recordDecisions bool) {
+	workPool := workpool.NewWorkPool(100)
+	defer workPool.Stop()
+
+	request := benchmarkAuctionRequest(numAuctions)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		zones := benchmarkZones(numCells)
+		request := auctiontypes.AuctionRequest{
+			LRPs:  append([]auctiontypes.LRPAuction{}, request.LRPs...),
+			Tasks: append([]auctiontypes.TaskAuction{}, request.Tasks...),
+		}
+		b.StartTimer()
+
+		scheduler := auctionrunner.NewScheduler(workPool, zones, clock.NewClock())
+		if deterministic {
+			scheduler.Deterministic(nil)
+		}
+		if recordDecisions {
+			scheduler.RecordDecisions()
+		}
+		results := scheduler.Schedule(request)
+		if len(results.FailedLRPs)+len(results.FailedTasks) > 0 {
+			b.Fatalf("%d lrps and %d tasks failed to place", len(results.FailedLRPs), len(results.FailedTasks))
+		}
+	}
+}
+
+// benchmarkZones builds cells across three zones, each already running a few
+// instances of the benchmark processes and with room for plenty more
+func benchmarkZones(numCells int) map[string]auctionrunner.Zone {
+	zones := map[string]auctionrunner.Zone{}
+	rootFSProviders := auctiontypes.RootFSProviders{models.PreloadedRootFSScheme: auctiontypes.NewFixedSetRootFSProvider(lucidStack)}
+
+	for i := 0; i < numCells; i++ {
+		zone := fmt.Sprintf("zone-%d", i%3)
+
+		lrps := make([]auctiontypes.LRP, 0, benchmarkRunningLRPsPerCell)
+		for j := 0; j < benchmarkRunningLRPsPerCell; j++ {
+			processGuid := fmt.Sprintf("pg-%d", (i*benchmarkRunningLRPsPerCell+j)%benchmarkProcesses)
+			lrps = append(lrps, auctiontypes.LRP{ProcessGuid: processGuid, Index: 10000 + i, MemoryMB: 64, DiskMB: 64})
+		}
+
+		state := auctiontypes.CellState{
+			RootFSProviders:    rootFSProviders,
+			TotalResources:     auctiontypes.Resources{MemoryMB: 64 * 1024, DiskMB: 64 * 1024, Containers: 1000},
+			AvailableResources: auctiontypes.Resources{MemoryMB: 64*1024 - 64*len(lrps), DiskMB: 64*1024 - 64*len(lrps), Containers: 1000 - len(lrps)},
+			LRPs:               lrps,
+			Zone:               zone,
+		}
+
+		guid := fmt.Sprintf("cell-%d", i)
+		zones[zone] = append(zones[zone], auctionrunner.NewCell(guid, benchmarkCellRep{}, state))
+	}
+
+	return zones
+}
+
+// benchmarkAuctionRequest asks for nine LRP instances for every task
+func benchmarkAuctionRequest(numAuctions int) auctiontypes.AuctionRequest {
+	request := auctiontypes.AuctionRequest{}
+	now := time.Now()
+
+	for i := 0; i < numAuctions; i++ {
+		if i%10 == 9 {
+			request.Tasks = append(request.Tasks, BuildTaskAuction(BuildTask(fmt.Sprintf("tg-%d", i), lucidRootFSURL, 64, 64), now))
+			continue
+		}
+		request.LRPs = append(request.LRPs, BuildLRPAuction(fmt.Sprintf("pg-%d", i%benchmarkProcesses), i/benchmarkProcesses, lucidRootFSURL, 64, 64, now))
+	}
+
+	return request
+}
+
+// simulationZones builds empty cells like the simulation's: 100MB of memory
+// and disk, 100 containers, alternating between two zones
+func simulationZones(numCells int) map[string]auctionrunner.Zone {
+	zones := map[string]auctionrunner.Zone{}
+	rootFSProviders := auctiontypes.RootFSProviders{models.PreloadedRootFSScheme: auctiontypes.NewFixedSetRootFSProvider(lucidStack)}
+	resources := auctiontypes.Resources{MemoryMB: 100, DiskMB: 100, Containers: 100}
+
+	for i := 0; i < numCells; i++ {
+		zone := fmt.Sprintf("Z%d", i%2)
+		state := auctiontypes.CellState{
+			RootFSProviders:    rootFSProviders,
+			TotalResources:     resources,
+			AvailableResources: resources,
+			Zone:               zone,
+		}
+
+		guid := fmt.Sprintf("cell-%d", i)
+		zones[zone] = append(zones[zone], auctionrunner.NewCell(guid, benchmarkCellRep{}, state))
+	}
+
+	return zones
+}
+
+// simulationAuctionRequest asks for the simulation's 25 cell "Large Cold
+// LRPStarts" mix: 1800 1MB, 200 2MB and 50 4MB instances, half of them of
+// unique apps and half spread over a few apps, in a fixed random order
+func simulationAuctionRequest() auctiontypes.AuctionRequest {
+	request := auctiontypes.AuctionRequest{}
+	now := time.Now()
+
+	add := func(prefix string, numInstances, memoryMB int, numApps int) {
+		for i := 0; i < numInstances; i++ {
+			processGuid := fmt.Sprintf("%s-%d", prefix, i)
+			index := 0
+			if numApps > 0 {
+				processGuid = fmt.Sprintf("%s-%d", prefix, i%numApps)
+				index = i / numApps
+			}
+			request.LRPs = append(request.LRPs, BuildLRPAuction(processGuid, index, lucidRootFSURL, memoryMB, 1, now))
+		}
+	}
+
+	add("unique-1", 900, 1, 0)
+	add("shared-1", 900, 1, 4)
+	add("unique-2", 100, 2, 0)
+	add("shared-2", 100, 2, 4)
+	add("unique-4", 25, 4, 0)
+	add("shared-4", 25, 4, 4)
+
+	r := rand.New(rand.NewSource(1))
+	permuted := make([]auctiontypes.LRPAuction, len(request.LRPs))
+	for i, index := range r.Perm(len(request.LRPs)) {
+		permuted[i] = request.LRPs[index]
+	}
+	request.LRPs = permuted
+
+	return request
+}
+
+type benchmarkCellRep struct{}
+
+func (benchmarkCellRep) State() (auctiontypes.CellState, error) {
+	return auctiontypes.CellState{}, nil
+}
+
+func (benchmarkCellRep) Perform(auctiontypes.Work) (auctiontypes.Work, error) {
+	return auctiontypes.Work{}, nil
+}
@@ -1,6 +1,9 @@
 package auctionrunner_test
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
 	"time"
 
 	"github.com/cloudfoundry/gunk/workpool"
@@ -223,6 +226,68 @@ var _ = Describe("Scheduler", func() {
 			})
 		})
 
+		Context("when the cell rejects the start auction as stale", func() {
+			BeforeEach(func() {
+				startAuction = BuildLRPAuction("pg-3", 1, lucidRootFSURL, 10, 10, clock.Now())
+
+				clients["A-cell"].PerformReturns(auctiontypes.Work{}, auctiontypes.ErrorStaleCellState)
+
+				clock.Increment(time.Minute)
+				s := auctionrunner.NewScheduler(workPool, zones, clock)
+				results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+			})
+
+			It("marks the start auction as failed because of the stale state", func() {
+				startAuction.Attempts = 1
+				startAuction.PlacementError = auctiontypes.ErrorStaleCellState.Error()
+				Expect(results.SuccessfulLRPs).To(BeEmpty())
+				Expect(results.FailedLRPs).To(ConsistOf(startAuction))
+			})
+		})
+
+		Context("when the cell errors while performing the start auction", func() {
+			BeforeEach(func() {
+				startAuction = BuildLRPAuction("pg-3", 1, lucidRootFSURL, 10, 10, clock.Now())
+
+				clients["A-cell"].PerformReturns(auctiontypes.Work{}, errors.New("boom"))
+			})
+
+			Context("and the cell reports that the LRP landed", func() {
+				BeforeEach(func() {
+					clients["A-cell"].StateReturns(auctiontypes.CellState{
+						LRPs: []auctiontypes.LRP{{"pg-3", 1, 10, 10}},
+					}, nil)
+
+					clock.Increment(time.Minute)
+					s := auctionrunner.NewScheduler(workPool, zones, clock)
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("marks the start auction as succeeded", func() {
+					setLRPWinner("A-cell", &startAuction)
+					startAuction.WaitDuration = time.Minute
+					Expect(results.SuccessfulLRPs).To(ConsistOf(startAuction))
+					Expect(results.FailedLRPs).To(BeEmpty())
+				})
+			})
+
+			Context("and the cell reports that the LRP did not land", func() {
+				BeforeEach(func() {
+					clients["A-cell"].StateReturns(auctiontypes.CellState{}, nil)
+
+					clock.Increment(time.Minute)
+					s := auctionrunner.NewScheduler(workPool, zones, clock)
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("marks the start auction as failed", func() {
+					startAuction.Attempts = 1
+					Expect(results.SuccessfulLRPs).To(BeEmpty())
+					Expect(results.FailedLRPs).To(ConsistOf(startAuction))
+				})
+			})
+		})
+
 		Context("when there is no room", func() {
 			BeforeEach(func() {
 				startAuction = BuildLRPAuctionWithPlacementError("pg-4", 0, lucidRootFSURL, 1000, 1000, clock.Now(), diego_errors.INSUFFICIENT_RESOURCES_MESSAGE)
@@ -242,6 +307,44 @@ var _ = Describe("Scheduler", func() {
 				Expect(results.FailedLRPs).To(ConsistOf(startAuction))
 			})
 		})
+
+		Context("when recording decisions", func() {
+			var s *auctionrunner.Scheduler
+
+			BeforeEach(func() {
+				s = auctionrunner.NewScheduler(workPool, zones, clock)
+				s.RecordDecisions()
+			})
+
+			It("records every cell scored and the winner", func() {
+				startAuction = BuildLRPAuction("pg-4", 1, lucidRootFSURL, 10, 10, clock.Now())
+				s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+
+				decision := s.LRPDecisions()[startAuction.Identifier()]
+				Expect(decision.Winner).To(Equal("B-cell"))
+				Expect(decision.Candidates).To(HaveLen(2))
+
+				scores := map[string]float64{}
+				for _, candidate := range decision.Candidates {
+					Expect(candidate.Error).To(BeEmpty())
+					scores[candidate.CellGuid] = candidate.Score
+				}
+				Expect(decision.Score).To(Equal(scores["B-cell"]))
+				Expect(scores["A-cell"]).To(BeNumerically(">", scores["B-cell"]))
+			})
+
+			It("records why cells could not take the work", func() {
+				startAuction = BuildLRPAuction("pg-4", 0, lucidRootFSURL, 1000, 1000, clock.Now())
+				s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+
+				decision := s.LRPDecisions()[startAuction.Identifier()]
+				Expect(decision.Winner).To(BeEmpty())
+				Expect(decision.Candidates).To(HaveLen(2))
+				for _, candidate := range decision.Candidates {
+					Expect(candidate.Error).To(Equal(diego_errors.INSUFFICIENT_RESOURCES_MESSAGE))
+				}
+			})
+		})
 	})
 
 	Describe("handling task auctions", func() {
@@ -401,6 +504,236 @@ var _ = Describe("Scheduler", func() {
 		})
 	})
 
+	Describe("handling work that is already running", func() {
+		var lrpAuction auctiontypes.LRPAuction
+		var taskAuction auctiontypes.TaskAuction
+
+		BeforeEach(func() {
+			clients["A-cell"] = &fakes.FakeSimulationCellRep{}
+			stateA := BuildCellState("A-zone", 100, 100, 100, false, lucidOnlyRootFSProviders, []auctiontypes.LRP{
+				{"pg-1", 0, 10, 10},
+			})
+			stateA.Tasks = []auctiontypes.Task{{TaskGuid: "tg-1", MemoryMB: 10, DiskMB: 10}}
+			zones["A-zone"] = auctionrunner.Zone{auctionrunner.NewCell("A-cell", clients["A-cell"], stateA)}
+
+			clients["B-cell"] = &fakes.FakeSimulationCellRep{}
+			zones["B-zone"] = auctionrunner.Zone{auctionrunner.NewCell("B-cell", clients["B-cell"], BuildCellState("B-zone", 100, 100, 100, false, lucidOnlyRootFSProviders, nil))}
+
+			lrpAuction = BuildLRPAuction("pg-1", 0, lucidRootFSURL, 10, 10, clock.Now())
+			taskAuction = BuildTaskAuction(BuildTask("tg-1", lucidRootFSURL, 10, 10), clock.Now())
+
+			s := auctionrunner.NewScheduler(workPool, zones, clock)
+			results = s.Schedule(auctiontypes.AuctionRequest{
+				LRPs:  []auctiontypes.LRPAuction{lrpAuction},
+				Tasks: []auctiontypes.TaskAuction{taskAuction},
+			})
+		})
+
+		It("does not start them again", func() {
+			Expect(clients["A-cell"].PerformCallCount()).To(Equal(0))
+			Expect(clients["B-cell"].PerformCallCount()).To(Equal(0))
+		})
+
+		It("reports them as already running on the cell that has them", func() {
+			lrpAuction.Winner = "A-cell"
+			taskAuction.Winner = "A-cell"
+
+			Expect(results.AlreadyRunningLRPs).To(ConsistOf(lrpAuction))
+			Expect(results.AlreadyRunningTasks).To(ConsistOf(taskAuction))
+		})
+
+		It("reports them as neither successful nor failed", func() {
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+			Expect(results.FailedLRPs).To(BeEmpty())
+			Expect(results.SuccessfulTasks).To(BeEmpty())
+			Expect(results.FailedTasks).To(BeEmpty())
+		})
+
+		Context("when other indices of the same process are requested", func() {
+			BeforeEach(func() {
+				lrpAuction = BuildLRPAuction("pg-1", 1, lucidRootFSURL, 10, 10, clock.Now())
+
+				s := auctionrunner.NewScheduler(workPool, zones, clock)
+				results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{lrpAuction}})
+			})
+
+			It("schedules them as usual", func() {
+				setLRPWinner("B-cell", &lrpAuction)
+				Expect(results.SuccessfulLRPs).To(ConsistOf(lrpAuction))
+				Expect(results.AlreadyRunningLRPs).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("deterministic scheduling", func() {
+		var identicalCells func(guids ...string) map[string]auctionrunner.Zone
+
+		BeforeEach(func() {
+			identicalCells = func(guids ...string) map[string]auctionrunner.Zone {
+				zones := map[string]auctionrunner.Zone{}
+				for i, guid := range guids {
+					zone := []string{"A-zone", "B-zone"}[i%2]
+					zones[zone] = append(zones[zone], auctionrunner.NewCell(
+						guid,
+						&fakes.FakeSimulationCellRep{},
+						BuildCellState(zone, 100, 100, 100, false, lucidOnlyRootFSProviders, nil),
+					))
+				}
+				return zones
+			}
+		})
+
+		It("settles ties by cell guid, whatever order the cells were fetched in", func() {
+			orders := [][]string{
+				{"cell-d", "cell-c", "cell-b", "cell-a"},
+				{"cell-a", "cell-b", "cell-c", "cell-d"},
+				{"cell-c", "cell-a", "cell-d", "cell-b"},
+			}
+
+			for _, order := range orders {
+				s := auctionrunner.NewScheduler(workPool, identicalCells(order...), clock)
+				s.Deterministic(nil)
+				results := s.Schedule(auctiontypes.AuctionRequest{
+					LRPs:  []auctiontypes.LRPAuction{BuildLRPAuction("pg-1", 0, lucidRootFSURL, 10, 10, clock.Now())},
+					Tasks: []auctiontypes.TaskAuction{BuildTaskAuction(BuildTask("tg-1", lucidRootFSURL, 10, 10), clock.Now())},
+				})
+
+				Expect(results.SuccessfulLRPs).To(HaveLen(1))
+				Expect(results.SuccessfulLRPs[0].Winner).To(Equal("cell-b"), "fetched in order %v", order)
+				Expect(results.SuccessfulTasks).To(HaveLen(1))
+				Expect(results.SuccessfulTasks[0].Winner).To(Equal("cell-a"), "fetched in order %v", order)
+			}
+		})
+
+		It("settles ties with the given policy", func() {
+			s := auctionrunner.NewScheduler(workPool, identicalCells("cell-a", "cell-b", "cell-c"), clock)
+			s.Deterministic(func(candidate, current *auctionrunner.Cell) bool {
+				return candidate.Guid > current.Guid
+			})
+			results := s.Schedule(auctiontypes.AuctionRequest{
+				Tasks: []auctiontypes.TaskAuction{BuildTaskAuction(BuildTask("tg-1", lucidRootFSURL, 10, 10), clock.Now())},
+			})
+
+			Expect(results.SuccessfulTasks).To(HaveLen(1))
+			Expect(results.SuccessfulTasks[0].Winner).To(Equal("cell-c"))
+		})
+
+		It("does not reorder the zones it was given", func() {
+			zones := identicalCells("cell-c", "cell-a")
+			zones["A-zone"] = append(zones["A-zone"], zones["B-zone"]...)
+			delete(zones, "B-zone")
+
+			auctionrunner.NewScheduler(workPool, zones, clock).Deterministic(nil)
+			Expect(zones["A-zone"][0].Guid).To(Equal("cell-c"))
+		})
+	})
+
+	Describe("indexing cells", func() {
+		randomZones := func(seed int64, numCells int) map[string]auctionrunner.Zone {
+			r := rand.New(rand.NewSource(seed))
+			zones := map[string]auctionrunner.Zone{}
+
+			for i := 0; i < numCells; i++ {
+				zone := fmt.Sprintf("zone-%d", r.Intn(3))
+				providers := lucidOnlyRootFSProviders
+				if i%5 == 0 {
+					providers = windowsOnlyRootFSProviders
+				}
+
+				size := []int{100, 200}[r.Intn(2)]
+				lrps := []auctiontypes.LRP{}
+				for j := r.Intn(4); j > 0; j-- {
+					lrps = append(lrps, auctiontypes.LRP{ProcessGuid: fmt.Sprintf("pg-%d", r.Intn(5)), Index: 100 + i*10 + j, MemoryMB: 10, DiskMB: 10})
+				}
+
+				guid := fmt.Sprintf("cell-%04d", i)
+				state := BuildCellState(zone, size, size, size/10, false, providers, lrps)
+				if i%7 == 0 {
+					zones[zone] = append(zones[zone], auctionrunner.NewStaleCell(guid, &fakes.FakeSimulationCellRep{}, state))
+				} else {
+					zones[zone] = append(zones[zone], auctionrunner.NewCell(guid, &fakes.FakeSimulationCellRep{}, state))
+				}
+			}
+
+			return zones
+		}
+
+		randomRequest := func(seed int64) auctiontypes.AuctionRequest {
+			r := rand.New(rand.NewSource(seed))
+			request := auctiontypes.AuctionRequest{}
+
+			for i := 0; i < 300; i++ {
+				rootFS := lucidRootFSURL
+				if i%9 == 0 {
+					rootFS = windowsRootFSURL
+				}
+				size := 5 + 5*r.Intn(3)
+
+				if i%4 == 0 {
+					request.Tasks = append(request.Tasks, BuildTaskAuction(BuildTask(fmt.Sprintf("tg-%d", i), rootFS, size, size), clock.Now()))
+				} else {
+					request.LRPs = append(request.LRPs, BuildLRPAuction(fmt.Sprintf("pg-%d", r.Intn(5)), i, rootFS, size, size, clock.Now()))
+				}
+			}
+
+			return request
+		}
+
+		winners := func(results auctiontypes.AuctionResults) map[string]string {
+			winners := map[string]string{}
+			for _, lrp := range results.SuccessfulLRPs {
+				winners[lrp.Identifier()] = lrp.Winner
+			}
+			for _, task := range results.SuccessfulTasks {
+				winners[task.Identifier()] = task.Winner
+			}
+			for _, lrp := range results.FailedLRPs {
+				winners[lrp.Identifier()] = lrp.PlacementError
+			}
+			for _, task := range results.FailedTasks {
+				winners[task.Identifier()] = task.PlacementError
+			}
+			return winners
+		}
+
+		It("places work exactly as scoring every cell does", func() {
+			for seed := int64(1); seed <= 10; seed++ {
+				exhaustive := auctionrunner.NewScheduler(workPool, randomZones(seed, 60), clock)
+				exhaustive.Deterministic(nil)
+				exhaustive.RecordDecisions()
+				expected := winners(exhaustive.Schedule(randomRequest(seed)))
+
+				indexed := auctionrunner.NewScheduler(workPool, randomZones(seed, 60), clock)
+				indexed.Deterministic(nil)
+				Expect(winners(indexed.Schedule(randomRequest(seed)))).To(Equal(expected), "seed %d", seed)
+			}
+		})
+
+		Context("when scoring in parallel", func() {
+			It("places work and records decisions exactly as scoring sequentially does", func() {
+				for seed := int64(1); seed <= 3; seed++ {
+					sequential := auctionrunner.NewScheduler(workPool, randomZones(seed, 1000), clock)
+					sequential.Deterministic(nil)
+					sequential.RecordDecisions()
+					expected := winners(sequential.Schedule(randomRequest(seed)))
+
+					parallel := auctionrunner.NewScheduler(workPool, randomZones(seed, 1000), clock)
+					parallel.Deterministic(nil)
+					parallel.RecordDecisions()
+					parallel.ScoreInParallel(4)
+					Expect(winners(parallel.Schedule(randomRequest(seed)))).To(Equal(expected), "seed %d", seed)
+					Expect(parallel.LRPDecisions()).To(Equal(sequential.LRPDecisions()), "seed %d", seed)
+					Expect(parallel.TaskDecisions()).To(Equal(sequential.TaskDecisions()), "seed %d", seed)
+
+					indexed := auctionrunner.NewScheduler(workPool, randomZones(seed, 1000), clock)
+					indexed.Deterministic(nil)
+					indexed.ScoreInParallel(4)
+					Expect(winners(indexed.Schedule(randomRequest(seed)))).To(Equal(expected), "seed %d", seed)
+				}
+			})
+		})
+	})
+
 	Describe("a comprehensive scenario", func() {
 		BeforeEach(func() {
 			clients["A-cell"] = &fakes.FakeSimulationCellRep{}
@@ -0,0 +1,81 @@
+package auctionrunner
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+/*
+ShardRing deterministically assigns cells and auctions to one of a fixed number
+of shards using rendezvous hashing.  Every cell guid hashes independently, so
+each shard owns a roughly even share of the cells in every zone, and changing
+the number of shards only moves the cells that have to move.
+
+LRPs are assigned by process guid, so all of the instances of a process are
+auctioned by the same shard and zone balancing sees all of them.
+*/
+type ShardRing struct {
+	shardCount int
+}
+
+func NewShardRing(shardCount int) ShardRing {
+	if shardCount < 1 {
+		shardCount = 1
+	}
+	return ShardRing{shardCount: shardCount}
+}
+
+func (r ShardRing) ShardCount() int {
+	return r.shardCount
+}
+
+func (r ShardRing) ShardForCell(cellGuid string) int {
+	return r.shardFor("cell", cellGuid)
+}
+
+func (r ShardRing) ShardForLRP(processGuid string) int {
+	return r.shardFor("lrp", processGuid)
+}
+
+func (r ShardRing) ShardForTask(taskGuid string) int {
+	return r.shardFor("task", taskGuid)
+}
+
+func (r ShardRing) shardFor(kind, key string) int {
+	winner := 0
+	var winnerWeight uint64
+
+	for shard := 0; shard < r.shardCount; shard++ {
+		weight := rendezvousWeight(kind, key, shard)
+		if shard == 0 || weight > winnerWeight {
+			winner = shard
+			winnerWeight = weight
+		}
+	}
+
+	return winner
+}
+
+func rendezvousWeight(kind, key string, shard int) uint64 {
+	shardBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(shardBytes, uint64(shard))
+
+	hash := fnv.New64a()
+	hash.Write([]byte(kind))
+	hash.Write([]byte{0})
+	hash.Write([]byte(key))
+	hash.Write([]byte{0})
+	hash.Write(shardBytes)
+	return mix64(hash.Sum64())
+}
+
+// mix64 is the splitmix64 finalizer; FNV alone weighs shards unevenly
+// for keys that differ only in their last few bytes
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
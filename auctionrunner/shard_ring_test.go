@@ -0,0 +1,57 @@
+package auctionrunner_test
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ShardRing", func() {
+	var ring auctionrunner.ShardRing
+
+	BeforeEach(func() {
+		ring = auctionrunner.NewShardRing(4)
+	})
+
+	It("assigns the same cell to the same shard every time", func() {
+		Expect(ring.ShardForCell("cell-a")).To(Equal(auctionrunner.NewShardRing(4).ShardForCell("cell-a")))
+		Expect(ring.ShardForLRP("pg-a")).To(Equal(auctionrunner.NewShardRing(4).ShardForLRP("pg-a")))
+		Expect(ring.ShardForTask("tg-a")).To(Equal(auctionrunner.NewShardRing(4).ShardForTask("tg-a")))
+	})
+
+	It("spreads cells across all of the shards", func() {
+		counts := map[int]int{}
+		for i := 0; i < 1000; i++ {
+			shard := ring.ShardForCell(fmt.Sprintf("cell-%d", i))
+			Expect(shard).To(BeNumerically(">=", 0))
+			Expect(shard).To(BeNumerically("<", 4))
+			counts[shard]++
+		}
+
+		Expect(counts).To(HaveLen(4))
+		for _, count := range counts {
+			Expect(count).To(BeNumerically("~", 250, 60))
+		}
+	})
+
+	It("only moves the cells of a removed shard when shrinking", func() {
+		smallerRing := auctionrunner.NewShardRing(3)
+		for i := 0; i < 1000; i++ {
+			guid := fmt.Sprintf("cell-%d", i)
+			if shard := ring.ShardForCell(guid); shard < 3 {
+				Expect(smallerRing.ShardForCell(guid)).To(Equal(shard))
+			}
+		}
+	})
+
+	Context("with fewer than one shard", func() {
+		It("puts everything on a single shard", func() {
+			ring = auctionrunner.NewShardRing(0)
+			Expect(ring.ShardCount()).To(Equal(1))
+			Expect(ring.ShardForCell("cell-a")).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,231 @@
+package auctionrunner
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/ifrit/grouper"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+)
+
+type ShardConfig struct {
+	Config
+	ShardCount int
+
+	// Overflow hands auctions that failed for lack of room or a compatible
+	// cell in their owner shard to the next shard, until every shard has
+	// been tried.  Auctions are only reported, to the delegate, the metric
+	// emitter and the audit sink, once they are settled.
+	Overflow bool
+}
+
+type shardedRunner struct {
+	ring   ShardRing
+	shards []*auctionRunner
+	logger lager.Logger
+
+	lrpHops  map[string]int
+	taskHops map[string]int
+	lock     *sync.Mutex
+}
+
+/*
+NewSharded returns an AuctionRunner that runs several auction runners side by
+side.  Each shard fetches state from, and schedules onto, only the cells that
+the ShardRing assigns to it, so the shards fetch and score in parallel.
+
+Auctions are routed to shards with the same ShardRing.  Auctioneers running in
+separate processes can shard the same way by running New with a
+NewShardDelegate and routing auctions with ShardForLRP and ShardForTask.
+*/
+func NewSharded(
+	delegate auctiontypes.AuctionRunnerDelegate,
+	metricEmitter auctiontypes.AuctionMetricEmitterDelegate,
+	clock clock.Clock,
+	workPool *workpool.WorkPool,
+	logger lager.Logger,
+	config ShardConfig,
+) auctiontypes.AuctionRunner {
+	ring := NewShardRing(config.ShardCount)
+
+	runner := &shardedRunner{
+		ring:     ring,
+		logger:   logger,
+		lrpHops:  map[string]int{},
+		taskHops: map[string]int{},
+		lock:     &sync.Mutex{},
+	}
+
+	for shard := 0; shard < ring.ShardCount(); shard++ {
+		shard := shard
+		shardLogger := logger.Session("shard", lager.Data{"shard": shard})
+		shardRunner := NewWithConfig(NewShardDelegate(delegate, ring, shard), metricEmitter, clock, workPool, shardLogger, config.Config)
+		if config.Overflow {
+			shardRunner.settle = func(results auctiontypes.AuctionResults) auctiontypes.AuctionResults {
+				return runner.overflowToNextShard(shard, results)
+			}
+		}
+		runner.shards = append(runner.shards, shardRunner)
+	}
+
+	return runner
+}
+
+func (r *shardedRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	members := grouper.Members{}
+	for shard, runner := range r.shards {
+		members = append(members, grouper.Member{Name: fmt.Sprintf("shard-%d", shard), Runner: runner})
+	}
+
+	return grouper.NewParallel(os.Interrupt, members).Run(signals, ready)
+}
+
+func (r *shardedRunner) ScheduleLRPsForAuctions(lrpStarts []models.LRPStartRequest) {
+	byShard := map[int][]models.LRPStartRequest{}
+	for _, lrpStart := range lrpStarts {
+		shard := r.ring.ShardForLRP(lrpStart.DesiredLRP.ProcessGuid)
+		byShard[shard] = append(byShard[shard], lrpStart)
+	}
+
+	for shard, starts := range byShard {
+		r.shards[shard].ScheduleLRPsForAuctions(starts)
+	}
+}
+
+func (r *shardedRunner) ScheduleTasksForAuctions(tasks []models.Task) {
+	byShard := map[int][]models.Task{}
+	for _, task := range tasks {
+		shard := r.ring.ShardForTask(task.TaskGuid)
+		byShard[shard] = append(byShard[shard], task)
+	}
+
+	for shard, tasks := range byShard {
+		r.shards[shard].ScheduleTasksForAuctions(tasks)
+	}
+}
+
+func (r *shardedRunner) QueueStatus() auctiontypes.QueueStatus {
+	status := auctiontypes.QueueStatus{}
+	for _, shard := range r.shards {
+		shardStatus := shard.QueueStatus()
+		status.LRPAuctions += shardStatus.LRPAuctions
+		status.TaskAuctions += shardStatus.TaskAuctions
+	}
+	return status
+}
+
+func (r *shardedRunner) overflowToNextShard(shard int, results auctiontypes.AuctionResults) auctiontypes.AuctionResults {
+	nextShard := (shard + 1) % len(r.shards)
+
+	r.lock.Lock()
+
+	overflowLRPs := []auctiontypes.LRPAuction{}
+	failedLRPs := []auctiontypes.LRPAuction{}
+	for _, lrpAuction := range results.FailedLRPs {
+		identifier := lrpAuction.Identifier()
+		if !canOverflow(lrpAuction.AuctionRecord) || r.lrpHops[identifier] >= len(r.shards)-1 {
+			delete(r.lrpHops, identifier)
+			failedLRPs = append(failedLRPs, lrpAuction)
+			continue
+		}
+		r.lrpHops[identifier]++
+		lrpAuction.PlacementError = ""
+		overflowLRPs = append(overflowLRPs, lrpAuction)
+	}
+
+	overflowTasks := []auctiontypes.TaskAuction{}
+	failedTasks := []auctiontypes.TaskAuction{}
+	for _, taskAuction := range results.FailedTasks {
+		identifier := taskAuction.Identifier()
+		if !canOverflow(taskAuction.AuctionRecord) || r.taskHops[identifier] >= len(r.shards)-1 {
+			delete(r.taskHops, identifier)
+			failedTasks = append(failedTasks, taskAuction)
+			continue
+		}
+		r.taskHops[identifier]++
+		taskAuction.PlacementError = ""
+		overflowTasks = append(overflowTasks, taskAuction)
+	}
+
+	for _, lrpAuction := range results.SuccessfulLRPs {
+		delete(r.lrpHops, lrpAuction.Identifier())
+	}
+	for _, lrpAuction := range results.AlreadyRunningLRPs {
+		delete(r.lrpHops, lrpAuction.Identifier())
+	}
+	for _, taskAuction := range results.SuccessfulTasks {
+		delete(r.taskHops, taskAuction.Identifier())
+	}
+	for _, taskAuction := range results.AlreadyRunningTasks {
+		delete(r.taskHops, taskAuction.Identifier())
+	}
+
+	r.lock.Unlock()
+
+	if len(overflowLRPs) > 0 || len(overflowTasks) > 0 {
+		r.logger.Info("overflowing-to-next-shard", lager.Data{
+			"shard":              shard,
+			"next-shard":         nextShard,
+			"lrp-start-auctions": len(overflowLRPs),
+			"task-auctions":      len(overflowTasks),
+		})
+	}
+
+	if len(overflowLRPs) > 0 {
+		r.shards[nextShard].batch.AddLRPAuctions(overflowLRPs)
+	}
+	if len(overflowTasks) > 0 {
+		r.shards[nextShard].batch.AddTaskAuctions(overflowTasks)
+	}
+
+	results.FailedLRPs = failedLRPs
+	results.FailedTasks = failedTasks
+	return results
+}
+
+func canOverflow(record auctiontypes.AuctionRecord) bool {
+	return record.PlacementError == auctiontypes.ErrorInsufficientResources.Error() ||
+		record.PlacementError == auctiontypes.ErrorCellMismatch.Error()
+}
+
+type shardDelegate struct {
+	delegate auctiontypes.AuctionRunnerDelegate
+	ring     ShardRing
+	shard    int
+}
+
+// NewShardDelegate restricts the cells fetched by delegate to those the ring
+// assigns to shard.
+func NewShardDelegate(delegate auctiontypes.AuctionRunnerDelegate, ring ShardRing, shard int) auctiontypes.AuctionRunnerDelegate {
+	return &shardDelegate{
+		delegate: delegate,
+		ring:     ring,
+		shard:    shard,
+	}
+}
+
+func (d *shardDelegate) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	cellReps, err := d.delegate.FetchCellReps()
+	if err != nil {
+		return nil, err
+	}
+
+	owned := map[string]auctiontypes.CellRep{}
+	for guid, cellRep := range cellReps {
+		if d.ring.ShardForCell(guid) == d.shard {
+			owned[guid] = cellRep
+		}
+	}
+
+	return owned, nil
+}
+
+func (d *shardDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	d.delegate.AuctionCompleted(results)
+}
@@ -0,0 +1,183 @@
+package auctionrunner_test
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ShardedRunner", func() {
+	var ring auctionrunner.ShardRing
+	var cellReps map[string]*fakes.FakeSimulationCellRep
+	var shardOfCell map[string]int
+	var delegate *fakeRunnerDelegate
+	var metricEmitter *resultsRecordingEmitter
+	var workPool *workpool.WorkPool
+	var config auctionrunner.ShardConfig
+	var runner auctiontypes.AuctionRunner
+	var process ifrit.Process
+
+	BeforeEach(func() {
+		ring = auctionrunner.NewShardRing(2)
+		cellReps = map[string]*fakes.FakeSimulationCellRep{}
+		shardOfCell = map[string]int{}
+
+		clients := map[string]auctiontypes.CellRep{}
+		for i := 0; len(clients) < 4; i++ {
+			guid := fmt.Sprintf("cell-%d", i)
+			shard := ring.ShardForCell(guid)
+			if countShard(shardOfCell, shard) == 2 {
+				continue
+			}
+
+			cellRep := &fakes.FakeSimulationCellRep{}
+			cellRep.StateReturns(BuildCellState("the-zone", 100, 100, 100, false, lucidOnlyRootFSProviders, nil), nil)
+			cellReps[guid] = cellRep
+			shardOfCell[guid] = shard
+			clients[guid] = cellRep
+		}
+
+		delegate = newFakeRunnerDelegate(clients)
+		metricEmitter = &resultsRecordingEmitter{lock: &sync.Mutex{}}
+		workPool = workpool.NewWorkPool(5)
+		config = auctionrunner.ShardConfig{ShardCount: 2}
+	})
+
+	JustBeforeEach(func() {
+		runner = auctionrunner.NewSharded(delegate, metricEmitter, clock.NewClock(), workPool, lagertest.NewTestLogger("test"), config)
+		process = ifrit.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+		workPool.Stop()
+	})
+
+	It("places each process only on cells owned by the process's shard", func() {
+		starts := []models.LRPStartRequest{}
+		for i := 0; i < 10; i++ {
+			starts = append(starts, BuildLRPStartRequest(fmt.Sprintf("pg-%d", i), []uint{0, 1}, lucidRootFSURL, 10, 10))
+		}
+		runner.ScheduleLRPsForAuctions(starts)
+
+		Eventually(delegate.ResultSize).Should(Equal(20))
+		results := delegate.Results()
+		Expect(results.SuccessfulLRPs).To(HaveLen(20))
+
+		for _, lrpAuction := range results.SuccessfulLRPs {
+			Expect(shardOfCell[lrpAuction.Winner]).To(Equal(ring.ShardForLRP(lrpAuction.DesiredLRP.ProcessGuid)))
+		}
+	})
+
+	It("only fetches state from each cell once per auction", func() {
+		runner.ScheduleTasksForAuctions([]models.Task{BuildTask("tg-1", lucidRootFSURL, 10, 10)})
+
+		Eventually(delegate.ResultSize).Should(Equal(1))
+		for guid, cellRep := range cellReps {
+			if shardOfCell[guid] == ring.ShardForTask("tg-1") {
+				Expect(cellRep.StateCallCount()).To(Equal(1))
+			} else {
+				Expect(cellRep.StateCallCount()).To(Equal(0))
+			}
+		}
+	})
+
+	Context("when the owner shard has no room", func() {
+		var processGuid string
+
+		BeforeEach(func() {
+			processGuid = "pg-big"
+			ownerShard := ring.ShardForLRP(processGuid)
+			for guid, cellRep := range cellReps {
+				if shardOfCell[guid] == ownerShard {
+					cellRep.StateReturns(BuildCellState("the-zone", 10, 100, 100, false, lucidOnlyRootFSProviders, nil), nil)
+				}
+			}
+		})
+
+		It("fails the auction", func() {
+			runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest(processGuid, []uint{0}, lucidRootFSURL, 50, 10)})
+
+			Eventually(delegate.ResultSize).Should(Equal(1))
+			Expect(delegate.Results().FailedLRPs).To(HaveLen(1))
+		})
+
+		Context("with overflow enabled", func() {
+			BeforeEach(func() {
+				config.Overflow = true
+			})
+
+			It("places the auction on another shard", func() {
+				runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest(processGuid, []uint{0}, lucidRootFSURL, 50, 10)})
+
+				Eventually(delegate.ResultSize).Should(Equal(1))
+				results := delegate.Results()
+				Expect(results.FailedLRPs).To(BeEmpty())
+				Expect(results.SuccessfulLRPs).To(HaveLen(1))
+
+				successfulLRP := results.SuccessfulLRPs[0]
+				Expect(shardOfCell[successfulLRP.Winner]).NotTo(Equal(ring.ShardForLRP(processGuid)))
+				Expect(successfulLRP.Attempts).To(Equal(2))
+			})
+
+			Context("with an audit sink", func() {
+				var sink *recordingAuditSink
+
+				BeforeEach(func() {
+					sink = &recordingAuditSink{lock: &sync.Mutex{}}
+					config.AuditSink = sink
+				})
+
+				It("reports the auction once it is settled, and only once", func() {
+					runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest(processGuid, []uint{0}, lucidRootFSURL, 50, 10)})
+
+					Eventually(delegate.ResultSize).Should(Equal(1))
+					Consistently(delegate.ResultSize).Should(Equal(1))
+
+					emitted := metricEmitter.Results()
+					Expect(emitted.FailedLRPs).To(BeEmpty())
+					Expect(emitted.SuccessfulLRPs).To(HaveLen(1))
+					Expect(emitted.SuccessfulLRPs[0].Attempts).To(Equal(2))
+
+					records := sink.Records()
+					Expect(records).To(HaveLen(1))
+					Expect(records[0].Outcome).To(Equal(auctiontypes.AuctionSucceeded))
+				})
+			})
+
+			It("fails the auction once every shard has been tried", func() {
+				runner.ScheduleLRPsForAuctions([]models.LRPStartRequest{BuildLRPStartRequest(processGuid, []uint{0}, lucidRootFSURL, 500, 10)})
+
+				Eventually(delegate.ResultSize).Should(Equal(1))
+				Consistently(delegate.ResultSize).Should(Equal(1))
+				results := delegate.Results()
+				Expect(results.FailedLRPs).To(HaveLen(1))
+				Expect(results.FailedLRPs[0].Attempts).To(Equal(2))
+			})
+		})
+	})
+})
+
+func countShard(shardOfCell map[string]int, shard int) int {
+	count := 0
+	for _, s := range shardOfCell {
+		if s == shard {
+			count++
+		}
+	}
+	return count
+}
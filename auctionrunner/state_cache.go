@@ -0,0 +1,56 @@
+package auctionrunner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+type cachedState struct {
+	state     auctiontypes.CellState
+	fetchedAt time.Time
+}
+
+// StateCache remembers the state each cell last reported, for up to ttl.
+type StateCache struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	states map[string]cachedState
+	lock   *sync.Mutex
+}
+
+func NewStateCache(ttl time.Duration, clock clock.Clock) *StateCache {
+	return &StateCache{
+		ttl:    ttl,
+		clock:  clock,
+		states: map[string]cachedState{},
+		lock:   &sync.Mutex{},
+	}
+}
+
+func (c *StateCache) Put(guid string, state auctiontypes.CellState) {
+	c.lock.Lock()
+	c.states[guid] = cachedState{state: state, fetchedAt: c.clock.Now()}
+	c.lock.Unlock()
+}
+
+func (c *StateCache) Get(guid string) (auctiontypes.CellState, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cached, ok := c.states[guid]
+	if !ok {
+		return auctiontypes.CellState{}, false
+	}
+
+	if c.clock.Now().Sub(cached.fetchedAt) > c.ttl {
+		delete(c.states, guid)
+		return auctiontypes.CellState{}, false
+	}
+
+	return cached.state, true
+}
@@ -1,24 +1,68 @@
 package auctionrunner
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
 	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 )
 
-func FetchStateAndBuildZones(logger lager.Logger, workPool *workpool.WorkPool, clients map[string]auctiontypes.CellRep) map[string]Zone {
+type StateFetchOptions struct {
+	// HedgeAfter sends a second State request to cells that have not answered
+	// within it, and uses whichever answers first.  Zero disables hedging.
+	HedgeAfter time.Duration
+
+	// SoftDeadline stops waiting for cells that have not answered within it
+	// and builds them from the state they last reported to Cache instead.
+	// Those cells are stale.  Zero, or a nil Cache, disables the fallback.
+	SoftDeadline time.Duration
+
+	// Cache is updated with every state fetched.
+	Cache *StateCache
+}
+
+// FetchStateAndBuildZones asks every client for its state and groups the cells
+// that answered by zone.  Once ctx is done it stops waiting and builds the zones
+// from the cells that answered in time.
+func FetchStateAndBuildZones(ctx context.Context, logger lager.Logger, workPool *workpool.WorkPool, clients map[string]auctiontypes.CellRep) map[string]Zone {
+	return FetchStateAndBuildZonesWithOptions(ctx, logger, workPool, clock.NewClock(), clients, StateFetchOptions{})
+}
+
+func FetchStateAndBuildZonesWithOptions(
+	ctx context.Context,
+	logger lager.Logger,
+	workPool *workpool.WorkPool,
+	clock clock.Clock,
+	clients map[string]auctiontypes.CellRep,
+	options StateFetchOptions,
+) map[string]Zone {
 	wg := &sync.WaitGroup{}
 	zones := map[string]Zone{}
+	finished := map[string]bool{}
 	lock := &sync.Mutex{}
+	closed := false
 
 	wg.Add(len(clients))
 	for guid, client := range clients {
 		guid, client := guid, client
 		workPool.Submit(func() {
 			defer wg.Done()
-			state, err := client.State()
+			state, err := fetchState(ctx, logger, clock, guid, client, options.HedgeAfter)
+			if err == nil && options.Cache != nil {
+				options.Cache.Put(guid, state)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if closed {
+				return
+			}
+			finished[guid] = true
+
 			if err != nil {
 				logger.Error("failed-to-get-state", err, lager.Data{"cell-guid": guid})
 				return
@@ -28,14 +72,122 @@ func FetchStateAndBuildZones(logger lager.Logger, workPool *workpool.WorkPool, c
 				return
 			}
 
-			cell := NewCell(guid, client, state)
-			lock.Lock()
-			zones[state.Zone] = append(zones[state.Zone], cell)
-			lock.Unlock()
+			zones[state.Zone] = append(zones[state.Zone], NewCell(guid, client, state))
 		})
 	}
 
-	wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var softDeadline <-chan time.Time
+	if options.SoftDeadline > 0 && options.Cache != nil {
+		timer := clock.NewTimer(options.SoftDeadline)
+		defer timer.Stop()
+		softDeadline = timer.C()
+	}
+
+	useCache := false
+	select {
+	case <-done:
+	case <-softDeadline:
+		useCache = true
+	case <-ctx.Done():
+		logger.Info("state-fetch-deadline-exceeded", lager.Data{"error": ctx.Err().Error()})
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	closed = true
+
+	if useCache {
+		staleCount := 0
+		for guid, client := range clients {
+			if finished[guid] {
+				continue
+			}
+
+			state, ok := options.Cache.Get(guid)
+			if !ok || state.Evacuating {
+				continue
+			}
+
+			zones[state.Zone] = append(zones[state.Zone], NewStaleCell(guid, client, state))
+			staleCount++
+		}
+
+		logger.Info("state-fetch-soft-deadline-exceeded", lager.Data{
+			"unanswered-cell-count": len(clients) - len(finished),
+			"stale-cell-count":      staleCount,
+		})
+	}
 
 	return zones
 }
+
+// fetchState asks client for its state, and asks again if it hasn't answered
+// within hedgeAfter.  The first answer wins and the other request is canceled.
+func fetchState(ctx context.Context, logger lager.Logger, clock clock.Clock, guid string, client auctiontypes.CellRep, hedgeAfter time.Duration) (auctiontypes.CellState, error) {
+	if hedgeAfter <= 0 {
+		return auctiontypes.StateWithContext(ctx, client)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		state auctiontypes.CellState
+		err   error
+	}
+
+	results := make(chan result, 2)
+	request := func() {
+		state, err := auctiontypes.StateWithContext(ctx, client)
+		results <- result{state, err}
+	}
+
+	go request()
+	inFlight := 1
+
+	timer := clock.NewTimer(hedgeAfter)
+	defer timer.Stop()
+	hedge := timer.C()
+
+	var err error
+	for inFlight > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			logger.Info("hedging-state-request", lager.Data{"cell-guid": guid})
+			go request()
+			inFlight++
+
+		case r := <-results:
+			inFlight--
+			if r.err == nil {
+				return r.state, nil
+			}
+			err = r.err
+			if hedge != nil {
+				// don't wait out the hedge timer when the first request fails fast
+				return auctiontypes.CellState{}, err
+			}
+		}
+	}
+
+	return auctiontypes.CellState{}, err
+}
+
+func zoneCapacities(zones map[string]Zone) map[string]auctiontypes.ZoneCapacity {
+	capacities := map[string]auctiontypes.ZoneCapacity{}
+	for name, zone := range zones {
+		capacity := auctiontypes.ZoneCapacity{}
+		for _, cell := range zone {
+			capacity.Add(cell.state)
+		}
+		capacities[name] = capacity
+	}
+	return capacities
+}
@@ -1,12 +1,16 @@
 package auctionrunner_test
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
+	"time"
 
 	"github.com/cloudfoundry-incubator/auction/auctionrunner"
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
 	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
 	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotal-golang/lager/lagertest"
 
@@ -43,7 +47,7 @@ var _ = Describe("ZoneBuilder", func() {
 	})
 
 	It("fetches state by calling each client", func() {
-		zones := auctionrunner.FetchStateAndBuildZones(logger, workPool, clients)
+		zones := auctionrunner.FetchStateAndBuildZones(context.Background(), logger, workPool, clients)
 		Expect(zones).To(HaveLen(2))
 
 		cells := map[string]*auctionrunner.Cell{}
@@ -70,7 +74,7 @@ var _ = Describe("ZoneBuilder", func() {
 		})
 
 		It("does not include them in the map", func() {
-			zones := auctionrunner.FetchStateAndBuildZones(logger, workPool, clients)
+			zones := auctionrunner.FetchStateAndBuildZones(context.Background(), logger, workPool, clients)
 			Expect(zones).To(HaveLen(2))
 
 			cells := zones["the-zone"]
@@ -89,7 +93,7 @@ var _ = Describe("ZoneBuilder", func() {
 		})
 
 		It("does not include the client in the map", func() {
-			zones := auctionrunner.FetchStateAndBuildZones(logger, workPool, clients)
+			zones := auctionrunner.FetchStateAndBuildZones(context.Background(), logger, workPool, clients)
 			Expect(zones).To(HaveLen(2))
 
 			cells := zones["the-zone"]
@@ -101,4 +105,123 @@ var _ = Describe("ZoneBuilder", func() {
 			Expect(cells[0].Guid).To(Equal("C"))
 		})
 	})
+
+	Context("when a client does not answer before the deadline", func() {
+		var blockForever chan struct{}
+
+		BeforeEach(func() {
+			blockForever = make(chan struct{})
+			repB.StateStub = func() (auctiontypes.CellState, error) {
+				<-blockForever
+				return BuildCellState("the-zone", 10, 10, 100, false, lucidOnlyRootFSProviders, nil), nil
+			}
+		})
+
+		AfterEach(func() {
+			close(blockForever)
+		})
+
+		It("builds the zones from the cells that answered in time", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			zones := auctionrunner.FetchStateAndBuildZones(ctx, logger, workPool, clients)
+			Expect(zones).To(HaveLen(2))
+
+			cells := zones["the-zone"]
+			Expect(cells).To(HaveLen(1))
+			Expect(cells[0].Guid).To(Equal("A"))
+
+			cells = zones["other-zone"]
+			Expect(cells).To(HaveLen(1))
+			Expect(cells[0].Guid).To(Equal("C"))
+		})
+	})
+
+	Describe("with options", func() {
+		var blockForever chan struct{}
+		var options auctionrunner.StateFetchOptions
+
+		BeforeEach(func() {
+			blockForever = make(chan struct{})
+			options = auctionrunner.StateFetchOptions{}
+		})
+
+		AfterEach(func() {
+			close(blockForever)
+		})
+
+		fetch := func() map[string]*auctionrunner.Cell {
+			zones := auctionrunner.FetchStateAndBuildZonesWithOptions(context.Background(), logger, workPool, clock.NewClock(), clients, options)
+
+			cells := map[string]*auctionrunner.Cell{}
+			for _, zone := range zones {
+				for _, cell := range zone {
+					cells[cell.Guid] = cell
+				}
+			}
+			return cells
+		}
+
+		Context("when hedging slow state requests", func() {
+			BeforeEach(func() {
+				options.HedgeAfter = 20 * time.Millisecond
+
+				calls := int32(0)
+				repB.StateStub = func() (auctiontypes.CellState, error) {
+					if atomic.AddInt32(&calls, 1) == 1 {
+						<-blockForever
+					}
+					return BuildCellState("the-zone", 10, 10, 100, false, lucidOnlyRootFSProviders, nil), nil
+				}
+			})
+
+			It("uses the second request when the first is slow", func() {
+				cells := fetch()
+				Expect(cells).To(HaveLen(3))
+				Expect(cells["B"].Stale()).To(BeFalse())
+				Expect(repB.StateCallCount()).To(Equal(2))
+			})
+
+			It("does not hedge cells that answer in time", func() {
+				fetch()
+				Expect(repA.StateCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when a cell misses the soft deadline", func() {
+			BeforeEach(func() {
+				options.SoftDeadline = 20 * time.Millisecond
+				options.Cache = auctionrunner.NewStateCache(time.Minute, clock.NewClock())
+
+				repB.StateStub = func() (auctiontypes.CellState, error) {
+					<-blockForever
+					return BuildCellState("the-zone", 10, 10, 100, false, lucidOnlyRootFSProviders, nil), nil
+				}
+			})
+
+			It("caches the state of the cells that answered", func() {
+				fetch()
+				_, ok := options.Cache.Get("A")
+				Expect(ok).To(BeTrue())
+				_, ok = options.Cache.Get("B")
+				Expect(ok).To(BeFalse())
+			})
+
+			It("leaves the cell out if it has no cached state", func() {
+				cells := fetch()
+				Expect(cells).To(HaveLen(2))
+				Expect(cells).NotTo(HaveKey("B"))
+			})
+
+			It("builds the cell from its cached state, marked stale", func() {
+				options.Cache.Put("B", BuildCellState("the-zone", 10, 10, 100, false, lucidOnlyRootFSProviders, nil))
+
+				cells := fetch()
+				Expect(cells).To(HaveLen(3))
+				Expect(cells["B"].Stale()).To(BeTrue())
+				Expect(cells["A"].Stale()).To(BeFalse())
+			})
+		})
+	})
 })
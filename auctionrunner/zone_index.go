@@ -0,0 +1,128 @@
+package auctionrunner
+
+import (
+	"sort"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+// bucketKey is everything a cell's score depends on apart from the instances
+// it runs.  Cells with the same key score the same for a task, and the same
+// for an LRP when they run the same number of its instances.
+type bucketKey struct {
+	total     auctiontypes.Resources
+	available auctiontypes.Resources
+	stale     bool
+}
+
+func bucketKeyFor(cell *Cell) bucketKey {
+	return bucketKey{
+		total:     cell.state.TotalResources,
+		available: cell.state.AvailableResources,
+		stale:     cell.stale,
+	}
+}
+
+type cellBucket struct {
+	key   bucketKey
+	cells []*Cell
+}
+
+// zoneIndex groups the cells of one zone that can run one rootfs into
+// buckets, so that an auction scores each bucket once instead of every cell.
+// Large fleets of identical cells collapse into a handful of buckets.
+//
+// With a tie-break policy, each bucket is kept in policy order, so the first
+// cell in a bucket is the one the policy picks among equal scores.  Otherwise
+// buckets are unordered.
+type zoneIndex struct {
+	name     string
+	cells    Zone
+	tieBreak TieBreakPolicy
+
+	buckets   []*cellBucket
+	byKey     map[bucketKey]*cellBucket
+	positions map[*Cell]int
+}
+
+func newZoneIndex(name string, cells Zone, tieBreak TieBreakPolicy) *zoneIndex {
+	index := &zoneIndex{
+		name:      name,
+		cells:     cells,
+		tieBreak:  tieBreak,
+		byKey:     map[bucketKey]*cellBucket{},
+		positions: make(map[*Cell]int, len(cells)),
+	}
+
+	for _, cell := range cells {
+		index.add(cell)
+	}
+
+	return index
+}
+
+// update moves a cell whose resources changed to its new bucket
+func (z *zoneIndex) update(cell *Cell, old bucketKey) {
+	bucket := z.byKey[old]
+	z.remove(bucket, cell)
+
+	if len(bucket.cells) == 0 {
+		delete(z.byKey, old)
+		for i, b := range z.buckets {
+			if b == bucket {
+				z.buckets = append(z.buckets[:i], z.buckets[i+1:]...)
+				break
+			}
+		}
+	}
+
+	z.add(cell)
+}
+
+func (z *zoneIndex) add(cell *Cell) {
+	key := bucketKeyFor(cell)
+
+	bucket, ok := z.byKey[key]
+	if !ok {
+		bucket = &cellBucket{key: key}
+		z.byKey[key] = bucket
+		z.buckets = append(z.buckets, bucket)
+	}
+
+	if z.tieBreak == nil {
+		z.positions[cell] = len(bucket.cells)
+		bucket.cells = append(bucket.cells, cell)
+		return
+	}
+
+	i := sort.Search(len(bucket.cells), func(i int) bool {
+		return z.tieBreak(cell, bucket.cells[i])
+	})
+	bucket.cells = append(bucket.cells, nil)
+	copy(bucket.cells[i+1:], bucket.cells[i:])
+	bucket.cells[i] = cell
+}
+
+func (z *zoneIndex) remove(bucket *cellBucket, cell *Cell) {
+	if z.tieBreak == nil {
+		position := z.positions[cell]
+		last := len(bucket.cells) - 1
+		bucket.cells[position] = bucket.cells[last]
+		z.positions[bucket.cells[position]] = position
+		bucket.cells = bucket.cells[:last]
+		return
+	}
+
+	i := sort.Search(len(bucket.cells), func(i int) bool {
+		return !z.tieBreak(bucket.cells[i], cell)
+	})
+	if i == len(bucket.cells) || bucket.cells[i] != cell {
+		// the policy does not order cells strictly; fall back to looking
+		for i = range bucket.cells {
+			if bucket.cells[i] == cell {
+				break
+			}
+		}
+	}
+	bucket.cells = append(bucket.cells[:i], bucket.cells[i+1:]...)
+}
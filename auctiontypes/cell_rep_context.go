@@ -0,0 +1,66 @@
+package auctiontypes
+
+import "context"
+
+// StateWithContext fetches rep's state, giving up when ctx is done.  Reps that
+// are not ContextCellReps keep running in the background after that, but the
+// caller is freed.
+func StateWithContext(ctx context.Context, rep CellRep) (CellState, error) {
+	if contextRep, ok := rep.(ContextCellRep); ok {
+		return contextRep.StateContext(ctx)
+	}
+
+	err := ctx.Err()
+	if err != nil {
+		return CellState{}, err
+	}
+
+	type result struct {
+		state CellState
+		err   error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		state, err := rep.State()
+		results <- result{state, err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.state, r.err
+	case <-ctx.Done():
+		return CellState{}, ctx.Err()
+	}
+}
+
+// PerformWithContext sends work to rep, giving up when ctx is done.  As with
+// any failed Perform, the rep may or may not have performed the work.
+func PerformWithContext(ctx context.Context, rep CellRep, work Work) (Work, error) {
+	if contextRep, ok := rep.(ContextCellRep); ok {
+		return contextRep.PerformContext(ctx, work)
+	}
+
+	err := ctx.Err()
+	if err != nil {
+		return Work{}, err
+	}
+
+	type result struct {
+		failedWork Work
+		err        error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		failedWork, err := rep.Perform(work)
+		results <- result{failedWork, err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.failedWork, r.err
+	case <-ctx.Done():
+		return Work{}, ctx.Err()
+	}
+}
@@ -0,0 +1,118 @@
+package auctiontypes_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Calling CellReps with a context", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var state auctiontypes.CellState
+	var work auctiontypes.Work
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		state = auctiontypes.CellState{Zone: "the-zone"}
+		work = auctiontypes.Work{Generation: 2}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	Context("with a ContextCellRep", func() {
+		var rep *fakes.FakeContextCellRep
+
+		BeforeEach(func() {
+			rep = &fakes.FakeContextCellRep{}
+			rep.StateContextReturns(state, nil)
+			rep.PerformContextReturns(work, nil)
+		})
+
+		It("passes the context along", func() {
+			Expect(auctiontypes.StateWithContext(ctx, rep)).To(Equal(state))
+			Expect(rep.StateContextArgsForCall(0)).To(Equal(ctx))
+
+			Expect(auctiontypes.PerformWithContext(ctx, rep, work)).To(Equal(work))
+			actualCtx, actualWork := rep.PerformContextArgsForCall(0)
+			Expect(actualCtx).To(Equal(ctx))
+			Expect(actualWork).To(Equal(work))
+
+			Expect(rep.StateCallCount()).To(Equal(0))
+			Expect(rep.PerformCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("with a plain CellRep", func() {
+		var rep *fakes.FakeSimulationCellRep
+
+		BeforeEach(func() {
+			rep = &fakes.FakeSimulationCellRep{}
+			rep.StateReturns(state, nil)
+			rep.PerformReturns(work, errors.New("boom"))
+		})
+
+		It("returns whatever the rep returns", func() {
+			Expect(auctiontypes.StateWithContext(ctx, rep)).To(Equal(state))
+
+			failedWork, err := auctiontypes.PerformWithContext(ctx, rep, work)
+			Expect(failedWork).To(Equal(work))
+			Expect(err).To(MatchError("boom"))
+		})
+
+		Context("when the context is already done", func() {
+			BeforeEach(func() {
+				cancel()
+			})
+
+			It("does not call the rep", func() {
+				_, err := auctiontypes.StateWithContext(ctx, rep)
+				Expect(err).To(Equal(context.Canceled))
+
+				_, err = auctiontypes.PerformWithContext(ctx, rep, work)
+				Expect(err).To(Equal(context.Canceled))
+
+				Expect(rep.StateCallCount()).To(Equal(0))
+				Expect(rep.PerformCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the rep does not answer before the deadline", func() {
+			var blockForever chan struct{}
+
+			BeforeEach(func() {
+				blockForever = make(chan struct{})
+				rep.StateStub = func() (auctiontypes.CellState, error) {
+					<-blockForever
+					return state, nil
+				}
+				rep.PerformStub = func(auctiontypes.Work) (auctiontypes.Work, error) {
+					<-blockForever
+					return work, nil
+				}
+
+				ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+			})
+
+			AfterEach(func() {
+				close(blockForever)
+			})
+
+			It("gives up", func() {
+				_, err := auctiontypes.StateWithContext(ctx, rep)
+				Expect(err).To(Equal(context.DeadlineExceeded))
+
+				_, err = auctiontypes.PerformWithContext(ctx, rep, work)
+				Expect(err).To(Equal(context.DeadlineExceeded))
+			})
+		})
+	})
+})
@@ -0,0 +1,174 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+type FakeContextCellRep struct {
+	StateStub        func() (auctiontypes.CellState, error)
+	stateMutex       sync.RWMutex
+	stateArgsForCall []struct{}
+	stateReturns     struct {
+		result1 auctiontypes.CellState
+		result2 error
+	}
+	PerformStub        func(auctiontypes.Work) (auctiontypes.Work, error)
+	performMutex       sync.RWMutex
+	performArgsForCall []struct {
+		arg1 auctiontypes.Work
+	}
+	performReturns struct {
+		result1 auctiontypes.Work
+		result2 error
+	}
+	StateContextStub        func(context.Context) (auctiontypes.CellState, error)
+	stateContextMutex       sync.RWMutex
+	stateContextArgsForCall []struct {
+		arg1 context.Context
+	}
+	stateContextReturns struct {
+		result1 auctiontypes.CellState
+		result2 error
+	}
+	PerformContextStub        func(context.Context, auctiontypes.Work) (auctiontypes.Work, error)
+	performContextMutex       sync.RWMutex
+	performContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 auctiontypes.Work
+	}
+	performContextReturns struct {
+		result1 auctiontypes.Work
+		result2 error
+	}
+}
+
+func (fake *FakeContextCellRep) State() (auctiontypes.CellState, error) {
+	fake.stateMutex.Lock()
+	fake.stateArgsForCall = append(fake.stateArgsForCall, struct{}{})
+	fake.stateMutex.Unlock()
+	if fake.StateStub != nil {
+		return fake.StateStub()
+	} else {
+		return fake.stateReturns.result1, fake.stateReturns.result2
+	}
+}
+
+func (fake *FakeContextCellRep) StateCallCount() int {
+	fake.stateMutex.RLock()
+	defer fake.stateMutex.RUnlock()
+	return len(fake.stateArgsForCall)
+}
+
+func (fake *FakeContextCellRep) StateReturns(result1 auctiontypes.CellState, result2 error) {
+	fake.StateStub = nil
+	fake.stateReturns = struct {
+		result1 auctiontypes.CellState
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextCellRep) Perform(arg1 auctiontypes.Work) (auctiontypes.Work, error) {
+	fake.performMutex.Lock()
+	fake.performArgsForCall = append(fake.performArgsForCall, struct {
+		arg1 auctiontypes.Work
+	}{arg1})
+	fake.performMutex.Unlock()
+	if fake.PerformStub != nil {
+		return fake.PerformStub(arg1)
+	} else {
+		return fake.performReturns.result1, fake.performReturns.result2
+	}
+}
+
+func (fake *FakeContextCellRep) PerformCallCount() int {
+	fake.performMutex.RLock()
+	defer fake.performMutex.RUnlock()
+	return len(fake.performArgsForCall)
+}
+
+func (fake *FakeContextCellRep) PerformArgsForCall(i int) auctiontypes.Work {
+	fake.performMutex.RLock()
+	defer fake.performMutex.RUnlock()
+	return fake.performArgsForCall[i].arg1
+}
+
+func (fake *FakeContextCellRep) PerformReturns(result1 auctiontypes.Work, result2 error) {
+	fake.PerformStub = nil
+	fake.performReturns = struct {
+		result1 auctiontypes.Work
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextCellRep) StateContext(arg1 context.Context) (auctiontypes.CellState, error) {
+	fake.stateContextMutex.Lock()
+	fake.stateContextArgsForCall = append(fake.stateContextArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	fake.stateContextMutex.Unlock()
+	if fake.StateContextStub != nil {
+		return fake.StateContextStub(arg1)
+	} else {
+		return fake.stateContextReturns.result1, fake.stateContextReturns.result2
+	}
+}
+
+func (fake *FakeContextCellRep) StateContextCallCount() int {
+	fake.stateContextMutex.RLock()
+	defer fake.stateContextMutex.RUnlock()
+	return len(fake.stateContextArgsForCall)
+}
+
+func (fake *FakeContextCellRep) StateContextArgsForCall(i int) context.Context {
+	fake.stateContextMutex.RLock()
+	defer fake.stateContextMutex.RUnlock()
+	return fake.stateContextArgsForCall[i].arg1
+}
+
+func (fake *FakeContextCellRep) StateContextReturns(result1 auctiontypes.CellState, result2 error) {
+	fake.StateContextStub = nil
+	fake.stateContextReturns = struct {
+		result1 auctiontypes.CellState
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextCellRep) PerformContext(arg1 context.Context, arg2 auctiontypes.Work) (auctiontypes.Work, error) {
+	fake.performContextMutex.Lock()
+	fake.performContextArgsForCall = append(fake.performContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 auctiontypes.Work
+	}{arg1, arg2})
+	fake.performContextMutex.Unlock()
+	if fake.PerformContextStub != nil {
+		return fake.PerformContextStub(arg1, arg2)
+	} else {
+		return fake.performContextReturns.result1, fake.performContextReturns.result2
+	}
+}
+
+func (fake *FakeContextCellRep) PerformContextCallCount() int {
+	fake.performContextMutex.RLock()
+	defer fake.performContextMutex.RUnlock()
+	return len(fake.performContextArgsForCall)
+}
+
+func (fake *FakeContextCellRep) PerformContextArgsForCall(i int) (context.Context, auctiontypes.Work) {
+	fake.performContextMutex.RLock()
+	defer fake.performContextMutex.RUnlock()
+	return fake.performContextArgsForCall[i].arg1, fake.performContextArgsForCall[i].arg2
+}
+
+func (fake *FakeContextCellRep) PerformContextReturns(result1 auctiontypes.Work, result2 error) {
+	fake.PerformContextStub = nil
+	fake.performContextReturns = struct {
+		result1 auctiontypes.Work
+		result2 error
+	}{result1, result2}
+}
+
+var _ auctiontypes.ContextCellRep = new(FakeContextCellRep)
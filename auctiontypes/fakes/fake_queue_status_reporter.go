@@ -0,0 +1,43 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+type FakeQueueStatusReporter struct {
+	QueueStatusStub        func() auctiontypes.QueueStatus
+	queueStatusMutex       sync.RWMutex
+	queueStatusArgsForCall []struct{}
+	queueStatusReturns     struct {
+		result1 auctiontypes.QueueStatus
+	}
+}
+
+func (fake *FakeQueueStatusReporter) QueueStatus() auctiontypes.QueueStatus {
+	fake.queueStatusMutex.Lock()
+	fake.queueStatusArgsForCall = append(fake.queueStatusArgsForCall, struct{}{})
+	fake.queueStatusMutex.Unlock()
+	if fake.QueueStatusStub != nil {
+		return fake.QueueStatusStub()
+	} else {
+		return fake.queueStatusReturns.result1
+	}
+}
+
+func (fake *FakeQueueStatusReporter) QueueStatusCallCount() int {
+	fake.queueStatusMutex.RLock()
+	defer fake.queueStatusMutex.RUnlock()
+	return len(fake.queueStatusArgsForCall)
+}
+
+func (fake *FakeQueueStatusReporter) QueueStatusReturns(result1 auctiontypes.QueueStatus) {
+	fake.QueueStatusStub = nil
+	fake.queueStatusReturns = struct {
+		result1 auctiontypes.QueueStatus
+	}{result1}
+}
+
+var _ auctiontypes.QueueStatusReporter = new(FakeQueueStatusReporter)
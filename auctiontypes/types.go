@@ -1,6 +1,7 @@
 package auctiontypes
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -16,6 +17,7 @@ import (
 var ErrorCellMismatch = errors.New(diego_errors.CELL_MISMATCH_MESSAGE)
 var ErrorInsufficientResources = errors.New(diego_errors.INSUFFICIENT_RESOURCES_MESSAGE)
 var ErrorNothingToStop = errors.New("nothing to stop")
+var ErrorStaleCellState = errors.New("stale cell state")
 
 //go:generate counterfeiter -o fakes/fake_auction_runner.go . AuctionRunner
 type AuctionRunner interface {
@@ -24,6 +26,20 @@ type AuctionRunner interface {
 	ScheduleTasksForAuctions([]models.Task)
 }
 
+//go:generate counterfeiter -o fakes/fake_queue_status_reporter.go . QueueStatusReporter
+
+// QueueStatusReporter is implemented by AuctionRunners that can count the
+// auctions waiting for the next auction round, as auctionrunner's do.
+type QueueStatusReporter interface {
+	QueueStatus() QueueStatus
+}
+
+// QueueStatus counts the auctions waiting for the next auction round.
+type QueueStatus struct {
+	LRPAuctions  int
+	TaskAuctions int
+}
+
 type AuctionRunnerDelegate interface {
 	FetchCellReps() (map[string]CellRep, error)
 	AuctionCompleted(AuctionResults)
@@ -34,20 +50,79 @@ type AuctionMetricEmitterDelegate interface {
 	AuctionCompleted(AuctionResults)
 }
 
+// ZoneCapacity totals the cells in a zone that took part in an auction.
+type ZoneCapacity struct {
+	Cells              int
+	AvailableResources Resources
+	TotalResources     Resources
+}
+
+func (c *ZoneCapacity) Add(state CellState) {
+	c.Cells++
+	c.AvailableResources.MemoryMB += state.AvailableResources.MemoryMB
+	c.AvailableResources.DiskMB += state.AvailableResources.DiskMB
+	c.AvailableResources.Containers += state.AvailableResources.Containers
+	c.TotalResources.MemoryMB += state.TotalResources.MemoryMB
+	c.TotalResources.DiskMB += state.TotalResources.DiskMB
+	c.TotalResources.Containers += state.TotalResources.Containers
+}
+
+// ZoneCapacityMetricEmitter is implemented by AuctionMetricEmitterDelegates
+// that want the capacity of every zone each time the cells' state is fetched.
+type ZoneCapacityMetricEmitter interface {
+	ZoneCapacitiesFetched(map[string]ZoneCapacity)
+}
+
+type CircuitBreakerState string
+
+const (
+	CircuitBreakerClosed   CircuitBreakerState = "closed"
+	CircuitBreakerOpen     CircuitBreakerState = "open"
+	CircuitBreakerHalfOpen CircuitBreakerState = "half-open"
+)
+
+// CircuitBreakerMetricEmitter is implemented by AuctionMetricEmitterDelegates
+// that want to hear when a cell's circuit breaker changes state.
+type CircuitBreakerMetricEmitter interface {
+	CircuitBreakerTransitioned(cellGuid string, from, to CircuitBreakerState)
+}
+
 type AuctionRequest struct {
 	LRPs  []LRPAuction
 	Tasks []TaskAuction
 }
 
 type AuctionResults struct {
-	SuccessfulLRPs  []LRPAuction
-	SuccessfulTasks []TaskAuction
-	FailedLRPs      []LRPAuction
-	FailedTasks     []TaskAuction
+	SuccessfulLRPs      []LRPAuction
+	SuccessfulTasks     []TaskAuction
+	FailedLRPs          []LRPAuction
+	FailedTasks         []TaskAuction
+	AlreadyRunningLRPs  []LRPAuction
+	AlreadyRunningTasks []TaskAuction
 }
 
 // LRPStart and Task Auctions
 
+type AuctionOutcome string
+
+const (
+	AuctionSucceeded      AuctionOutcome = "succeeded"
+	AuctionFailed         AuctionOutcome = "failed"
+	AuctionAlreadyRunning AuctionOutcome = "already-running"
+)
+
+// LRPAuctionResult and TaskAuctionResult record how a completed auction
+// turned out.
+type LRPAuctionResult struct {
+	Outcome AuctionOutcome
+	Auction LRPAuction
+}
+
+type TaskAuctionResult struct {
+	Outcome AuctionOutcome
+	Auction TaskAuction
+}
+
 type AuctionRecord struct {
 	Winner   string
 	Attempts int
@@ -99,11 +174,34 @@ type SimulationCellRep interface {
 	Reset() error
 }
 
+//go:generate counterfeiter -o fakes/fake_context_cell_rep.go . ContextCellRep
+
+// ContextCellRep is a CellRep whose calls can be abandoned.  Use
+// StateWithContext and PerformWithContext to call any CellRep with a context.
+type ContextCellRep interface {
+	CellRep
+
+	StateContext(context.Context) (CellState, error)
+	PerformContext(context.Context, Work) (Work, error)
+}
+
+// ReadinessReporter is implemented by CellReps that can be alive but not yet,
+// or no longer, ready to take work.  Ready returns why the rep isn't ready.
+// CellReps that don't implement it are ready whenever they are alive.
+type ReadinessReporter interface {
+	Ready() error
+}
+
+// Generation echoes the CellState.Generation the work was scheduled against.
+// Reps reject work with a stale, non-zero Generation with ErrorStaleCellState.
 type Work struct {
-	LRPs  []LRPAuction
-	Tasks []models.Task
+	LRPs       []LRPAuction
+	Tasks      []models.Task
+	Generation uint64
 }
 
+// Generation changes whenever the cell's LRPs, tasks or resources change.
+// Zero means the cell does not version its state.
 type CellState struct {
 	RootFSProviders    RootFSProviders
 	AvailableResources Resources
@@ -112,6 +210,7 @@ type CellState struct {
 	Tasks              []Task
 	Zone               string
 	Evacuating         bool
+	Generation         uint64
 }
 
 func (cell CellState) MatchRootFS(rootfs string) bool {
@@ -0,0 +1,117 @@
+package auction_grpc_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+)
+
+type AuctionGRPCClient struct {
+	conn    grpc.ClientConnInterface
+	repGuid string
+	timeout time.Duration
+	logger  lager.Logger
+}
+
+// New returns a SimulationCellRep that talks to the rep on the other end of
+// conn.  Each call gives up after timeout; a zero timeout never gives up.
+// Connections can be shared between clients.
+func New(conn grpc.ClientConnInterface, repGuid string, timeout time.Duration, logger lager.Logger) *AuctionGRPCClient {
+	return &AuctionGRPCClient{
+		conn:    conn,
+		repGuid: repGuid,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Dial opens a connection to the rep at address.  The connection is made
+// lazily, on the first call.
+func Dial(address string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.NewClient(address, opts...)
+}
+
+func (c *AuctionGRPCClient) State() (auctiontypes.CellState, error) {
+	return c.StateContext(context.Background())
+}
+
+func (c *AuctionGRPCClient) StateContext(ctx context.Context) (auctiontypes.CellState, error) {
+	logger := c.logger.Session("fetching-state", lager.Data{
+		"rep": c.repGuid,
+	})
+
+	logger.Debug("requesting")
+
+	var state auctiontypes.CellState
+	err := c.invoke(ctx, services.State, &services.Empty{}, &state)
+	if err != nil {
+		logger.Error("failed-to-perform-request", err)
+		return auctiontypes.CellState{}, err
+	}
+
+	logger.Debug("done")
+
+	return state, nil
+}
+
+func (c *AuctionGRPCClient) Perform(work auctiontypes.Work) (auctiontypes.Work, error) {
+	return c.PerformContext(context.Background(), work)
+}
+
+func (c *AuctionGRPCClient) PerformContext(ctx context.Context, work auctiontypes.Work) (auctiontypes.Work, error) {
+	logger := c.logger.Session("sending-work", lager.Data{
+		"rep":    c.repGuid,
+		"starts": len(work.LRPs),
+	})
+
+	logger.Debug("requesting")
+
+	var failedWork auctiontypes.Work
+	err := c.invoke(ctx, services.Perform, &work, &failedWork)
+	if status.Code(err) == codes.Aborted {
+		logger.Error("stale-cell-state", auctiontypes.ErrorStaleCellState)
+		return auctiontypes.Work{}, auctiontypes.ErrorStaleCellState
+	}
+	if err != nil {
+		logger.Error("failed-to-perform-request", err)
+		return auctiontypes.Work{}, err
+	}
+
+	logger.Debug("done")
+
+	return failedWork, nil
+}
+
+func (c *AuctionGRPCClient) Reset() error {
+	logger := c.logger.Session("SIM-reseting", lager.Data{
+		"rep": c.repGuid,
+	})
+
+	logger.Debug("requesting")
+
+	err := c.invoke(context.Background(), services.Sim_Reset, &services.Empty{}, &services.Empty{})
+	if err != nil {
+		logger.Error("failed-to-perform-request", err)
+		return err
+	}
+
+	logger.Debug("done")
+	return nil
+}
+
+func (c *AuctionGRPCClient) invoke(ctx context.Context, method string, request, response interface{}) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	return c.conn.Invoke(ctx, services.FullMethod(method), request, response, services.CallOptions()...)
+}
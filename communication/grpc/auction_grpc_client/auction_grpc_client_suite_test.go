@@ -0,0 +1,71 @@
+package auction_grpc_client_test
+
+import (
+	"net"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_client"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_server"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-golang/lager/lagertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"testing"
+)
+
+func TestAuctionGrpcClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AuctionGrpcClient Suite")
+}
+
+var auctionRep *fakes.FakeSimulationCellRep
+var server *grpc.Server
+var listenerThatErrors net.Listener
+var conn, connToServerThatErrors *grpc.ClientConn
+var client, clientForServerThatErrors auctiontypes.SimulationCellRep
+
+var _ = BeforeEach(func() {
+	logger := lagertest.NewTestLogger("test")
+
+	auctionRep = &fakes.FakeSimulationCellRep{}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	server = services.NewServer()
+	services.RegisterCellRepServer(server, auction_grpc_server.New(auctionRep, logger))
+	go server.Serve(listener)
+
+	conn, err = auction_grpc_client.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	Expect(err).NotTo(HaveOccurred())
+	client = auction_grpc_client.New(conn, "rep-guid", time.Second, logger)
+
+	listenerThatErrors, err = net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	go func() {
+		for {
+			c, err := listenerThatErrors.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+
+	connToServerThatErrors, err = auction_grpc_client.Dial(listenerThatErrors.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	Expect(err).NotTo(HaveOccurred())
+	clientForServerThatErrors = auction_grpc_client.New(connToServerThatErrors, "rep-guid", time.Second, logger)
+})
+
+var _ = AfterEach(func() {
+	conn.Close()
+	connToServerThatErrors.Close()
+	server.Stop()
+	listenerThatErrors.Close()
+})
@@ -0,0 +1,129 @@
+package auction_grpc_client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_client"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_server"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+)
+
+var benchmarkRunningLRPs = []int{10, 100, 1000}
+
+// BenchmarkFetchState fetches the state of a cell running LRPs, and a tenth
+// as many tasks, over gRPC and over http, reporting the encoded state's size
+func BenchmarkFetchState(b *testing.B) {
+	for _, numLRPs := range benchmarkRunningLRPs {
+		state := benchmarkCellState(numLRPs)
+
+		b.Run(fmt.Sprintf("grpc, %d lrps", numLRPs), func(b *testing.B) {
+			payload, err := services.Codec{}.Marshal(&state)
+			if err != nil {
+				b.Fatal(err)
+			}
+			benchmarkFetchState(b, newBenchmarkGRPCClient(b, state), len(payload))
+		})
+
+		b.Run(fmt.Sprintf("http, %d lrps", numLRPs), func(b *testing.B) {
+			payload, err := json.Marshal(state)
+			if err != nil {
+				b.Fatal(err)
+			}
+			benchmarkFetchState(b, newBenchmarkHTTPClient(b, state), len(payload))
+		})
+	}
+}
+
+func benchmarkFetchState(b *testing.B, client auctiontypes.CellRep, stateBytes int) {
+	b.ReportAllocs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := client.State()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(stateBytes), "state-bytes")
+}
+
+func benchmarkRep(state auctiontypes.CellState) *fakes.FakeSimulationCellRep {
+	rep := &fakes.FakeSimulationCellRep{}
+	rep.StateReturns(state, nil)
+	return rep
+}
+
+func newBenchmarkGRPCClient(b *testing.B, state auctiontypes.CellState) auctiontypes.CellRep {
+	logger := lager.NewLogger("benchmark")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	server := services.NewServer()
+	services.RegisterCellRepServer(server, auction_grpc_server.New(benchmarkRep(state), logger))
+	go server.Serve(listener)
+	b.Cleanup(server.Stop)
+
+	conn, err := auction_grpc_client.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { conn.Close() })
+
+	return auction_grpc_client.New(conn, "rep-guid", time.Second, logger)
+}
+
+func newBenchmarkHTTPClient(b *testing.B, state auctiontypes.CellState) auctiontypes.CellRep {
+	logger := lager.NewLogger("benchmark")
+
+	handler, err := rata.NewRouter(routes.Routes, auction_http_handlers.New(benchmarkRep(state), logger))
+	if err != nil {
+		b.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	b.Cleanup(server.Close)
+
+	return auction_http_client.New(&http.Client{}, "rep-guid", server.URL, logger)
+}
+
+func benchmarkCellState(numLRPs int) auctiontypes.CellState {
+	state := auctiontypes.CellState{
+		RootFSProviders: auctiontypes.RootFSProviders{
+			models.PreloadedRootFSScheme: auctiontypes.NewFixedSetRootFSProvider("lucid64", "cflinuxfs2"),
+			"docker":                     auctiontypes.ArbitraryRootFSProvider{},
+		},
+		TotalResources:     auctiontypes.Resources{MemoryMB: 64 * 1024, DiskMB: 64 * 1024, Containers: 2000},
+		AvailableResources: auctiontypes.Resources{MemoryMB: 32 * 1024, DiskMB: 32 * 1024, Containers: 1000},
+		Zone:               "z1",
+		Generation:         42,
+	}
+
+	for i := 0; i < numLRPs; i++ {
+		state.LRPs = append(state.LRPs, auctiontypes.LRP{ProcessGuid: fmt.Sprintf("process-guid-%d", i), Index: i % 10, MemoryMB: 256, DiskMB: 1024})
+		if i%10 == 0 {
+			state.Tasks = append(state.Tasks, auctiontypes.Task{TaskGuid: fmt.Sprintf("task-guid-%d", i), MemoryMB: 128, DiskMB: 512})
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,120 @@
+package auction_grpc_server
+
+import (
+	"context"
+	"net"
+	"os"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/ifrit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+)
+
+type cellRepServer struct {
+	rep    auctiontypes.CellRep
+	logger lager.Logger
+}
+
+// New adapts rep to the CellRep gRPC service.  Stale work is reported with
+// codes.Aborted, which the client turns back into ErrorStaleCellState.
+func New(rep auctiontypes.CellRep, logger lager.Logger) services.CellRepServer {
+	return &cellRepServer{
+		rep:    rep,
+		logger: logger,
+	}
+}
+
+func (s *cellRepServer) State(ctx context.Context, _ *services.Empty) (*auctiontypes.CellState, error) {
+	logger := s.logger.Session("auction-fetch-state")
+	logger.Info("handling")
+
+	state, err := auctiontypes.StateWithContext(ctx, s.rep)
+	if err != nil {
+		logger.Error("failed-to-fetch-state", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	logger.Info("success")
+	return &state, nil
+}
+
+func (s *cellRepServer) Perform(ctx context.Context, work *auctiontypes.Work) (*auctiontypes.Work, error) {
+	logger := s.logger.Session("auction-perform-work")
+	logger.Info("handling")
+
+	failedWork, err := auctiontypes.PerformWithContext(ctx, s.rep, *work)
+	if err == auctiontypes.ErrorStaleCellState {
+		logger.Error("stale-cell-state", err)
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if err != nil {
+		logger.Error("failed-to-perform-work", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	logger.Info("success")
+	return &failedWork, nil
+}
+
+func (s *cellRepServer) Reset(ctx context.Context, _ *services.Empty) (*services.Empty, error) {
+	logger := s.logger.Session("sim-reset")
+	logger.Info("handling")
+
+	simRep, ok := s.rep.(auctiontypes.SimulationCellRep)
+	if !ok {
+		logger.Error("not-a-simulation-rep", nil)
+		return nil, status.Error(codes.Unimplemented, "not a simulation rep")
+	}
+
+	err := simRep.Reset()
+	if err != nil {
+		logger.Error("failed-to-reset", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	logger.Info("success")
+	return &services.Empty{}, nil
+}
+
+type serverRunner struct {
+	address string
+	server  *grpc.Server
+}
+
+// NewRunner serves rep over gRPC on address until signaled.
+func NewRunner(address string, rep auctiontypes.CellRep, logger lager.Logger, opts ...grpc.ServerOption) ifrit.Runner {
+	server := services.NewServer(opts...)
+	services.RegisterCellRepServer(server, New(rep, logger))
+
+	return &serverRunner{
+		address: address,
+		server:  server,
+	}
+}
+
+func (r *serverRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	listener, err := net.Listen("tcp", r.address)
+	if err != nil {
+		return err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- r.server.Serve(listener)
+	}()
+
+	close(ready)
+
+	select {
+	case <-signals:
+		r.server.GracefulStop()
+		return nil
+	case err := <-errChan:
+		return err
+	}
+}
@@ -0,0 +1,55 @@
+package auction_grpc_server_test
+
+import (
+	"context"
+	"net"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_server"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+	"github.com/pivotal-golang/lager/lagertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAuctionGrpcServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AuctionGrpcServer Suite")
+}
+
+var server *grpc.Server
+var conn *grpc.ClientConn
+var auctionRep *fakes.FakeSimulationCellRep
+
+var _ = BeforeEach(func() {
+	logger := lagertest.NewTestLogger("auction_grpc_server")
+
+	auctionRep = &fakes.FakeSimulationCellRep{}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	server = services.NewServer()
+	services.RegisterCellRepServer(server, auction_grpc_server.New(auctionRep, logger))
+	go server.Serve(listener)
+
+	conn, err = grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	Expect(err).NotTo(HaveOccurred())
+})
+
+var _ = AfterEach(func() {
+	conn.Close()
+	server.Stop()
+})
+
+func Invoke(method string, request, response interface{}) codes.Code {
+	err := conn.Invoke(context.Background(), services.FullMethod(method), request, response, services.CallOptions()...)
+	return status.Code(err)
+}
@@ -0,0 +1,84 @@
+package auction_grpc_server_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	"google.golang.org/grpc/codes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Perform", func() {
+	var requestedWork, failedWork auctiontypes.Work
+	BeforeEach(func() {
+		requestedWork = auctiontypes.Work{
+			Tasks: []models.Task{
+				{
+					TaskGuid: "tg-a",
+				},
+				{
+					TaskGuid: "tg-b",
+				},
+			},
+		}
+
+		failedWork = auctiontypes.Work{
+			Tasks: []models.Task{
+				{
+					TaskGuid: "pg-a",
+				},
+			},
+		}
+	})
+
+	Context("and no perform error", func() {
+		BeforeEach(func() {
+			auctionRep.PerformReturns(failedWork, nil)
+		})
+
+		It("succeeds, returning any failed work", func() {
+			Expect(auctionRep.PerformCallCount()).To(Equal(0))
+
+			var response auctiontypes.Work
+			Expect(Invoke(services.Perform, &requestedWork, &response)).To(Equal(codes.OK))
+			Expect(response).To(Equal(failedWork))
+
+			Expect(auctionRep.PerformCallCount()).To(Equal(1))
+			Expect(auctionRep.PerformArgsForCall(0)).To(Equal(requestedWork))
+		})
+	})
+
+	Context("and a perform error", func() {
+		BeforeEach(func() {
+			auctionRep.PerformReturns(failedWork, errors.New("kaboom"))
+		})
+
+		It("fails, returning nothing", func() {
+			var response auctiontypes.Work
+			Expect(Invoke(services.Perform, &requestedWork, &response)).To(Equal(codes.Internal))
+			Expect(response).To(BeZero())
+
+			Expect(auctionRep.PerformCallCount()).To(Equal(1))
+			Expect(auctionRep.PerformArgsForCall(0)).To(Equal(requestedWork))
+		})
+	})
+
+	Context("when the work is stale", func() {
+		BeforeEach(func() {
+			auctionRep.PerformReturns(auctiontypes.Work{}, auctiontypes.ErrorStaleCellState)
+		})
+
+		It("aborts, returning nothing", func() {
+			var response auctiontypes.Work
+			Expect(Invoke(services.Perform, &auctiontypes.Work{Generation: 3}, &response)).To(Equal(codes.Aborted))
+			Expect(response).To(BeZero())
+
+			Expect(auctionRep.PerformCallCount()).To(Equal(1))
+			Expect(auctionRep.PerformArgsForCall(0).Generation).To(BeEquivalentTo(3))
+		})
+	})
+})
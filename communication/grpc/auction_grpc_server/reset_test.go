@@ -0,0 +1,47 @@
+package auction_grpc_server_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_server"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+	"github.com/pivotal-golang/lager/lagertest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reset", func() {
+	Context("when the reset succeeds", func() {
+		It("succeeds", func() {
+			Expect(auctionRep.ResetCallCount()).To(Equal(0))
+
+			Expect(Invoke(services.Sim_Reset, &services.Empty{}, &services.Empty{})).To(Equal(codes.OK))
+
+			Expect(auctionRep.ResetCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the reset fails", func() {
+		It("fails", func() {
+			auctionRep.ResetReturns(errors.New("boom"))
+
+			Expect(Invoke(services.Sim_Reset, &services.Empty{}, &services.Empty{})).To(Equal(codes.Internal))
+
+			Expect(auctionRep.ResetCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the rep is not a simulation rep", func() {
+		It("is unimplemented", func() {
+			cellRepServer := auction_grpc_server.New(struct{ auctiontypes.CellRep }{auctionRep}, lagertest.NewTestLogger("test"))
+
+			_, err := cellRepServer.Reset(context.Background(), &services.Empty{})
+			Expect(status.Code(err)).To(Equal(codes.Unimplemented))
+		})
+	})
+})
@@ -0,0 +1,47 @@
+package auction_grpc_server_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+	"google.golang.org/grpc/codes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("State", func() {
+	Context("when the state call succeeds", func() {
+		var repState auctiontypes.CellState
+		BeforeEach(func() {
+			repState = auctiontypes.CellState{
+				RootFSProviders: auctiontypes.RootFSProviders{"docker": auctiontypes.ArbitraryRootFSProvider{}},
+				Generation:      4,
+			}
+			auctionRep.StateReturns(repState, nil)
+			Expect(auctionRep.StateCallCount()).To(Equal(0))
+		})
+
+		It("it returns whatever the state call returns", func() {
+			var state auctiontypes.CellState
+			Expect(Invoke(services.State, &services.Empty{}, &state)).To(Equal(codes.OK))
+			Expect(state).To(Equal(repState))
+
+			Expect(auctionRep.StateCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the state call fails", func() {
+		It("fails", func() {
+			auctionRep.StateReturns(auctiontypes.CellState{}, errors.New("boom"))
+			Expect(auctionRep.StateCallCount()).To(Equal(0))
+
+			var state auctiontypes.CellState
+			Expect(Invoke(services.State, &services.Empty{}, &state)).To(Equal(codes.Internal))
+			Expect(state).To(BeZero())
+
+			Expect(auctionRep.StateCallCount()).To(Equal(1))
+		})
+	})
+})
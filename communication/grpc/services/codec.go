@@ -0,0 +1,339 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+)
+
+// CodecName is the content-subtype of the CellRep service's messages
+const CodecName = "auction-cellrep"
+
+// Codec encodes the CellRep service's messages in the protobuf wire format
+// described by cellrep.proto.  It is not registered with grpc: servers made
+// with NewServer and clients that pass CallOptions() use it, whatever the
+// other codecs in the process.
+type Codec struct{}
+
+func (Codec) Name() string {
+	return CodecName
+}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	switch message := v.(type) {
+	case *Empty:
+		return []byte{}, nil
+	case *auctiontypes.CellState:
+		return appendCellState(nil, *message), nil
+	case *auctiontypes.Work:
+		return appendWork(nil, *message)
+	}
+	return nil, fmt.Errorf("cannot marshal %T", v)
+}
+
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	switch message := v.(type) {
+	case *Empty:
+		return consumeFields(data, func(protowire.Number, uint64, []byte) error { return nil })
+	case *auctiontypes.CellState:
+		*message = auctiontypes.CellState{}
+		return consumeCellState(data, message)
+	case *auctiontypes.Work:
+		*message = auctiontypes.Work{}
+		return consumeWork(data, message)
+	}
+	return fmt.Errorf("cannot unmarshal %T", v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendInt(b []byte, num protowire.Number, v int) []byte {
+	return appendVarint(b, num, uint64(int64(v)))
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	return appendVarint(b, num, protowire.EncodeBool(v))
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// appendMessage appends the message that encode appends to its argument
+func appendMessage(b []byte, num protowire.Number, encode func([]byte) []byte) []byte {
+	return appendBytes(b, num, encode(nil))
+}
+
+// consumeFields calls field with the number and value of each of a message's
+// varint and length-delimited fields, and skips the rest
+func consumeFields(b []byte, field func(num protowire.Number, v uint64, bytes []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var v uint64
+		var bytes []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if typ != protowire.VarintType && typ != protowire.BytesType {
+			continue
+		}
+
+		err := field(num, v, bytes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendResources(b []byte, r auctiontypes.Resources) []byte {
+	b = appendInt(b, 1, r.MemoryMB)
+	b = appendInt(b, 2, r.DiskMB)
+	return appendInt(b, 3, r.Containers)
+}
+
+func consumeResources(b []byte, r *auctiontypes.Resources) error {
+	return consumeFields(b, func(num protowire.Number, v uint64, _ []byte) error {
+		switch num {
+		case 1:
+			r.MemoryMB = int(int64(v))
+		case 2:
+			r.DiskMB = int(int64(v))
+		case 3:
+			r.Containers = int(int64(v))
+		}
+		return nil
+	})
+}
+
+// appendRootFSProviders appends the providers sorted by scheme, and the
+// rootfses of fixed sets sorted, so that a state always encodes the same way
+func appendRootFSProviders(b []byte, num protowire.Number, providers auctiontypes.RootFSProviders) []byte {
+	schemes := []string{}
+	for scheme := range providers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	for _, scheme := range schemes {
+		provider := providers[scheme]
+		b = appendMessage(b, num, func(b []byte) []byte {
+			b = appendString(b, 1, scheme)
+			b = appendString(b, 2, string(provider.Type()))
+
+			fixedSet, ok := provider.(auctiontypes.FixedSetRootFSProvider)
+			if !ok {
+				return b
+			}
+			rootFSes := []string{}
+			for rootFS := range fixedSet.FixedSet {
+				rootFSes = append(rootFSes, rootFS)
+			}
+			sort.Strings(rootFSes)
+			for _, rootFS := range rootFSes {
+				b = appendBytes(b, 3, []byte(rootFS))
+			}
+			return b
+		})
+	}
+	return b
+}
+
+// consumeRootFSProvider adds the provider to providers.  Like the JSON
+// encoding, it drops providers of unknown types.
+func consumeRootFSProvider(b []byte, providers auctiontypes.RootFSProviders) error {
+	var scheme string
+	var providerType auctiontypes.RootFSProviderType
+	rootFSes := []string{}
+
+	err := consumeFields(b, func(num protowire.Number, _ uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			scheme = string(bytes)
+		case 2:
+			providerType = auctiontypes.RootFSProviderType(bytes)
+		case 3:
+			rootFSes = append(rootFSes, string(bytes))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch providerType {
+	case auctiontypes.RootFSProviderTypeArbitrary:
+		providers[scheme] = auctiontypes.ArbitraryRootFSProvider{}
+	case auctiontypes.RootFSProviderTypeFixedSet:
+		providers[scheme] = auctiontypes.NewFixedSetRootFSProvider(rootFSes...)
+	}
+	return nil
+}
+
+func appendLRP(b []byte, lrp auctiontypes.LRP) []byte {
+	b = appendString(b, 1, lrp.ProcessGuid)
+	b = appendInt(b, 2, lrp.Index)
+	b = appendInt(b, 3, lrp.MemoryMB)
+	return appendInt(b, 4, lrp.DiskMB)
+}
+
+func consumeLRP(b []byte, lrp *auctiontypes.LRP) error {
+	return consumeFields(b, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			lrp.ProcessGuid = string(bytes)
+		case 2:
+			lrp.Index = int(int64(v))
+		case 3:
+			lrp.MemoryMB = int(int64(v))
+		case 4:
+			lrp.DiskMB = int(int64(v))
+		}
+		return nil
+	})
+}
+
+func appendTask(b []byte, task auctiontypes.Task) []byte {
+	b = appendString(b, 1, task.TaskGuid)
+	b = appendInt(b, 2, task.MemoryMB)
+	return appendInt(b, 3, task.DiskMB)
+}
+
+func consumeTask(b []byte, task *auctiontypes.Task) error {
+	return consumeFields(b, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			task.TaskGuid = string(bytes)
+		case 2:
+			task.MemoryMB = int(int64(v))
+		case 3:
+			task.DiskMB = int(int64(v))
+		}
+		return nil
+	})
+}
+
+func appendCellState(b []byte, state auctiontypes.CellState) []byte {
+	b = appendRootFSProviders(b, 1, state.RootFSProviders)
+	b = appendMessage(b, 2, func(b []byte) []byte { return appendResources(b, state.AvailableResources) })
+	b = appendMessage(b, 3, func(b []byte) []byte { return appendResources(b, state.TotalResources) })
+	for _, lrp := range state.LRPs {
+		lrp := lrp
+		b = appendMessage(b, 4, func(b []byte) []byte { return appendLRP(b, lrp) })
+	}
+	for _, task := range state.Tasks {
+		task := task
+		b = appendMessage(b, 5, func(b []byte) []byte { return appendTask(b, task) })
+	}
+	b = appendString(b, 6, state.Zone)
+	b = appendBool(b, 7, state.Evacuating)
+	return appendVarint(b, 8, state.Generation)
+}
+
+func consumeCellState(b []byte, state *auctiontypes.CellState) error {
+	return consumeFields(b, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			if state.RootFSProviders == nil {
+				state.RootFSProviders = auctiontypes.RootFSProviders{}
+			}
+			return consumeRootFSProvider(bytes, state.RootFSProviders)
+		case 2:
+			return consumeResources(bytes, &state.AvailableResources)
+		case 3:
+			return consumeResources(bytes, &state.TotalResources)
+		case 4:
+			var lrp auctiontypes.LRP
+			err := consumeLRP(bytes, &lrp)
+			state.LRPs = append(state.LRPs, lrp)
+			return err
+		case 5:
+			var task auctiontypes.Task
+			err := consumeTask(bytes, &task)
+			state.Tasks = append(state.Tasks, task)
+			return err
+		case 6:
+			state.Zone = string(bytes)
+		case 7:
+			state.Evacuating = protowire.DecodeBool(v)
+		case 8:
+			state.Generation = v
+		}
+		return nil
+	})
+}
+
+// appendWork carries the LRPs and tasks as their JSON encodings: their
+// runtime-schema models are only defined in JSON
+func appendWork(b []byte, work auctiontypes.Work) ([]byte, error) {
+	for _, lrp := range work.LRPs {
+		payload, err := json.Marshal(lrp)
+		if err != nil {
+			return nil, err
+		}
+		b = appendBytes(b, 1, payload)
+	}
+	for _, task := range work.Tasks {
+		payload, err := json.Marshal(task)
+		if err != nil {
+			return nil, err
+		}
+		b = appendBytes(b, 2, payload)
+	}
+	return appendVarint(b, 3, work.Generation), nil
+}
+
+func consumeWork(b []byte, work *auctiontypes.Work) error {
+	return consumeFields(b, func(num protowire.Number, v uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			var lrp auctiontypes.LRPAuction
+			err := json.Unmarshal(bytes, &lrp)
+			work.LRPs = append(work.LRPs, lrp)
+			return err
+		case 2:
+			var task models.Task
+			err := json.Unmarshal(bytes, &task)
+			work.Tasks = append(work.Tasks, task)
+			return err
+		case 3:
+			work.Generation = v
+		}
+		return nil
+	})
+}
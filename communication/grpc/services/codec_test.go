@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/services"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Codec", func() {
+	var codec services.Codec
+
+	It("is named after the service rather than the encoding", func() {
+		Expect(codec.Name()).To(Equal("auction-cellrep"))
+	})
+
+	It("round-trips cell states", func() {
+		state := auctiontypes.CellState{
+			RootFSProviders: auctiontypes.RootFSProviders{
+				models.PreloadedRootFSScheme: auctiontypes.NewFixedSetRootFSProvider("lucid64", "cflinuxfs2"),
+				"docker":                     auctiontypes.ArbitraryRootFSProvider{},
+			},
+			AvailableResources: auctiontypes.Resources{MemoryMB: 10, DiskMB: 20, Containers: 30},
+			TotalResources:     auctiontypes.Resources{MemoryMB: 100, DiskMB: 200, Containers: 300},
+			LRPs: []auctiontypes.LRP{
+				{ProcessGuid: "pg-1", Index: 0, MemoryMB: 10, DiskMB: 20},
+				{ProcessGuid: "pg-1", Index: 1, MemoryMB: 10, DiskMB: 20},
+			},
+			Tasks:      []auctiontypes.Task{{TaskGuid: "tg-1", MemoryMB: 5, DiskMB: 6}},
+			Zone:       "z1",
+			Evacuating: true,
+			Generation: 7,
+		}
+
+		payload, err := codec.Marshal(&state)
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded auctiontypes.CellState
+		Expect(codec.Unmarshal(payload, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(state))
+	})
+
+	It("round-trips work", func() {
+		work := auctiontypes.Work{
+			LRPs: []auctiontypes.LRPAuction{
+				{DesiredLRP: models.DesiredLRP{ProcessGuid: "pg-1", MemoryMB: 10, DiskMB: 20}, Index: 1},
+			},
+			Tasks:      []models.Task{{TaskGuid: "tg-1", MemoryMB: 5, DiskMB: 6}},
+			Generation: 3,
+		}
+
+		payload, err := codec.Marshal(&work)
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded auctiontypes.Work
+		Expect(codec.Unmarshal(payload, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(work))
+	})
+
+	It("skips fields it doesn't know", func() {
+		payload, err := codec.Marshal(&auctiontypes.CellState{Zone: "z1"})
+		Expect(err).NotTo(HaveOccurred())
+		payload = protowire.AppendTag(payload, 99, protowire.Fixed64Type)
+		payload = protowire.AppendFixed64(payload, 1)
+		payload = protowire.AppendTag(payload, 98, protowire.BytesType)
+		payload = protowire.AppendString(payload, "from the future")
+
+		var decoded auctiontypes.CellState
+		Expect(codec.Unmarshal(payload, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(auctiontypes.CellState{Zone: "z1"}))
+	})
+
+	It("fails on truncated messages", func() {
+		payload, err := codec.Marshal(&auctiontypes.CellState{Zone: "z1"})
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded auctiontypes.CellState
+		Expect(codec.Unmarshal(payload[:len(payload)-1], &decoded)).NotTo(Succeed())
+	})
+
+	It("refuses messages that aren't the service's", func() {
+		_, err := codec.Marshal(&struct{}{})
+		Expect(err).To(HaveOccurred())
+	})
+})
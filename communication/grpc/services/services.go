@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+const (
+	ServiceName = "auction.CellRep"
+
+	State   = "State"
+	Perform = "Perform"
+
+	Sim_Reset = "Reset"
+)
+
+func FullMethod(method string) string {
+	return "/" + ServiceName + "/" + method
+}
+
+// NewServer returns a grpc server that encodes messages with Codec.
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	return grpc.NewServer(append([]grpc.ServerOption{grpc.ForceServerCodec(Codec{})}, opts...)...)
+}
+
+// CallOptions encode a call's messages with Codec.
+func CallOptions() []grpc.CallOption {
+	return []grpc.CallOption{grpc.ForceCodec(Codec{})}
+}
+
+type Empty struct{}
+
+type CellRepServer interface {
+	State(context.Context, *Empty) (*auctiontypes.CellState, error)
+	Perform(context.Context, *auctiontypes.Work) (*auctiontypes.Work, error)
+	Reset(context.Context, *Empty) (*Empty, error)
+}
+
+func RegisterCellRepServer(server *grpc.Server, cellRepServer CellRepServer) {
+	server.RegisterService(&serviceDesc, cellRepServer)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*CellRepServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: State, Handler: stateHandler},
+		{MethodName: Perform, Handler: performHandler},
+
+		{MethodName: Sim_Reset, Handler: resetHandler},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func stateHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &Empty{}
+	err := dec(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return intercept(ctx, in, interceptor, srv, State, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellRepServer).State(ctx, req.(*Empty))
+	})
+}
+
+func performHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &auctiontypes.Work{}
+	err := dec(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return intercept(ctx, in, interceptor, srv, Perform, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellRepServer).Perform(ctx, req.(*auctiontypes.Work))
+	})
+}
+
+func resetHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &Empty{}
+	err := dec(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return intercept(ctx, in, interceptor, srv, Sim_Reset, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellRepServer).Reset(ctx, req.(*Empty))
+	})
+}
+
+func intercept(ctx context.Context, in interface{}, interceptor grpc.UnaryServerInterceptor, srv interface{}, method string, handler grpc.UnaryHandler) (interface{}, error) {
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FullMethod(method),
+	}
+	return interceptor(ctx, in, info, handler)
+}
@@ -2,10 +2,14 @@ package auction_http_client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 
+	"github.com/cloudfoundry-incubator/auction/communication/http/content_encoding"
 	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
@@ -13,12 +17,21 @@ import (
 	"github.com/tedsuo/rata"
 )
 
+// ErrIncompatibleRep is returned instead of sending work to a rep that speaks
+// a different routes.ProtocolVersion.
+var ErrIncompatibleRep = errors.New("rep speaks an incompatible protocol version")
+
 type AuctionHTTPClient struct {
 	client           *http.Client
 	repGuid          string
 	address          string
 	requestGenerator *rata.RequestGenerator
 	logger           lager.Logger
+
+	// the version of the rep last found compatible, cleared whenever a
+	// request to the rep fails in case it has been replaced
+	peerVersion *routes.VersionInfo
+	lock        *sync.Mutex
 }
 
 type Response struct {
@@ -32,10 +45,15 @@ func New(client *http.Client, repGuid string, address string, logger lager.Logge
 		address:          address,
 		requestGenerator: rata.NewRequestGenerator(address, routes.Routes),
 		logger:           logger,
+		lock:             &sync.Mutex{},
 	}
 }
 
 func (c *AuctionHTTPClient) State() (auctiontypes.CellState, error) {
+	return c.StateContext(context.Background())
+}
+
+func (c *AuctionHTTPClient) StateContext(ctx context.Context) (auctiontypes.CellState, error) {
 	logger := c.logger.Session("fetching-state", lager.Data{
 		"rep": c.repGuid,
 	})
@@ -47,21 +65,25 @@ func (c *AuctionHTTPClient) State() (auctiontypes.CellState, error) {
 		logger.Error("failed-to-create-request", err)
 		return auctiontypes.CellState{}, err
 	}
+	req = req.WithContext(ctx)
+	content_encoding.Advertise(req.Header)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
 		logger.Error("failed-to-perform-request", err)
+		c.forgetPeerVersion()
 		return auctiontypes.CellState{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		logger.Error("invalid-status-code", fmt.Errorf("%d", resp.StatusCode))
+		c.forgetPeerVersion()
 		return auctiontypes.CellState{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	var state auctiontypes.CellState
-	err = json.NewDecoder(resp.Body).Decode(&state)
+	err = content_encoding.DecodeJSON(resp.Body, resp.Header, &state)
 	if err != nil {
 		logger.Error("failed-to-decode-rep-state", err)
 		return auctiontypes.CellState{}, err
@@ -73,6 +95,10 @@ func (c *AuctionHTTPClient) State() (auctiontypes.CellState, error) {
 }
 
 func (c *AuctionHTTPClient) Perform(work auctiontypes.Work) (auctiontypes.Work, error) {
+	return c.PerformContext(context.Background(), work)
+}
+
+func (c *AuctionHTTPClient) PerformContext(ctx context.Context, work auctiontypes.Work) (auctiontypes.Work, error) {
 	logger := c.logger.Session("sending-work", lager.Data{
 		"rep":    c.repGuid,
 		"starts": len(work.LRPs),
@@ -80,7 +106,17 @@ func (c *AuctionHTTPClient) Perform(work auctiontypes.Work) (auctiontypes.Work,
 
 	logger.Debug("requesting")
 
-	body, err := json.Marshal(work)
+	version, err := c.checkCompatibility(ctx, logger)
+	if err != nil {
+		return auctiontypes.Work{}, err
+	}
+
+	if !version.Supports(routes.FeatureGenerations) {
+		// the rep can't check the generation, so don't pretend it will
+		work.Generation = 0
+	}
+
+	body, encoding, err := content_encoding.EncodeJSON(work, version.Supports(routes.FeatureGzip))
 	if err != nil {
 		logger.Error("failed-to-marshal-work", err)
 		return auctiontypes.Work{}, err
@@ -91,21 +127,33 @@ func (c *AuctionHTTPClient) Perform(work auctiontypes.Work) (auctiontypes.Work,
 		logger.Error("failed-to-create-request", err)
 		return auctiontypes.Work{}, err
 	}
+	req = req.WithContext(ctx)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	content_encoding.Advertise(req.Header)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
 		logger.Error("failed-to-perform-request", err)
+		c.forgetPeerVersion()
 		return auctiontypes.Work{}, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusConflict {
+		logger.Error("stale-cell-state", auctiontypes.ErrorStaleCellState)
+		return auctiontypes.Work{}, auctiontypes.ErrorStaleCellState
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		logger.Error("invalid-status-code", fmt.Errorf("%d", resp.StatusCode))
+		c.forgetPeerVersion()
 		return auctiontypes.Work{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	var failedWork auctiontypes.Work
-	err = json.NewDecoder(resp.Body).Decode(&failedWork)
+	err = content_encoding.DecodeJSON(resp.Body, resp.Header, &failedWork)
 	if err != nil {
 		logger.Error("failed-to-decode-failed-work", err)
 		return auctiontypes.Work{}, err
@@ -116,6 +164,84 @@ func (c *AuctionHTTPClient) Perform(work auctiontypes.Work) (auctiontypes.Work,
 	return failedWork, nil
 }
 
+// Version asks the rep which protocol version and features it supports.
+func (c *AuctionHTTPClient) Version(ctx context.Context) (routes.VersionInfo, error) {
+	logger := c.logger.Session("fetching-version", lager.Data{
+		"rep": c.repGuid,
+	})
+
+	req, err := c.requestGenerator.CreateRequest(routes.Version, nil, nil)
+	if err != nil {
+		logger.Error("failed-to-create-request", err)
+		return routes.VersionInfo{}, err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("failed-to-perform-request", err)
+		return routes.VersionInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// reps that predate the version route
+		return routes.VersionInfo{ProtocolVersion: 1}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("invalid-status-code", fmt.Errorf("%d", resp.StatusCode))
+		return routes.VersionInfo{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var version routes.VersionInfo
+	err = json.NewDecoder(resp.Body).Decode(&version)
+	if err != nil {
+		logger.Error("failed-to-decode-version", err)
+		return routes.VersionInfo{}, err
+	}
+
+	return version, nil
+}
+
+// checkCompatibility asks the rep for its version the first time work is sent
+// to it, after each failed check in case the rep is upgraded, and after any
+// request to it fails in case another rep has taken its address.  It returns
+// the version, whose features say how work may be sent.
+func (c *AuctionHTTPClient) checkCompatibility(ctx context.Context, logger lager.Logger) (routes.VersionInfo, error) {
+	c.lock.Lock()
+	peerVersion := c.peerVersion
+	c.lock.Unlock()
+	if peerVersion != nil {
+		return *peerVersion, nil
+	}
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		logger.Error("failed-to-check-compatibility", err)
+		return routes.VersionInfo{}, err
+	}
+
+	if version.ProtocolVersion != routes.ProtocolVersion {
+		logger.Error("incompatible-rep", ErrIncompatibleRep, lager.Data{
+			"rep-protocol-version": version.ProtocolVersion,
+			"protocol-version":     routes.ProtocolVersion,
+		})
+		return routes.VersionInfo{}, ErrIncompatibleRep
+	}
+
+	c.lock.Lock()
+	c.peerVersion = &version
+	c.lock.Unlock()
+	return version, nil
+}
+
+func (c *AuctionHTTPClient) forgetPeerVersion() {
+	c.lock.Lock()
+	c.peerVersion = nil
+	c.lock.Unlock()
+}
+
 func (c *AuctionHTTPClient) Reset() error {
 	logger := c.logger.Session("SIM-reseting", lager.Data{
 		"rep": c.repGuid,
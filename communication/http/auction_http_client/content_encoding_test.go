@@ -0,0 +1,115 @@
+package auction_http_client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content encoding", func() {
+	var work auctiontypes.Work
+	var state auctiontypes.CellState
+
+	BeforeEach(func() {
+		work = auctiontypes.Work{}
+		state = auctiontypes.CellState{
+			RootFSProviders: auctiontypes.RootFSProviders{"docker": auctiontypes.ArbitraryRootFSProvider{}},
+		}
+		for i := 0; i < 100; i++ {
+			work.Tasks = append(work.Tasks, models.Task{TaskGuid: fmt.Sprintf("tg-%d", i)})
+			state.Tasks = append(state.Tasks, auctiontypes.Task{TaskGuid: fmt.Sprintf("tg-%d", i)})
+		}
+	})
+
+	Context("with a rep that accepts gzip", func() {
+		var gzipServer *httptest.Server
+		var gzipClient auctiontypes.SimulationCellRep
+		var encodings map[string]string
+
+		BeforeEach(func() {
+			logger := lagertest.NewTestLogger("test")
+			handler, err := rata.NewRouter(routes.Routes, auction_http_handlers.New(auctionRep, logger))
+			Expect(err).NotTo(HaveOccurred())
+
+			encodings = map[string]string{}
+			gzipServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encodings[r.URL.Path] = r.Header.Get("Content-Encoding")
+				handler.ServeHTTP(w, r)
+			}))
+			gzipClient = auction_http_client.New(&http.Client{}, "rep-guid", gzipServer.URL, logger)
+
+			auctionRep.StateReturns(state, nil)
+			auctionRep.PerformReturns(work, nil)
+		})
+
+		AfterEach(func() {
+			gzipServer.Close()
+		})
+
+		It("compresses work once the rep has advertised gzip", func() {
+			Expect(gzipClient.Perform(work)).To(Equal(work))
+
+			Expect(encodings["/work"]).To(Equal("gzip"))
+			Expect(auctionRep.PerformArgsForCall(0)).To(Equal(work))
+		})
+
+		It("compresses its responses to state requests", func() {
+			Expect(gzipClient.State()).To(Equal(state))
+		})
+	})
+
+	Context("with a rep that only speaks plain JSON", func() {
+		var oldServer *ghttp.Server
+		var oldClient auctiontypes.SimulationCellRep
+
+		BeforeEach(func() {
+			oldServer = ghttp.NewServer()
+			oldClient = auction_http_client.New(&http.Client{}, "rep-guid", oldServer.URL(), lagertest.NewTestLogger("test"))
+
+			oldServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/state"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, state),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/version"),
+					ghttp.RespondWith(http.StatusNotFound, nil),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/work"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header.Get("Content-Encoding")).To(BeEmpty())
+
+						var receivedWork auctiontypes.Work
+						Expect(json.NewDecoder(r.Body).Decode(&receivedWork)).To(Succeed())
+						Expect(receivedWork).To(Equal(work))
+					},
+					ghttp.RespondWithJSONEncoded(http.StatusOK, auctiontypes.Work{}),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			oldServer.Close()
+		})
+
+		It("sends plain JSON", func() {
+			Expect(oldClient.State()).To(Equal(state))
+			Expect(oldClient.Perform(work)).To(BeZero())
+			Expect(oldServer.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+})
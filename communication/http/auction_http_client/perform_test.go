@@ -1,6 +1,7 @@
 package auction_http_client_test
 
 import (
+	"context"
 	"errors"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
@@ -62,6 +63,18 @@ var _ = Describe("Perform", func() {
 		})
 	})
 
+	Context("when the rep rejects the work as stale", func() {
+		BeforeEach(func() {
+			auctionRep.PerformReturns(auctiontypes.Work{}, auctiontypes.ErrorStaleCellState)
+		})
+
+		It("should return ErrorStaleCellState", func() {
+			failedWork, err := client.Perform(work)
+			Expect(failedWork).To(BeZero())
+			Expect(err).To(Equal(auctiontypes.ErrorStaleCellState))
+		})
+	})
+
 	Context("when a request errors (in the network sense)", func() {
 		It("should error", func() {
 			failedWork, err := clientForServerThatErrors.Perform(work)
@@ -69,4 +82,16 @@ var _ = Describe("Perform", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Context("when the context is canceled", func() {
+		It("should error without telling the rep to perform", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			failedWork, err := client.(auctiontypes.ContextCellRep).PerformContext(ctx, work)
+			Expect(failedWork).To(BeZero())
+			Expect(err).To(HaveOccurred())
+			Expect(auctionRep.PerformCallCount()).To(Equal(0))
+		})
+	})
 })
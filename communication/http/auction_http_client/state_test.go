@@ -1,6 +1,7 @@
 package auction_http_client_test
 
 import (
+	"context"
 	"errors"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
@@ -52,4 +53,16 @@ var _ = Describe("State", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Context("when the context is canceled", func() {
+		It("should error without asking the rep", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			state, err := client.(auctiontypes.ContextCellRep).StateContext(ctx)
+			Expect(state).To(BeZero())
+			Expect(err).To(HaveOccurred())
+			Expect(auctionRep.StateCallCount()).To(Equal(0))
+		})
+	})
 })
@@ -0,0 +1,144 @@
+package auction_http_client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version", func() {
+	It("fetches the rep's version", func() {
+		version, err := client.(*auction_http_client.AuctionHTTPClient).Version(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version.ProtocolVersion).To(Equal(routes.ProtocolVersion))
+		Expect(version.Features).To(Equal(routes.Features))
+	})
+
+	Context("with a rep that speaks another protocol version", func() {
+		var futureServer *ghttp.Server
+		var futureClient *auction_http_client.AuctionHTTPClient
+
+		BeforeEach(func() {
+			futureServer = ghttp.NewServer()
+			futureServer.RouteToHandler("GET", "/version", ghttp.RespondWithJSONEncoded(http.StatusOK, routes.VersionInfo{
+				ProtocolVersion: routes.ProtocolVersion + 1,
+			}))
+			futureClient = auction_http_client.New(&http.Client{}, "rep-guid", futureServer.URL(), lagertest.NewTestLogger("test"))
+		})
+
+		AfterEach(func() {
+			futureServer.Close()
+		})
+
+		It("refuses to send it work", func() {
+			_, err := futureClient.Perform(auctiontypes.Work{})
+			Expect(err).To(Equal(auction_http_client.ErrIncompatibleRep))
+			Expect(futureServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("with a rep that doesn't advertise generations", func() {
+		var oldServer *ghttp.Server
+		var oldClient *auction_http_client.AuctionHTTPClient
+		var received auctiontypes.Work
+
+		BeforeEach(func() {
+			oldServer = ghttp.NewServer()
+			oldServer.RouteToHandler("GET", "/version", ghttp.RespondWithJSONEncoded(http.StatusOK, routes.VersionInfo{
+				ProtocolVersion: routes.ProtocolVersion,
+			}))
+			oldServer.RouteToHandler("POST", "/work", ghttp.CombineHandlers(
+				func(w http.ResponseWriter, r *http.Request) {
+					Expect(r.Header.Get("Content-Encoding")).To(BeEmpty())
+					Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+				},
+				ghttp.RespondWithJSONEncoded(http.StatusOK, auctiontypes.Work{}),
+			))
+			oldClient = auction_http_client.New(&http.Client{}, "rep-guid", oldServer.URL(), lagertest.NewTestLogger("test"))
+		})
+
+		AfterEach(func() {
+			oldServer.Close()
+		})
+
+		It("sends it plain work without a generation to check", func() {
+			_, err := oldClient.Perform(auctiontypes.Work{Generation: 3})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received.Generation).To(BeZero())
+		})
+	})
+
+	It("checks compatibility only once", func() {
+		countingServer := ghttp.NewServer()
+		defer countingServer.Close()
+		countingServer.RouteToHandler("GET", "/version", ghttp.RespondWithJSONEncoded(http.StatusOK, routes.VersionInfo{
+			ProtocolVersion: routes.ProtocolVersion,
+		}))
+		countingServer.RouteToHandler("POST", "/work", ghttp.RespondWithJSONEncoded(http.StatusOK, auctiontypes.Work{}))
+		countingClient := auction_http_client.New(&http.Client{}, "rep-guid", countingServer.URL(), lagertest.NewTestLogger("test"))
+
+		_, err := countingClient.Perform(auctiontypes.Work{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = countingClient.Perform(auctiontypes.Work{})
+		Expect(err).NotTo(HaveOccurred())
+
+		paths := []string{}
+		for _, request := range countingServer.ReceivedRequests() {
+			paths = append(paths, request.URL.Path)
+		}
+		Expect(paths).To(Equal([]string{"/version", "/work", "/work"}))
+	})
+
+	Context("when a request to the rep fails", func() {
+		var versions []routes.VersionInfo
+		var versionServer *ghttp.Server
+		var versionClient *auction_http_client.AuctionHTTPClient
+
+		BeforeEach(func() {
+			versions = []routes.VersionInfo{
+				{ProtocolVersion: routes.ProtocolVersion},
+				{ProtocolVersion: routes.ProtocolVersion + 1},
+			}
+
+			versionServer = ghttp.NewServer()
+			versionServer.RouteToHandler("GET", "/version", func(w http.ResponseWriter, r *http.Request) {
+				version := versions[0]
+				versions = versions[1:]
+				ghttp.RespondWithJSONEncoded(http.StatusOK, version)(w, r)
+			})
+			versionClient = auction_http_client.New(&http.Client{}, "rep-guid", versionServer.URL(), lagertest.NewTestLogger("test"))
+		})
+
+		AfterEach(func() {
+			versionServer.Close()
+		})
+
+		It("checks compatibility again, in case another rep has taken its address", func() {
+			versionServer.RouteToHandler("POST", "/work", ghttp.RespondWithJSONEncoded(http.StatusOK, auctiontypes.Work{}))
+			_, err := versionClient.Perform(auctiontypes.Work{})
+			Expect(err).NotTo(HaveOccurred())
+
+			versionServer.RouteToHandler("GET", "/state", ghttp.RespondWith(http.StatusInternalServerError, nil))
+			_, err = versionClient.State()
+			Expect(err).To(HaveOccurred())
+
+			_, err = versionClient.Perform(auctiontypes.Work{})
+			Expect(err).To(Equal(auction_http_client.ErrIncompatibleRep))
+
+			paths := []string{}
+			for _, request := range versionServer.ReceivedRequests() {
+				paths = append(paths, request.URL.Path)
+			}
+			Expect(paths).To(Equal([]string{"/version", "/work", "/state", "/version"}))
+		})
+	})
+})
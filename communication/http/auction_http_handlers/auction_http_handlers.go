@@ -12,6 +12,10 @@ func New(rep auctiontypes.CellRep, logger lager.Logger) rata.Handlers {
 		routes.State:   &state{rep: rep, logger: logger},
 		routes.Perform: &perform{rep: rep, logger: logger},
 
+		routes.Ping:    &ping{},
+		routes.Ready:   &ready{rep: rep, logger: logger},
+		routes.Version: &version{logger: logger},
+
 		routes.Sim_Reset: &reset{rep: rep, logger: logger},
 	}
 
@@ -0,0 +1,57 @@
+package auction_http_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/pivotal-golang/lager"
+)
+
+type ping struct{}
+
+func (h *ping) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+type ready struct {
+	rep    auctiontypes.CellRep
+	logger lager.Logger
+}
+
+func (h *ready) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	readinessRep, ok := h.rep.(auctiontypes.ReadinessReporter)
+	if !ok {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	err := readinessRep.Ready()
+	if err != nil {
+		h.logger.Session("ready").Info("not-ready", lager.Data{"reason": err.Error()})
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type version struct {
+	logger lager.Logger
+}
+
+func (h *version) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload, err := json.Marshal(routes.VersionInfo{
+		ProtocolVersion: routes.ProtocolVersion,
+		Features:        routes.Features,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.logger.Session("version").Error("failed-to-marshal", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(payload)
+}
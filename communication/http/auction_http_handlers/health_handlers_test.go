@@ -0,0 +1,85 @@
+package auction_http_handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health Handlers", func() {
+	Describe("Ping", func() {
+		It("succeeds without asking the rep for anything", func() {
+			status, _ := Request(routes.Ping, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(auctionRep.StateCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("Ready", func() {
+		It("succeeds for reps that do not report readiness", func() {
+			status, _ := Request(routes.Ready, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+		})
+
+		Context("when the rep reports readiness", func() {
+			var rep *readinessRep
+			var readyServer *httptest.Server
+
+			BeforeEach(func() {
+				rep = &readinessRep{FakeSimulationCellRep: &fakes.FakeSimulationCellRep{}}
+				handler, err := rata.NewRouter(routes.Routes, auction_http_handlers.New(rep, lagertest.NewTestLogger("test")))
+				Expect(err).NotTo(HaveOccurred())
+				readyServer = httptest.NewServer(handler)
+			})
+
+			AfterEach(func() {
+				readyServer.Close()
+			})
+
+			It("reflects whether the rep is ready", func() {
+				resp, err := http.Get(readyServer.URL + "/ready")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				rep.notReady = errors.New("evacuating")
+				resp, err = http.Get(readyServer.URL + "/ready")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			})
+		})
+	})
+
+	Describe("Version", func() {
+		It("reports the protocol version and features", func() {
+			status, body := Request(routes.Version, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var version routes.VersionInfo
+			Expect(json.Unmarshal(body, &version)).To(Succeed())
+			Expect(version.ProtocolVersion).To(Equal(routes.ProtocolVersion))
+			Expect(version.Supports(routes.FeatureGzip)).To(BeTrue())
+			Expect(version.Supports(routes.FeatureGenerations)).To(BeTrue())
+		})
+	})
+})
+
+type readinessRep struct {
+	*fakes.FakeSimulationCellRep
+	notReady error
+}
+
+func (r *readinessRep) Ready() error {
+	return r.notReady
+}
@@ -1,10 +1,10 @@
 package auction_http_handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/content_encoding"
 	"github.com/pivotal-golang/lager"
 )
 
@@ -16,9 +16,10 @@ type perform struct {
 func (h *perform) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logger := h.logger.Session("auction-perform-work")
 	logger.Info("handling")
+	content_encoding.Advertise(w.Header())
 
 	var work auctiontypes.Work
-	err := json.NewDecoder(r.Body).Decode(&work)
+	err := content_encoding.DecodeJSON(r.Body, r.Header, &work)
 
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -26,13 +27,22 @@ func (h *perform) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	failedWork, err := h.rep.Perform(work)
+	failedWork, err := auctiontypes.PerformWithContext(r.Context(), h.rep, work)
+	if err == auctiontypes.ErrorStaleCellState {
+		w.WriteHeader(http.StatusConflict)
+		logger.Error("stale-cell-state", err)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error("failed-to-perform-work", err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(failedWork)
+	err = content_encoding.WriteJSON(w, r, failedWork)
+	if err != nil {
+		logger.Error("failed-to-write-failed-work", err)
+		return
+	}
 	logger.Info("success")
 }
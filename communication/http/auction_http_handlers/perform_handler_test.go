@@ -3,9 +3,11 @@ package auction_http_handlers_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/content_encoding"
 	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
 	"github.com/cloudfoundry-incubator/runtime-schema/models"
 
@@ -72,6 +74,45 @@ var _ = Describe("Perform", func() {
 		})
 	})
 
+	Context("with gzipped JSON", func() {
+		It("decompresses the work", func() {
+			requestedWork := auctiontypes.Work{}
+			for i := 0; i < 100; i++ {
+				requestedWork.Tasks = append(requestedWork.Tasks, models.Task{TaskGuid: fmt.Sprintf("tg-%d", i)})
+			}
+
+			body, encoding, err := content_encoding.EncodeJSON(requestedWork, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoding).To(Equal("gzip"))
+
+			request, err := requestGenerator.CreateRequest(routes.Perform, nil, bytes.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Content-Encoding", encoding)
+
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(auctionRep.PerformArgsForCall(0)).To(Equal(requestedWork))
+		})
+	})
+
+	Context("when the work is stale", func() {
+		BeforeEach(func() {
+			auctionRep.PerformReturns(auctiontypes.Work{}, auctiontypes.ErrorStaleCellState)
+		})
+
+		It("fails with a conflict, returning nothing", func() {
+			status, body := Request(routes.Perform, nil, JSONReaderFor(auctiontypes.Work{Generation: 3}))
+			Expect(status).To(Equal(http.StatusConflict))
+			Expect(body).To(BeEmpty())
+
+			Expect(auctionRep.PerformCallCount()).To(Equal(1))
+			Expect(auctionRep.PerformArgsForCall(0).Generation).To(BeEquivalentTo(3))
+		})
+	})
+
 	Context("with invalid JSON", func() {
 		It("fails", func() {
 			Expect(auctionRep.PerformCallCount()).To(Equal(0))
@@ -1,10 +1,10 @@
 package auction_http_handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/content_encoding"
 	"github.com/pivotal-golang/lager"
 )
 
@@ -16,14 +16,19 @@ type state struct {
 func (h *state) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logger := h.logger.Session("auction-fetch-state")
 	logger.Info("handling")
+	content_encoding.Advertise(w.Header())
 
-	state, err := h.rep.State()
+	state, err := auctiontypes.StateWithContext(r.Context(), h.rep)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error("failed-to-fetch-state", err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(state)
+	err = content_encoding.WriteJSON(w, r, state)
+	if err != nil {
+		logger.Error("failed-to-write-state", err)
+		return
+	}
 	logger.Info("success")
 }
@@ -2,9 +2,11 @@ package auction_http_handlers_test
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/content_encoding"
 	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
 
 	. "github.com/onsi/ginkgo"
@@ -44,4 +46,35 @@ var _ = Describe("State", func() {
 			Expect(auctionRep.StateCallCount()).To(Equal(1))
 		})
 	})
+
+	Context("when the client accepts gzip", func() {
+		var repState auctiontypes.CellState
+		BeforeEach(func() {
+			repState = auctiontypes.CellState{
+				RootFSProviders: auctiontypes.RootFSProviders{"docker": auctiontypes.ArbitraryRootFSProvider{}},
+			}
+			for i := 0; i < 100; i++ {
+				repState.LRPs = append(repState.LRPs, auctiontypes.LRP{ProcessGuid: fmt.Sprintf("pg-%d", i)})
+			}
+			auctionRep.StateReturns(repState, nil)
+		})
+
+		It("compresses the state and advertises that it accepts gzip", func() {
+			request, err := requestGenerator.CreateRequest(routes.State, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Accept-Encoding", "gzip")
+
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(response.Header.Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(response.Header.Get("Accept-Encoding")).To(Equal("gzip"))
+
+			var state auctiontypes.CellState
+			Expect(content_encoding.DecodeJSON(response.Body, response.Header, &state)).To(Succeed())
+			Expect(state).To(Equal(repState))
+		})
+	})
 })
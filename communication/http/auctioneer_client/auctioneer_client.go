@@ -0,0 +1,123 @@
+package auctioneer_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_routes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+)
+
+// ErrResultNotFound is returned for auctions that have not completed, or
+// whose results the auctioneer no longer remembers.
+var ErrResultNotFound = errors.New("auction result not found")
+
+type AuctioneerClient struct {
+	client           *http.Client
+	requestGenerator *rata.RequestGenerator
+	logger           lager.Logger
+}
+
+func New(client *http.Client, address string, logger lager.Logger) *AuctioneerClient {
+	return &AuctioneerClient{
+		client:           client,
+		requestGenerator: rata.NewRequestGenerator(address, auctioneer_routes.Routes),
+		logger:           logger,
+	}
+}
+
+func (c *AuctioneerClient) SubmitLRPAuctions(starts []models.LRPStartRequest) error {
+	logger := c.logger.Session("submitting-lrp-auctions", lager.Data{"lrp-starts": len(starts)})
+	return c.do(logger, auctioneer_routes.SubmitLRPAuctions, nil, starts, http.StatusAccepted, nil, nil)
+}
+
+func (c *AuctioneerClient) SubmitTaskAuctions(tasks []models.Task) error {
+	logger := c.logger.Session("submitting-task-auctions", lager.Data{"tasks": len(tasks)})
+	return c.do(logger, auctioneer_routes.SubmitTaskAuctions, nil, tasks, http.StatusAccepted, nil, nil)
+}
+
+func (c *AuctioneerClient) QueueStatus() (auctiontypes.QueueStatus, error) {
+	logger := c.logger.Session("fetching-queue-status")
+
+	var status auctiontypes.QueueStatus
+	err := c.do(logger, auctioneer_routes.QueueStatus, nil, nil, http.StatusOK, nil, &status)
+	return status, err
+}
+
+func (c *AuctioneerClient) LRPResult(processGuid string, index int) (auctiontypes.LRPAuctionResult, error) {
+	logger := c.logger.Session("fetching-lrp-result", lager.Data{"process-guid": processGuid, "index": index})
+
+	var result auctiontypes.LRPAuctionResult
+	params := rata.Params{"process_guid": processGuid, "index": strconv.Itoa(index)}
+	err := c.do(logger, auctioneer_routes.LRPResult, params, nil, http.StatusOK, ErrResultNotFound, &result)
+	return result, err
+}
+
+func (c *AuctioneerClient) TaskResult(taskGuid string) (auctiontypes.TaskAuctionResult, error) {
+	logger := c.logger.Session("fetching-task-result", lager.Data{"task-guid": taskGuid})
+
+	var result auctiontypes.TaskAuctionResult
+	err := c.do(logger, auctioneer_routes.TaskResult, rata.Params{"task_guid": taskGuid}, nil, http.StatusOK, ErrResultNotFound, &result)
+	return result, err
+}
+
+// do sends payload to route and decodes the response.  A 404 is returned as
+// notFound, if it is set: only the result routes answer 404 for things they
+// don't know about, so elsewhere it means the address is wrong.
+func (c *AuctioneerClient) do(logger lager.Logger, route string, params rata.Params, payload interface{}, expectedStatus int, notFound error, response interface{}) error {
+	logger.Debug("requesting")
+
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			logger.Error("failed-to-marshal", err)
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := c.requestGenerator.CreateRequest(route, params, body)
+	if err != nil {
+		logger.Error("failed-to-create-request", err)
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("failed-to-perform-request", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && notFound != nil {
+		return notFound
+	}
+
+	if resp.StatusCode != expectedStatus {
+		logger.Error("invalid-status-code", fmt.Errorf("%d", resp.StatusCode))
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if response != nil {
+		err = json.NewDecoder(resp.Body).Decode(response)
+		if err != nil {
+			logger.Error("failed-to-decode", err)
+			return err
+		}
+	}
+
+	logger.Debug("done")
+	return nil
+}
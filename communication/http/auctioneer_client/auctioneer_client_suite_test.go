@@ -0,0 +1,54 @@
+package auctioneer_client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_routes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	"testing"
+)
+
+func TestAuctioneerClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AuctioneerClient Suite")
+}
+
+var runner *fakes.FakeAuctionRunner
+var queue *fakes.FakeQueueStatusReporter
+var history *auctionrunner.ResultHistory
+var server *httptest.Server
+var client *auctioneer_client.AuctioneerClient
+
+var _ = BeforeEach(func() {
+	logger := lagertest.NewTestLogger("test")
+
+	runner = &fakes.FakeAuctionRunner{}
+	queue = &fakes.FakeQueueStatusReporter{}
+	history = auctionrunner.NewResultHistory(nopDelegate{}, 10)
+
+	handler, err := rata.NewRouter(auctioneer_routes.Routes, auctioneer_handlers.New(runner, queue, history, logger))
+	Expect(err).NotTo(HaveOccurred())
+	server = httptest.NewServer(handler)
+
+	client = auctioneer_client.New(&http.Client{}, server.URL, logger)
+})
+
+var _ = AfterEach(func() {
+	server.Close()
+})
+
+type nopDelegate struct{}
+
+func (nopDelegate) FetchCellReps() (map[string]auctiontypes.CellRep, error) { return nil, nil }
+func (nopDelegate) AuctionCompleted(auctiontypes.AuctionResults)            {}
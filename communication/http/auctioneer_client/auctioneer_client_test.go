@@ -0,0 +1,110 @@
+package auctioneer_client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_client"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuctioneerClient", func() {
+	Describe("submitting auctions", func() {
+		It("schedules LRP start auctions on the runner", func() {
+			starts := []models.LRPStartRequest{{
+				DesiredLRP: models.DesiredLRP{ProcessGuid: "pg-1", MemoryMB: 10, DiskMB: 10},
+				Indices:    []uint{0, 1},
+			}}
+
+			Expect(client.SubmitLRPAuctions(starts)).To(Succeed())
+			Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+			Expect(runner.ScheduleLRPsForAuctionsArgsForCall(0)).To(Equal(starts))
+		})
+
+		It("schedules task auctions on the runner", func() {
+			tasks := []models.Task{{TaskGuid: "tg-1", MemoryMB: 10, DiskMB: 10}}
+
+			Expect(client.SubmitTaskAuctions(tasks)).To(Succeed())
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+			Expect(runner.ScheduleTasksForAuctionsArgsForCall(0)).To(Equal(tasks))
+		})
+	})
+
+	Describe("QueueStatus", func() {
+		It("reports the queue", func() {
+			queue.QueueStatusReturns(auctiontypes.QueueStatus{LRPAuctions: 3, TaskAuctions: 1})
+
+			status, err := client.QueueStatus()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(auctiontypes.QueueStatus{LRPAuctions: 3, TaskAuctions: 1}))
+		})
+	})
+
+	Describe("looking up results", func() {
+		BeforeEach(func() {
+			history.AuctionCompleted(auctiontypes.AuctionResults{
+				SuccessfulLRPs: []auctiontypes.LRPAuction{{
+					DesiredLRP:    models.DesiredLRP{ProcessGuid: "pg-1"},
+					Index:         1,
+					AuctionRecord: auctiontypes.AuctionRecord{Winner: "cell-a", Attempts: 1},
+				}},
+				FailedTasks: []auctiontypes.TaskAuction{{
+					Task:          models.Task{TaskGuid: "tg-1"},
+					AuctionRecord: auctiontypes.AuctionRecord{Attempts: 2, PlacementError: "insufficient resources"},
+				}},
+			})
+		})
+
+		It("returns the result of an LRP auction", func() {
+			result, err := client.LRPResult("pg-1", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Outcome).To(Equal(auctiontypes.AuctionSucceeded))
+			Expect(result.Auction.Winner).To(Equal("cell-a"))
+		})
+
+		It("returns the result of a task auction", func() {
+			result, err := client.TaskResult("tg-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Outcome).To(Equal(auctiontypes.AuctionFailed))
+			Expect(result.Auction.PlacementError).To(Equal("insufficient resources"))
+		})
+
+		It("returns ErrResultNotFound for unknown auctions", func() {
+			_, err := client.LRPResult("pg-1", 0)
+			Expect(err).To(Equal(auctioneer_client.ErrResultNotFound))
+
+			_, err = client.TaskResult("tg-unknown")
+			Expect(err).To(Equal(auctioneer_client.ErrResultNotFound))
+		})
+	})
+
+	Context("with an address that doesn't serve the auctioneer's routes", func() {
+		var missingServer *httptest.Server
+		var missingClient *auctioneer_client.AuctioneerClient
+
+		BeforeEach(func() {
+			missingServer = httptest.NewServer(http.NotFoundHandler())
+			missingClient = auctioneer_client.New(&http.Client{}, missingServer.URL, lagertest.NewTestLogger("test"))
+		})
+
+		AfterEach(func() {
+			missingServer.Close()
+		})
+
+		It("reports the unexpected status rather than a missing result", func() {
+			err := missingClient.SubmitLRPAuctions([]models.LRPStartRequest{})
+			Expect(err).To(MatchError("unexpected status code: 404"))
+
+			err = missingClient.SubmitTaskAuctions([]models.Task{})
+			Expect(err).To(MatchError("unexpected status code: 404"))
+
+			_, err = missingClient.QueueStatus()
+			Expect(err).To(MatchError("unexpected status code: 404"))
+		})
+	})
+})
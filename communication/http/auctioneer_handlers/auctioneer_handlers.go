@@ -0,0 +1,138 @@
+package auctioneer_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_routes"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+)
+
+// Results is satisfied by auctionrunner.ResultHistory.
+type Results interface {
+	LRPResult(identifier string) (auctiontypes.LRPAuctionResult, bool)
+	TaskResult(identifier string) (auctiontypes.TaskAuctionResult, bool)
+}
+
+// New serves auctions to runner.  The queue is usually runner too, as the
+// runners from auctionrunner report their queues.
+func New(runner auctiontypes.AuctionRunner, queue auctiontypes.QueueStatusReporter, results Results, logger lager.Logger) rata.Handlers {
+	handlers := rata.Handlers{
+		auctioneer_routes.SubmitLRPAuctions:  &submitLRPAuctions{runner: runner, logger: logger},
+		auctioneer_routes.SubmitTaskAuctions: &submitTaskAuctions{runner: runner, logger: logger},
+		auctioneer_routes.QueueStatus:        &queueStatus{queue: queue, logger: logger},
+		auctioneer_routes.LRPResult:          &lrpResult{results: results, logger: logger},
+		auctioneer_routes.TaskResult:         &taskResult{results: results, logger: logger},
+	}
+
+	return handlers
+}
+
+type submitLRPAuctions struct {
+	runner auctiontypes.AuctionRunner
+	logger lager.Logger
+}
+
+func (h *submitLRPAuctions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("submit-lrp-auctions")
+
+	var starts []models.LRPStartRequest
+	err := json.NewDecoder(r.Body).Decode(&starts)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error("failed-to-unmarshal", err)
+		return
+	}
+
+	h.runner.ScheduleLRPsForAuctions(starts)
+	logger.Info("submitted", lager.Data{"lrp-starts": len(starts)})
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type submitTaskAuctions struct {
+	runner auctiontypes.AuctionRunner
+	logger lager.Logger
+}
+
+func (h *submitTaskAuctions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("submit-task-auctions")
+
+	var tasks []models.Task
+	err := json.NewDecoder(r.Body).Decode(&tasks)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error("failed-to-unmarshal", err)
+		return
+	}
+
+	h.runner.ScheduleTasksForAuctions(tasks)
+	logger.Info("submitted", lager.Data{"tasks": len(tasks)})
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type queueStatus struct {
+	queue  auctiontypes.QueueStatusReporter
+	logger lager.Logger
+}
+
+func (h *queueStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.queue.QueueStatus(), h.logger.Session("queue-status"))
+}
+
+type lrpResult struct {
+	results Results
+	logger  lager.Logger
+}
+
+func (h *lrpResult) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("lrp-result")
+
+	index, err := strconv.Atoi(r.FormValue(":index"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error("invalid-index", err)
+		return
+	}
+
+	result, ok := h.results.LRPResult(auctiontypes.IdentifierForLRP(r.FormValue(":process_guid"), index))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, result, logger)
+}
+
+type taskResult struct {
+	results Results
+	logger  lager.Logger
+}
+
+func (h *taskResult) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result, ok := h.results.TaskResult(r.FormValue(":task_guid"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, result, h.logger.Session("task-result"))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}, logger lager.Logger) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-marshal", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(payload)
+}
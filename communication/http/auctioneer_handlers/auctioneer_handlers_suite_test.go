@@ -0,0 +1,78 @@
+package auctioneer_handlers_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_routes"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/rata"
+
+	"testing"
+)
+
+func TestAuctioneerHandlers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AuctioneerHandlers Suite")
+}
+
+var server *httptest.Server
+var requestGenerator *rata.RequestGenerator
+var runner *fakes.FakeAuctionRunner
+var queue *fakes.FakeQueueStatusReporter
+var results *fakeResults
+
+var _ = BeforeEach(func() {
+	logger := lagertest.NewTestLogger("auctioneer_handlers")
+
+	runner = &fakes.FakeAuctionRunner{}
+	queue = &fakes.FakeQueueStatusReporter{}
+	results = &fakeResults{}
+
+	handler, err := rata.NewRouter(auctioneer_routes.Routes, auctioneer_handlers.New(runner, queue, results, logger))
+	Expect(err).NotTo(HaveOccurred())
+	server = httptest.NewServer(handler)
+
+	requestGenerator = rata.NewRequestGenerator(server.URL, auctioneer_routes.Routes)
+})
+
+var _ = AfterEach(func() {
+	server.Close()
+})
+
+func Request(name string, params rata.Params, body io.Reader) (statusCode int, responseBody []byte) {
+	request, err := requestGenerator.CreateRequest(name, params, body)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	response, err := http.DefaultClient.Do(request)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	responseBody, err = ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	return response.StatusCode, responseBody
+}
+
+type fakeResults struct {
+	lookups []string
+}
+
+func (f *fakeResults) LRPResult(identifier string) (auctiontypes.LRPAuctionResult, bool) {
+	f.lookups = append(f.lookups, identifier)
+	return auctiontypes.LRPAuctionResult{}, false
+}
+
+func (f *fakeResults) TaskResult(identifier string) (auctiontypes.TaskAuctionResult, bool) {
+	f.lookups = append(f.lookups, identifier)
+	return auctiontypes.TaskAuctionResult{}, false
+}
@@ -0,0 +1,75 @@
+package auctioneer_handlers_test
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auctioneer_routes"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuctioneerHandlers", func() {
+	Describe("SubmitLRPAuctions", func() {
+		It("schedules the LRP starts", func() {
+			status, _ := Request(auctioneer_routes.SubmitLRPAuctions, nil, bytes.NewBufferString(`[{"indices":[0]}]`))
+			Expect(status).To(Equal(http.StatusAccepted))
+			Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+		})
+
+		It("rejects invalid JSON", func() {
+			status, _ := Request(auctioneer_routes.SubmitLRPAuctions, nil, bytes.NewBufferString("{"))
+			Expect(status).To(Equal(http.StatusBadRequest))
+			Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("SubmitTaskAuctions", func() {
+		It("schedules the tasks", func() {
+			status, _ := Request(auctioneer_routes.SubmitTaskAuctions, nil, bytes.NewBufferString(`[{"task_guid":"tg"}]`))
+			Expect(status).To(Equal(http.StatusAccepted))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		})
+
+		It("rejects invalid JSON", func() {
+			status, _ := Request(auctioneer_routes.SubmitTaskAuctions, nil, bytes.NewBufferString("{"))
+			Expect(status).To(Equal(http.StatusBadRequest))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("QueueStatus", func() {
+		It("reports the queue", func() {
+			queue.QueueStatusReturns(auctiontypes.QueueStatus{LRPAuctions: 3, TaskAuctions: 1})
+
+			status, body := Request(auctioneer_routes.QueueStatus, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`{"LRPAuctions":3,"TaskAuctions":1}`))
+		})
+	})
+
+	Describe("LRPResult", func() {
+		It("rejects non-numeric indices", func() {
+			status, _ := Request(auctioneer_routes.LRPResult, rata.Params{"process_guid": "pg", "index": "first"}, nil)
+			Expect(status).To(Equal(http.StatusBadRequest))
+			Expect(results.lookups).To(BeEmpty())
+		})
+
+		It("is not found when there is no result", func() {
+			status, _ := Request(auctioneer_routes.LRPResult, rata.Params{"process_guid": "pg", "index": "1"}, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+			Expect(results.lookups).To(Equal([]string{"pg.1"}))
+		})
+	})
+
+	Describe("TaskResult", func() {
+		It("is not found when there is no result", func() {
+			status, _ := Request(auctioneer_routes.TaskResult, rata.Params{"task_guid": "tg"}, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+			Expect(results.lookups).To(Equal([]string{"tg"}))
+		})
+	})
+})
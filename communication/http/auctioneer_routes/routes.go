@@ -0,0 +1,19 @@
+package auctioneer_routes
+
+import "github.com/tedsuo/rata"
+
+const (
+	SubmitLRPAuctions  = "SUBMIT_LRP_AUCTIONS"
+	SubmitTaskAuctions = "SUBMIT_TASK_AUCTIONS"
+	QueueStatus        = "QUEUE_STATUS"
+	LRPResult          = "LRP_RESULT"
+	TaskResult         = "TASK_RESULT"
+)
+
+var Routes = rata.Routes{
+	{Path: "/auctions/lrps", Method: "POST", Name: SubmitLRPAuctions},
+	{Path: "/auctions/tasks", Method: "POST", Name: SubmitTaskAuctions},
+	{Path: "/auctions/queue", Method: "GET", Name: QueueStatus},
+	{Path: "/auctions/lrps/:process_guid/:index", Method: "GET", Name: LRPResult},
+	{Path: "/auctions/tasks/:task_guid", Method: "GET", Name: TaskResult},
+}
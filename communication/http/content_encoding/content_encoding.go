@@ -0,0 +1,99 @@
+/*
+Package content_encoding negotiates gzip compression of the JSON bodies sent
+between auctioneers and reps.
+
+Handlers advertise that they accept gzipped request bodies with an
+Accept-Encoding response header, and compress responses for clients that ask
+for it.  Clients only compress request bodies once the peer has advertised
+support, so peers that only speak plain JSON keep working in both directions.
+*/
+package content_encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const Gzip = "gzip"
+
+// bodies smaller than this are sent uncompressed; gzip's framing outweighs
+// any savings
+const minCompressibleSize = 1024
+
+func Accepts(header http.Header) bool {
+	for _, value := range header["Accept-Encoding"] {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.TrimSpace(strings.SplitN(encoding, ";", 2)[0])
+			if encoding == Gzip {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func Advertise(header http.Header) {
+	header.Set("Accept-Encoding", Gzip)
+}
+
+// EncodeJSON marshals v, gzipping it if compress is set and v is large enough.
+// It returns the Content-Encoding of the result, which is empty for plain JSON.
+func EncodeJSON(v interface{}, compress bool) ([]byte, string, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !compress || len(body) < minCompressibleSize {
+		return body, "", nil
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	_, err = writer.Write(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buffer.Bytes(), Gzip, nil
+}
+
+// DecodeJSON unmarshals body into v, decompressing it first if the
+// Content-Encoding in header says it is gzipped.
+func DecodeJSON(body io.Reader, header http.Header, v interface{}) error {
+	if header.Get("Content-Encoding") == Gzip {
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		body = reader
+	}
+
+	return json.NewDecoder(body).Decode(v)
+}
+
+// WriteJSON writes v as the response to r, compressed if r accepts gzip.
+func WriteJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	body, encoding, err := EncodeJSON(v, Accepts(r.Header))
+	if err != nil {
+		return err
+	}
+
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(body)
+	return err
+}
@@ -0,0 +1,13 @@
+package content_encoding_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestContentEncoding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ContentEncoding Suite")
+}
@@ -0,0 +1,107 @@
+package content_encoding_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/auction/communication/http/content_encoding"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type payload struct {
+	Value string
+}
+
+var _ = Describe("ContentEncoding", func() {
+	var small, large payload
+
+	BeforeEach(func() {
+		small = payload{Value: "small"}
+		large = payload{Value: strings.Repeat("large", 1000)}
+	})
+
+	Describe("Accepts", func() {
+		It("finds gzip among the accepted encodings", func() {
+			Expect(content_encoding.Accepts(http.Header{"Accept-Encoding": {"gzip"}})).To(BeTrue())
+			Expect(content_encoding.Accepts(http.Header{"Accept-Encoding": {"deflate, gzip;q=0.5"}})).To(BeTrue())
+		})
+
+		It("is false when gzip is not accepted", func() {
+			Expect(content_encoding.Accepts(http.Header{})).To(BeFalse())
+			Expect(content_encoding.Accepts(http.Header{"Accept-Encoding": {"deflate"}})).To(BeFalse())
+		})
+	})
+
+	Describe("EncodeJSON and DecodeJSON", func() {
+		It("leaves the JSON plain when not compressing", func() {
+			body, encoding, err := content_encoding.EncodeJSON(large, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoding).To(BeEmpty())
+			Expect(body).To(MatchJSON(`{"Value":"` + large.Value + `"}`))
+		})
+
+		It("leaves small bodies plain", func() {
+			_, encoding, err := content_encoding.EncodeJSON(small, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoding).To(BeEmpty())
+		})
+
+		It("round-trips compressed bodies", func() {
+			body, encoding, err := content_encoding.EncodeJSON(large, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoding).To(Equal(content_encoding.Gzip))
+			Expect(len(body)).To(BeNumerically("<", len(large.Value)))
+
+			var decoded payload
+			err = content_encoding.DecodeJSON(bytes.NewReader(body), http.Header{"Content-Encoding": {encoding}}, &decoded)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal(large))
+		})
+
+		It("decodes plain bodies", func() {
+			var decoded payload
+			err := content_encoding.DecodeJSON(strings.NewReader(`{"Value":"plain"}`), http.Header{}, &decoded)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal(payload{Value: "plain"}))
+		})
+
+		It("errors when a body claiming to be gzipped is not", func() {
+			var decoded payload
+			err := content_encoding.DecodeJSON(strings.NewReader(`{"Value":"plain"}`), http.Header{"Content-Encoding": {"gzip"}}, &decoded)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("WriteJSON", func() {
+		var recorder *httptest.ResponseRecorder
+		var request *http.Request
+
+		BeforeEach(func() {
+			recorder = httptest.NewRecorder()
+
+			var err error
+			request, err = http.NewRequest("GET", "/state", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("compresses the response when the request accepts gzip", func() {
+			request.Header.Set("Accept-Encoding", "gzip")
+			Expect(content_encoding.WriteJSON(recorder, request, large)).To(Succeed())
+			Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+
+			var decoded payload
+			Expect(content_encoding.DecodeJSON(recorder.Body, recorder.Header(), &decoded)).To(Succeed())
+			Expect(decoded).To(Equal(large))
+		})
+
+		It("writes plain JSON otherwise", func() {
+			Expect(content_encoding.WriteJSON(recorder, request, large)).To(Succeed())
+			Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(recorder.Body.String()).To(MatchJSON(`{"Value":"` + large.Value + `"}`))
+		})
+	})
+})
@@ -0,0 +1,145 @@
+/*
+Package certauthority generates a throwaway CA and certificates signed by it,
+so tests and the simulation can exercise mutual TLS without checked-in keys.
+*/
+package certauthority
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"time"
+)
+
+const validity = 24 * time.Hour
+
+type CertAuthority struct {
+	dir        string
+	cert       *x509.Certificate
+	key        *ecdsa.PrivateKey
+	caCertFile string
+}
+
+// New generates a CA and writes its certificate to dir.
+func New(dir string) (*CertAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := newTemplate("auction-ca")
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	caCertFile := filepath.Join(dir, "ca.crt")
+	err = writePEM(caCertFile, "CERTIFICATE", der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertAuthority{
+		dir:        dir,
+		cert:       cert,
+		key:        key,
+		caCertFile: caCertFile,
+	}, nil
+}
+
+func (ca *CertAuthority) CACertFile() string {
+	return ca.caCertFile
+}
+
+// GenerateServerCert writes a certificate and key for name, valid for server
+// auth only.  name is the certificate's CN and DNS SAN, so reps should be
+// issued certificates named after their guid.
+func (ca *CertAuthority) GenerateServerCert(name string) (certFile string, keyFile string, err error) {
+	return ca.generateCert(name, "server", x509.ExtKeyUsageServerAuth)
+}
+
+// GenerateClientCert writes a certificate and key for name, valid for client
+// auth only.  name is the certificate's CN, which servers check against the
+// clients they allow, such as the auctioneers.
+func (ca *CertAuthority) GenerateClientCert(name string) (certFile string, keyFile string, err error) {
+	return ca.generateCert(name, "client", x509.ExtKeyUsageClientAuth)
+}
+
+func (ca *CertAuthority) generateCert(name string, kind string, usage x509.ExtKeyUsage) (certFile string, keyFile string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	template, err := newTemplate(name)
+	if err != nil {
+		return "", "", err
+	}
+	if usage == x509.ExtKeyUsageServerAuth {
+		template.DNSNames = []string{name}
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return "", "", err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	certFile = filepath.Join(ca.dir, name+"-"+kind+".crt")
+	keyFile = filepath.Join(ca.dir, name+"-"+kind+".key")
+
+	err = writePEM(certFile, "CERTIFICATE", der)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = writePEM(keyFile, "EC PRIVATE KEY", keyDER)
+	if err != nil {
+		return "", "", err
+	}
+
+	return certFile, keyFile, nil
+}
+
+func newTemplate(commonName string) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+func writePEM(path, blockType string, der []byte) error {
+	return ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+}
@@ -0,0 +1,157 @@
+/*
+Package mutual_tls configures mutually authenticated TLS between auctioneers
+and reps.
+
+Both sides present certificates signed by a shared CA.  Each rep's certificate
+must name the rep's guid as a DNS subject alternative name: auctioneers verify
+the certificate against the repGuid they expect to be talking to rather than
+against the address they dialed, so one cell can't impersonate another.
+
+Reps' certificates are for server auth and auctioneers' for client auth, and
+reps only accept clients whose certificates name one of the auctioneers in
+their CN, so one rep can't hand work to another.
+
+Reps register with the registry as clients named after their guid, and the
+registry only takes registrations and state from the cell that the client's
+certificate names.
+*/
+package mutual_tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+var ErrInvalidCACert = errors.New("no certificates found in CA file")
+var ErrNoClientPolicy = errors.New("no policy for which clients to accept")
+
+type Config struct {
+	CACertFile string
+	CertFile   string
+	KeyFile    string
+}
+
+// ClientPolicy decides whether to accept a client, by the CN of its
+// certificate.
+type ClientPolicy func(name string) bool
+
+// AllowNames accepts the clients with one of the given names.
+func AllowNames(names ...string) ClientPolicy {
+	return func(name string) bool {
+		for _, allowed := range names {
+			if name == allowed {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllowAnyName accepts any client with a certificate from the CA.  Servers
+// that use it must check each request's ClientName themselves.
+func AllowAnyName(string) bool {
+	return true
+}
+
+// ServerTLSConfig is for reps: it presents the rep's certificate and requires
+// clients to present a certificate signed by the CA for client auth, and
+// named as the policy allows, typically AllowNames of the auctioneers.
+func (c Config) ServerTLSConfig(policy ClientPolicy) (*tls.Config, error) {
+	if policy == nil {
+		return nil, ErrNoClientPolicy
+	}
+
+	certificate, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			name := state.VerifiedChains[0][0].Subject.CommonName
+			if !policy(name) {
+				return fmt.Errorf("client %q is not allowed", name)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// ClientName is the CN of the certificate that the client of a request made
+// with a ServerTLSConfig presented, or "" if the request isn't over TLS.
+func ClientName(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return ""
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.CommonName
+}
+
+// ClientTLSConfig is for auctioneers: it presents the auctioneer's
+// certificate and trusts reps whose certificates are signed by the CA.  Use
+// ForCell to verify the identity of a particular rep.
+func (c Config) ClientTLSConfig() (*tls.Config, error) {
+	certificate, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func (c Config) load() (tls.Certificate, *x509.CertPool, error) {
+	certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	caCert, err := ioutil.ReadFile(c.CACertFile)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return tls.Certificate{}, nil, ErrInvalidCACert
+	}
+
+	return certificate, pool, nil
+}
+
+// ForCell returns a copy of clientConfig that only accepts the certificate of
+// the rep with the given guid.
+func ForCell(clientConfig *tls.Config, repGuid string) *tls.Config {
+	config := clientConfig.Clone()
+	config.ServerName = repGuid
+	return config
+}
+
+// NewCellClient returns an http.Client, for use with auction_http_client.New,
+// that talks mutual TLS to the rep with the given guid and nothing else.
+func NewCellClient(clientConfig *tls.Config, repGuid string, timeout time.Duration) *http.Client {
+	return NewClient(clientConfig, repGuid, timeout)
+}
+
+// NewClient returns an http.Client that talks mutual TLS to the server whose
+// certificate names serverName, such as the registry, and nothing else.
+func NewClient(clientConfig *tls.Config, serverName string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: ForCell(clientConfig, serverName),
+		},
+	}
+}
@@ -0,0 +1,176 @@
+package mutual_tls_test
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls"
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls/certauthority"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MutualTLS", func() {
+	var tmpDir string
+	var ca *certauthority.CertAuthority
+	var auctioneerConfig mutual_tls.Config
+	var auctionRep *fakes.FakeSimulationCellRep
+	var server *httptest.Server
+
+	newConfig := func(generate func(string) (string, string, error), caCertFile string, name string) mutual_tls.Config {
+		certFile, keyFile, err := generate(name)
+		Expect(err).NotTo(HaveOccurred())
+
+		return mutual_tls.Config{
+			CACertFile: caCertFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+		}
+	}
+
+	newClient := func(config mutual_tls.Config, repGuid string) auctiontypes.SimulationCellRep {
+		clientTLSConfig, err := config.ClientTLSConfig()
+		Expect(err).NotTo(HaveOccurred())
+
+		httpClient := mutual_tls.NewCellClient(clientTLSConfig, repGuid, time.Second)
+		return auction_http_client.New(httpClient, repGuid, server.URL, lagertest.NewTestLogger("test"))
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "mutual-tls")
+		Expect(err).NotTo(HaveOccurred())
+
+		ca, err = certauthority.New(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		auctioneerConfig = newConfig(ca.GenerateClientCert, ca.CACertFile(), "auctioneer")
+		repConfig := newConfig(ca.GenerateServerCert, ca.CACertFile(), "rep-1")
+
+		auctionRep = &fakes.FakeSimulationCellRep{}
+		handler, err := rata.NewRouter(routes.Routes, auction_http_handlers.New(auctionRep, lagertest.NewTestLogger("test")))
+		Expect(err).NotTo(HaveOccurred())
+
+		serverTLSConfig, err := repConfig.ServerTLSConfig(mutual_tls.AllowNames("auctioneer"))
+		Expect(err).NotTo(HaveOccurred())
+
+		server = httptest.NewUnstartedServer(handler)
+		server.TLS = serverTLSConfig
+		server.StartTLS()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("lets an auctioneer with a trusted certificate talk to the rep it expects", func() {
+		_, err := newClient(auctioneerConfig, "rep-1").State()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(auctionRep.StateCallCount()).To(Equal(1))
+	})
+
+	It("refuses to talk to a rep with a different guid", func() {
+		_, err := newClient(auctioneerConfig, "rep-2").Perform(auctiontypes.Work{})
+		Expect(err).To(HaveOccurred())
+		Expect(auctionRep.PerformCallCount()).To(Equal(0))
+	})
+
+	It("rejects auctioneers that do not present a certificate", func() {
+		clientTLSConfig, err := auctioneerConfig.ClientTLSConfig()
+		Expect(err).NotTo(HaveOccurred())
+		clientTLSConfig.Certificates = nil
+
+		httpClient := mutual_tls.NewCellClient(clientTLSConfig, "rep-1", time.Second)
+		_, err = auction_http_client.New(httpClient, "rep-1", server.URL, lagertest.NewTestLogger("test")).State()
+		Expect(err).To(HaveOccurred())
+		Expect(auctionRep.StateCallCount()).To(Equal(0))
+	})
+
+	It("rejects auctioneers with certificates from another CA", func() {
+		otherDir := filepath.Join(tmpDir, "other")
+		Expect(os.Mkdir(otherDir, 0755)).To(Succeed())
+		otherCA, err := certauthority.New(otherDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		impostorConfig := newConfig(otherCA.GenerateClientCert, ca.CACertFile(), "auctioneer")
+
+		_, err = newClient(impostorConfig, "rep-1").State()
+		Expect(err).To(HaveOccurred())
+		Expect(auctionRep.StateCallCount()).To(Equal(0))
+	})
+
+	It("rejects a rep's own certificate", func() {
+		otherRepConfig := newConfig(ca.GenerateServerCert, ca.CACertFile(), "rep-2")
+		otherRep := newClient(otherRepConfig, "rep-1")
+
+		_, err := otherRep.State()
+		Expect(err).To(HaveOccurred())
+
+		_, err = otherRep.Perform(auctiontypes.Work{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(auctionRep.StateCallCount()).To(Equal(0))
+		Expect(auctionRep.PerformCallCount()).To(Equal(0))
+	})
+
+	It("rejects clients with trusted certificates that are not auctioneers", func() {
+		cellConfig := newConfig(ca.GenerateClientCert, ca.CACertFile(), "rep-2")
+		cell := newClient(cellConfig, "rep-1")
+
+		_, err := cell.State()
+		Expect(err).To(HaveOccurred())
+
+		_, err = cell.Perform(auctiontypes.Work{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(auctionRep.StateCallCount()).To(Equal(0))
+		Expect(auctionRep.PerformCallCount()).To(Equal(0))
+	})
+
+	It("rejects plain http", func() {
+		response, err := http.Get("http://" + server.Listener.Addr().String() + "/state")
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+
+		Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(auctionRep.StateCallCount()).To(Equal(0))
+	})
+
+	Context("without a policy for which clients to accept", func() {
+		It("errors", func() {
+			_, err := auctioneerConfig.ServerTLSConfig(nil)
+			Expect(err).To(Equal(mutual_tls.ErrNoClientPolicy))
+		})
+	})
+
+	Context("when the CA file has no certificates", func() {
+		It("errors", func() {
+			Expect(ioutil.WriteFile(auctioneerConfig.CACertFile, []byte("nope"), 0600)).To(Succeed())
+
+			_, err := auctioneerConfig.ClientTLSConfig()
+			Expect(err).To(Equal(mutual_tls.ErrInvalidCACert))
+		})
+	})
+
+	Describe("ForCell", func() {
+		It("does not modify the shared config", func() {
+			clientTLSConfig := &tls.Config{}
+			Expect(mutual_tls.ForCell(clientTLSConfig, "rep-1").ServerName).To(Equal("rep-1"))
+			Expect(clientTLSConfig.ServerName).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package registration_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/auction/communication/http/registration_routes"
+	"github.com/cloudfoundry-incubator/auction/registry"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+)
+
+type RegistrationHTTPClient struct {
+	client           *http.Client
+	requestGenerator *rata.RequestGenerator
+	logger           lager.Logger
+}
+
+func New(client *http.Client, address string, logger lager.Logger) *RegistrationHTTPClient {
+	return &RegistrationHTTPClient{
+		client:           client,
+		requestGenerator: rata.NewRequestGenerator(address, registration_routes.Routes),
+		logger:           logger,
+	}
+}
+
+func (c *RegistrationHTTPClient) Register(registration registry.Registration) error {
+	logger := c.logger.Session("registering", lager.Data{
+		"cell-guid": registration.CellGuid,
+	})
+
+	return c.do(logger, registration_routes.Register, nil, registration, http.StatusCreated)
+}
+
+func (c *RegistrationHTTPClient) Update(delta registry.StateDelta) error {
+	logger := c.logger.Session("updating-state", lager.Data{
+		"cell-guid":  delta.CellGuid,
+		"generation": delta.Generation,
+	})
+
+	return c.do(logger, registration_routes.Update, rata.Params{"guid": delta.CellGuid}, delta, http.StatusNoContent)
+}
+
+func (c *RegistrationHTTPClient) Deregister(cellGuid string) error {
+	logger := c.logger.Session("deregistering", lager.Data{
+		"cell-guid": cellGuid,
+	})
+
+	return c.do(logger, registration_routes.Deregister, rata.Params{"guid": cellGuid}, nil, http.StatusNoContent)
+}
+
+func (c *RegistrationHTTPClient) do(logger lager.Logger, route string, params rata.Params, payload interface{}, expectedStatus int) error {
+	logger.Debug("requesting")
+
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			logger.Error("failed-to-marshal", err)
+			return err
+		}
+	}
+
+	req, err := c.requestGenerator.CreateRequest(route, params, bytes.NewReader(body))
+	if err != nil {
+		logger.Error("failed-to-create-request", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("failed-to-perform-request", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case expectedStatus:
+	case http.StatusNotFound:
+		return registry.ErrUnknownCell
+	case http.StatusConflict:
+		return registry.ErrGenerationMismatch
+	default:
+		logger.Error("invalid-status-code", fmt.Errorf("%d", resp.StatusCode))
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	logger.Debug("done")
+	return nil
+}
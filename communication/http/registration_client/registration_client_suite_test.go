@@ -0,0 +1,85 @@
+package registration_client_test
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls"
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls/certauthority"
+	"github.com/cloudfoundry-incubator/auction/communication/http/registration_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/registration_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/registration_routes"
+	"github.com/cloudfoundry-incubator/auction/registry/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	"testing"
+)
+
+func TestRegistrationClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RegistrationClient Suite")
+}
+
+var certsDir string
+var ca *certauthority.CertAuthority
+var registrations *fakes.FakeRegistrationClient
+var server *httptest.Server
+var client *registration_client.RegistrationHTTPClient
+
+// newCellClient registers as the cell its client certificate names
+func newCellClient(cellGuid string) *registration_client.RegistrationHTTPClient {
+	certFile, keyFile, err := ca.GenerateClientCert(cellGuid)
+	Expect(err).NotTo(HaveOccurred())
+
+	clientTLSConfig, err := mutual_tls.Config{
+		CACertFile: ca.CACertFile(),
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+	}.ClientTLSConfig()
+	Expect(err).NotTo(HaveOccurred())
+
+	httpClient := mutual_tls.NewClient(clientTLSConfig, "registry", time.Second)
+	return registration_client.New(httpClient, server.URL, lagertest.NewTestLogger("test"))
+}
+
+var _ = BeforeEach(func() {
+	logger := lagertest.NewTestLogger("test")
+
+	var err error
+	certsDir, err = ioutil.TempDir("", "registration-client")
+	Expect(err).NotTo(HaveOccurred())
+
+	ca, err = certauthority.New(certsDir)
+	Expect(err).NotTo(HaveOccurred())
+
+	certFile, keyFile, err := ca.GenerateServerCert("registry")
+	Expect(err).NotTo(HaveOccurred())
+
+	serverTLSConfig, err := mutual_tls.Config{
+		CACertFile: ca.CACertFile(),
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+	}.ServerTLSConfig(mutual_tls.AllowAnyName)
+	Expect(err).NotTo(HaveOccurred())
+
+	registrations = &fakes.FakeRegistrationClient{}
+
+	handler, err := rata.NewRouter(registration_routes.Routes, registration_handlers.New(registrations, logger))
+	Expect(err).NotTo(HaveOccurred())
+	server = httptest.NewUnstartedServer(handler)
+	server.TLS = serverTLSConfig
+	server.StartTLS()
+
+	client = newCellClient("cell")
+})
+
+var _ = AfterEach(func() {
+	server.Close()
+	os.RemoveAll(certsDir)
+})
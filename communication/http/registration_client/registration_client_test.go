@@ -0,0 +1,113 @@
+package registration_client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/registration_client"
+	"github.com/cloudfoundry-incubator/auction/registry"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("RegistrationClient", func() {
+	Describe("Register", func() {
+		It("sends the registration", func() {
+			registration := registry.Registration{
+				CellGuid: "cell",
+				Address:  "http://cell",
+				State: auctiontypes.CellState{
+					RootFSProviders: auctiontypes.RootFSProviders{
+						models.PreloadedRootFSScheme: auctiontypes.NewFixedSetRootFSProvider("lucid64"),
+					},
+					TotalResources: auctiontypes.Resources{MemoryMB: 100, DiskMB: 100, Containers: 10},
+					Zone:           "Z0",
+					Generation:     4,
+				},
+			}
+
+			Expect(client.Register(registration)).To(Succeed())
+			Expect(registrations.RegisterCallCount()).To(Equal(1))
+			Expect(registrations.RegisterArgsForCall(0)).To(Equal(registration))
+		})
+
+		It("errors when the registry fails to register the cell", func() {
+			registrations.RegisterReturns(errors.New("boom"))
+			Expect(client.Register(registry.Registration{CellGuid: "cell"})).NotTo(Succeed())
+		})
+	})
+
+	Describe("Update", func() {
+		var delta registry.StateDelta
+
+		BeforeEach(func() {
+			delta = registry.StateDelta{
+				CellGuid:       "cell",
+				BaseGeneration: 4,
+				Generation:     5,
+				AddedLRPs:      []auctiontypes.LRP{{ProcessGuid: "pg", Index: 1, MemoryMB: 10, DiskMB: 10}},
+				RemovedTasks:   []string{"tg"},
+			}
+		})
+
+		It("sends the delta", func() {
+			Expect(client.Update(delta)).To(Succeed())
+			Expect(registrations.UpdateCallCount()).To(Equal(1))
+			Expect(registrations.UpdateArgsForCall(0)).To(Equal(delta))
+		})
+
+		It("returns ErrUnknownCell when the registry does not know the cell", func() {
+			registrations.UpdateReturns(registry.ErrUnknownCell)
+			Expect(client.Update(delta)).To(Equal(registry.ErrUnknownCell))
+		})
+
+		It("returns ErrGenerationMismatch when the delta does not apply", func() {
+			registrations.UpdateReturns(registry.ErrGenerationMismatch)
+			Expect(client.Update(delta)).To(Equal(registry.ErrGenerationMismatch))
+		})
+	})
+
+	Describe("Deregister", func() {
+		It("deregisters the cell", func() {
+			Expect(client.Deregister("cell")).To(Succeed())
+			Expect(registrations.DeregisterCallCount()).To(Equal(1))
+			Expect(registrations.DeregisterArgsForCall(0)).To(Equal("cell"))
+		})
+	})
+
+	Describe("authorization", func() {
+		var otherCell *registration_client.RegistrationHTTPClient
+
+		BeforeEach(func() {
+			otherCell = newCellClient("other-cell")
+		})
+
+		It("refuses to register a cell for a client named after another", func() {
+			Expect(otherCell.Register(registry.Registration{CellGuid: "cell", Address: "http://elsewhere"})).NotTo(Succeed())
+			Expect(registrations.RegisterCallCount()).To(Equal(0))
+		})
+
+		It("refuses to update a cell for a client named after another", func() {
+			Expect(otherCell.Update(registry.StateDelta{CellGuid: "cell"})).NotTo(Succeed())
+			Expect(registrations.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("refuses to deregister a cell for a client named after another", func() {
+			Expect(otherCell.Deregister("cell")).NotTo(Succeed())
+			Expect(registrations.DeregisterCallCount()).To(Equal(0))
+		})
+
+		It("refuses registrations over plain http", func() {
+			plainServer := httptest.NewServer(server.Config.Handler)
+			defer plainServer.Close()
+
+			plainClient := registration_client.New(&http.Client{}, plainServer.URL, lagertest.NewTestLogger("test"))
+			Expect(plainClient.Register(registry.Registration{CellGuid: "cell"})).NotTo(Succeed())
+			Expect(registrations.RegisterCallCount()).To(Equal(0))
+		})
+	})
+})
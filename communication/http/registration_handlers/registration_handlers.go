@@ -0,0 +1,125 @@
+package registration_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls"
+	"github.com/cloudfoundry-incubator/auction/communication/http/registration_routes"
+	"github.com/cloudfoundry-incubator/auction/registry"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+)
+
+// New serves registrations over mutual TLS, from a mutual_tls ServerTLSConfig.
+// A cell may only register, update and deregister itself: the CN of the
+// client's certificate must be the cell's guid.
+func New(registrations registry.RegistrationClient, logger lager.Logger) rata.Handlers {
+	handlers := rata.Handlers{
+		registration_routes.Register:   &register{registrations: registrations, logger: logger},
+		registration_routes.Update:     &update{registrations: registrations, logger: logger},
+		registration_routes.Deregister: &deregister{registrations: registrations, logger: logger},
+	}
+
+	return handlers
+}
+
+type register struct {
+	registrations registry.RegistrationClient
+	logger        lager.Logger
+}
+
+func (h *register) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("register-cell")
+
+	var registration registry.Registration
+	err := json.NewDecoder(r.Body).Decode(&registration)
+	if err != nil || registration.CellGuid == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error("failed-to-unmarshal", err)
+		return
+	}
+
+	if !authorized(w, r, registration.CellGuid, logger) {
+		return
+	}
+
+	err = h.registrations.Register(registration)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-register", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+type update struct {
+	registrations registry.RegistrationClient
+	logger        lager.Logger
+}
+
+func (h *update) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("update-cell-state")
+
+	var delta registry.StateDelta
+	err := json.NewDecoder(r.Body).Decode(&delta)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error("failed-to-unmarshal", err)
+		return
+	}
+	delta.CellGuid = r.FormValue(":guid")
+
+	if !authorized(w, r, delta.CellGuid, logger) {
+		return
+	}
+
+	err = h.registrations.Update(delta)
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case registry.ErrUnknownCell:
+		w.WriteHeader(http.StatusNotFound)
+	case registry.ErrGenerationMismatch:
+		w.WriteHeader(http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-update", err)
+	}
+}
+
+type deregister struct {
+	registrations registry.RegistrationClient
+	logger        lager.Logger
+}
+
+func (h *deregister) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("deregister-cell")
+
+	cellGuid := r.FormValue(":guid")
+	if !authorized(w, r, cellGuid, logger) {
+		return
+	}
+
+	err := h.registrations.Deregister(cellGuid)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-deregister", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorized responds with http.StatusForbidden unless the request's client
+// certificate names the cell
+func authorized(w http.ResponseWriter, r *http.Request, cellGuid string, logger lager.Logger) bool {
+	clientName := mutual_tls.ClientName(r)
+	if clientName != cellGuid {
+		w.WriteHeader(http.StatusForbidden)
+		logger.Error("unauthorized-client", nil, lager.Data{"client": clientName, "cell-guid": cellGuid})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,15 @@
+package registration_routes
+
+import "github.com/tedsuo/rata"
+
+const (
+	Register   = "REGISTER"
+	Update     = "UPDATE_CELL_STATE"
+	Deregister = "DEREGISTER"
+)
+
+var Routes = rata.Routes{
+	{Path: "/cells", Method: "POST", Name: Register},
+	{Path: "/cells/:guid/state", Method: "PUT", Name: Update},
+	{Path: "/cells/:guid", Method: "DELETE", Name: Deregister},
+}
@@ -6,6 +6,10 @@ const (
 	State   = "STATE"
 	Perform = "PERFORM"
 
+	Ping    = "PING"
+	Ready   = "READY"
+	Version = "VERSION"
+
 	Sim_Reset = "RESET"
 )
 
@@ -13,5 +17,9 @@ var Routes = rata.Routes{
 	{Path: "/state", Method: "GET", Name: State},
 	{Path: "/work", Method: "POST", Name: Perform},
 
+	{Path: "/ping", Method: "GET", Name: Ping},
+	{Path: "/ready", Method: "GET", Name: Ready},
+	{Path: "/version", Method: "GET", Name: Version},
+
 	{Path: "/sim/reset", Method: "POST", Name: Sim_Reset},
 }
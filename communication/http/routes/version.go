@@ -0,0 +1,28 @@
+package routes
+
+// ProtocolVersion changes whenever the messages exchanged on Routes change
+// incompatibly.  Clients only send work to reps that speak the same version.
+// Reps that predate the Version route speak version 1.
+const ProtocolVersion = 1
+
+// Optional features a rep may support within a protocol version.
+const (
+	FeatureGzip        = "gzip"
+	FeatureGenerations = "generations"
+)
+
+var Features = []string{FeatureGzip, FeatureGenerations}
+
+type VersionInfo struct {
+	ProtocolVersion int
+	Features        []string
+}
+
+func (v VersionInfo) Supports(feature string) bool {
+	for _, f := range v.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
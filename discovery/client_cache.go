@@ -0,0 +1,115 @@
+package discovery
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls"
+)
+
+type TransportConfig struct {
+	// Timeout bounds each request to a cell.
+	Timeout time.Duration
+
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	// TLSConfig, typically from mutual_tls.Config.ClientTLSConfig, makes the
+	// clients talk mutual TLS.  Each cell's certificate is verified against
+	// its guid.
+	TLSConfig *tls.Config
+}
+
+type cachedClient struct {
+	address string
+	rep     auctiontypes.CellRep
+}
+
+/*
+ClientCache hands out auction_http_clients for the cells a delegate discovers,
+and keeps them between auctions so that their connections are reused.  A
+client is rebuilt only when its cell's address changes, and dropped once its
+cell is no longer discovered.
+
+Without TLS every client shares a single transport.  With TLS each cell gets
+its own transport, built from the same settings, so it can verify that cell's
+certificate.
+*/
+type ClientCache struct {
+	config    TransportConfig
+	transport *http.Transport
+	logger    lager.Logger
+
+	clients map[string]cachedClient
+	lock    *sync.Mutex
+}
+
+func NewClientCache(config TransportConfig, logger lager.Logger) *ClientCache {
+	return &ClientCache{
+		config:    config,
+		transport: newTransport(config),
+		logger:    logger,
+		clients:   map[string]cachedClient{},
+		lock:      &sync.Mutex{},
+	}
+}
+
+// ClientsFor returns a client for each cell, keyed by cell guid, given the
+// cells' addresses keyed by cell guid.
+func (c *ClientCache) ClientsFor(addresses map[string]string) map[string]auctiontypes.CellRep {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	reps := map[string]auctiontypes.CellRep{}
+	for guid, address := range addresses {
+		cached, ok := c.clients[guid]
+		if !ok || cached.address != address {
+			cached = cachedClient{
+				address: address,
+				rep:     auction_http_client.New(c.httpClient(guid), guid, address, c.logger),
+			}
+			c.clients[guid] = cached
+		}
+		reps[guid] = cached.rep
+	}
+
+	for guid := range c.clients {
+		if _, ok := addresses[guid]; !ok {
+			delete(c.clients, guid)
+		}
+	}
+
+	return reps
+}
+
+func (c *ClientCache) httpClient(guid string) *http.Client {
+	transport := c.transport
+	if c.config.TLSConfig != nil {
+		transport = newTransport(c.config)
+		transport.TLSClientConfig = mutual_tls.ForCell(c.config.TLSConfig, guid)
+	}
+
+	return &http.Client{
+		Timeout:   c.config.Timeout,
+		Transport: transport,
+	}
+}
+
+func newTransport(config TransportConfig) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		IdleConnTimeout:     config.IdleConnTimeout,
+	}
+}
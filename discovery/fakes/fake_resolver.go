@@ -0,0 +1,65 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/auction/discovery"
+)
+
+type FakeResolver struct {
+	LookupSRVStub        func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	lookupSRVMutex       sync.RWMutex
+	lookupSRVArgsForCall []struct {
+		ctx     context.Context
+		service string
+		proto   string
+		name    string
+	}
+	lookupSRVReturns struct {
+		result1 string
+		result2 []*net.SRV
+		result3 error
+	}
+}
+
+func (fake *FakeResolver) LookupSRV(ctx context.Context, service string, proto string, name string) (string, []*net.SRV, error) {
+	fake.lookupSRVMutex.Lock()
+	fake.lookupSRVArgsForCall = append(fake.lookupSRVArgsForCall, struct {
+		ctx     context.Context
+		service string
+		proto   string
+		name    string
+	}{ctx, service, proto, name})
+	fake.lookupSRVMutex.Unlock()
+	if fake.LookupSRVStub != nil {
+		return fake.LookupSRVStub(ctx, service, proto, name)
+	} else {
+		return fake.lookupSRVReturns.result1, fake.lookupSRVReturns.result2, fake.lookupSRVReturns.result3
+	}
+}
+
+func (fake *FakeResolver) LookupSRVCallCount() int {
+	fake.lookupSRVMutex.RLock()
+	defer fake.lookupSRVMutex.RUnlock()
+	return len(fake.lookupSRVArgsForCall)
+}
+
+func (fake *FakeResolver) LookupSRVArgsForCall(i int) (context.Context, string, string, string) {
+	fake.lookupSRVMutex.RLock()
+	defer fake.lookupSRVMutex.RUnlock()
+	return fake.lookupSRVArgsForCall[i].ctx, fake.lookupSRVArgsForCall[i].service, fake.lookupSRVArgsForCall[i].proto, fake.lookupSRVArgsForCall[i].name
+}
+
+func (fake *FakeResolver) LookupSRVReturns(result1 string, result2 []*net.SRV, result3 error) {
+	fake.LookupSRVStub = nil
+	fake.lookupSRVReturns = struct {
+		result1 string
+		result2 []*net.SRV
+		result3 error
+	}{result1, result2, result3}
+}
+
+var _ discovery.Resolver = new(FakeResolver)
@@ -0,0 +1,123 @@
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+type ResultsDelegate interface {
+	AuctionCompleted(auctiontypes.AuctionResults)
+}
+
+// Cell is an entry in a cells file.
+type Cell struct {
+	Guid    string `json:"guid" yaml:"guid"`
+	Address string `json:"address" yaml:"address"`
+}
+
+type fileDelegate struct {
+	path            string
+	clients         *ClientCache
+	resultsDelegate ResultsDelegate
+	logger          lager.Logger
+
+	digest    [sha256.Size]byte
+	addresses map[string]string
+	lock      *sync.Mutex
+}
+
+/*
+NewFileDelegate returns an AuctionRunnerDelegate that discovers cells from a
+file listing their guids and addresses.  Files ending in .yml or .yaml are
+read as YAML, anything else as JSON:
+
+	[{"guid": "cell-1", "address": "http://10.0.0.1:1800"}]
+
+The file is polled: every FetchCellReps reads it again, and parses it again
+if its contents have changed.  If it cannot be read, the cells it last listed
+are used.
+*/
+func NewFileDelegate(path string, clients *ClientCache, resultsDelegate ResultsDelegate, logger lager.Logger) auctiontypes.AuctionRunnerDelegate {
+	return &fileDelegate{
+		path:            path,
+		clients:         clients,
+		resultsDelegate: resultsDelegate,
+		logger:          logger.Session("file-discovery", lager.Data{"path": path}),
+		lock:            &sync.Mutex{},
+	}
+}
+
+func (d *fileDelegate) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	addresses, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.clients.ClientsFor(addresses), nil
+}
+
+func (d *fileDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	if d.resultsDelegate != nil {
+		d.resultsDelegate.AuctionCompleted(results)
+	}
+}
+
+func (d *fileDelegate) load() (map[string]string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// compare contents rather than modification times, which may not change
+	// between writes on filesystems with coarse timestamps
+	payload, err := ioutil.ReadFile(d.path)
+	if err == nil {
+		digest := sha256.Sum256(payload)
+		if d.addresses != nil && digest == d.digest {
+			return d.addresses, nil
+		}
+
+		var addresses map[string]string
+		addresses, err = parseCells(d.path, payload)
+		if err == nil {
+			d.logger.Info("loaded-cells", lager.Data{"cells": len(addresses)})
+			d.digest = digest
+			d.addresses = addresses
+			return addresses, nil
+		}
+	}
+
+	if d.addresses == nil {
+		d.logger.Error("failed-to-load-cells", err)
+		return nil, err
+	}
+
+	d.logger.Error("failed-to-reload-cells", err)
+	return d.addresses, nil
+}
+
+func parseCells(path string, payload []byte) (map[string]string, error) {
+	var cells []Cell
+	var err error
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(payload, &cells)
+	default:
+		err = json.Unmarshal(payload, &cells)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := map[string]string{}
+	for _, cell := range cells {
+		addresses[cell.Guid] = cell.Address
+	}
+	return addresses, nil
+}
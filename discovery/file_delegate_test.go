@@ -0,0 +1,167 @@
+package discovery_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/cloudfoundry-incubator/auction/discovery"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileDelegate", func() {
+	var dir, path string
+	var rep *fakes.FakeSimulationCellRep
+	var server *httptest.Server
+	var delegate auctiontypes.AuctionRunnerDelegate
+
+	writeCells := func(contents string, modTime time.Time) {
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "discovery")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "cells.json")
+
+		logger := lagertest.NewTestLogger("test")
+		rep = &fakes.FakeSimulationCellRep{}
+		rep.StateReturns(auctiontypes.CellState{Zone: "Z0"}, nil)
+		handler, err := rata.NewRouter(routes.Routes, auction_http_handlers.New(rep, logger))
+		Expect(err).NotTo(HaveOccurred())
+		server = httptest.NewServer(handler)
+	})
+
+	JustBeforeEach(func() {
+		clients := discovery.NewClientCache(discovery.TransportConfig{Timeout: time.Second}, lagertest.NewTestLogger("test"))
+		delegate = discovery.NewFileDelegate(path, clients, nil, lagertest.NewTestLogger("test"))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("builds clients for the cells in the file", func() {
+		writeCells(`[{"guid": "cell-1", "address": "`+server.URL+`"}]`, time.Now())
+
+		cellReps, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cellReps).To(HaveLen(1))
+
+		state, err := cellReps["cell-1"].State()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Zone).To(Equal("Z0"))
+	})
+
+	It("keeps clients between auctions", func() {
+		writeCells(`[{"guid": "cell-1", "address": "`+server.URL+`"}]`, time.Now())
+
+		first, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second["cell-1"]).To(BeIdenticalTo(first["cell-1"]))
+	})
+
+	It("reloads the file when it changes", func() {
+		modTime := time.Now().Add(-time.Minute)
+		writeCells(`[{"guid": "cell-1", "address": "http://a"}]`, modTime)
+		first, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		writeCells(`[{"guid": "cell-1", "address": "http://b"}, {"guid": "cell-2", "address": "http://c"}]`, modTime.Add(time.Second))
+		second, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second).To(HaveLen(2))
+		Expect(second["cell-1"]).NotTo(BeIdenticalTo(first["cell-1"]))
+	})
+
+	It("reloads the file when it changes without a new modification time", func() {
+		modTime := time.Now().Add(-time.Minute)
+		writeCells(`[{"guid": "cell-1", "address": "http://a"}]`, modTime)
+		_, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		writeCells(`[{"guid": "cell-2", "address": "http://a"}]`, modTime)
+		cellReps, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cellReps).To(HaveLen(1))
+		Expect(cellReps).To(HaveKey("cell-2"))
+	})
+
+	It("keeps the last cells when the file becomes unreadable", func() {
+		writeCells(`[{"guid": "cell-1", "address": "http://a"}]`, time.Now().Add(-time.Minute))
+		_, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		writeCells(`not json`, time.Now())
+		cellReps, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cellReps).To(HaveKey("cell-1"))
+	})
+
+	It("errors when the file has never been read", func() {
+		_, err := delegate.FetchCellReps()
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("with a YAML file", func() {
+		BeforeEach(func() {
+			path = filepath.Join(dir, "cells.yml")
+		})
+
+		It("reads the cells as YAML", func() {
+			writeCells("- guid: cell-1\n  address: http://a\n- guid: cell-2\n  address: http://b\n", time.Now())
+
+			cellReps, err := delegate.FetchCellReps()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cellReps).To(HaveLen(2))
+			Expect(cellReps).To(HaveKey("cell-2"))
+		})
+	})
+})
+
+var _ = Describe("ClientCache", func() {
+	It("drops clients for cells that are no longer discovered", func() {
+		clients := discovery.NewClientCache(discovery.TransportConfig{}, lagertest.NewTestLogger("test"))
+
+		first := clients.ClientsFor(map[string]string{"cell-1": "http://a"})
+		Expect(clients.ClientsFor(map[string]string{})).To(BeEmpty())
+
+		second := clients.ClientsFor(map[string]string{"cell-1": "http://a"})
+		Expect(second["cell-1"]).NotTo(BeIdenticalTo(first["cell-1"]))
+	})
+
+	It("builds clients that share the configured timeout", func() {
+		blocked := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-blocked
+		}))
+		defer server.Close()
+		defer close(blocked)
+
+		clients := discovery.NewClientCache(discovery.TransportConfig{Timeout: 50 * time.Millisecond}, lagertest.NewTestLogger("test"))
+		reps := clients.ClientsFor(map[string]string{"cell-1": server.URL})
+
+		_, err := reps["cell-1"].State()
+		Expect(err).To(HaveOccurred())
+	})
+})
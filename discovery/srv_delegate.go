@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+//go:generate counterfeiter -o fakes/fake_resolver.go . Resolver
+
+// Resolver is satisfied by *net.Resolver.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type SRVConfig struct {
+	// Service, Proto and Name are looked up as _service._proto.name.  Leave
+	// Service and Proto empty to look up Name directly.
+	Service string
+	Proto   string
+	Name    string
+
+	// Scheme is used to build each cell's address.  It defaults to http.
+	Scheme string
+
+	// LookupTimeout bounds each lookup.  It defaults to five seconds.
+	LookupTimeout time.Duration
+}
+
+type srvDelegate struct {
+	resolver        Resolver
+	config          SRVConfig
+	clients         *ClientCache
+	resultsDelegate ResultsDelegate
+	logger          lager.Logger
+
+	addresses map[string]string
+	lock      *sync.Mutex
+}
+
+/*
+NewSRVDelegate returns an AuctionRunnerDelegate that discovers cells by
+resolving DNS SRV records before every auction.  Each record names one cell:
+the first label of its target is the cell guid, and the cell is reached at
+scheme://target:port.
+
+If a lookup fails, the cells found by the last successful lookup are used.
+*/
+func NewSRVDelegate(resolver Resolver, config SRVConfig, clients *ClientCache, resultsDelegate ResultsDelegate, logger lager.Logger) auctiontypes.AuctionRunnerDelegate {
+	if config.Scheme == "" {
+		config.Scheme = "http"
+	}
+	if config.LookupTimeout <= 0 {
+		config.LookupTimeout = 5 * time.Second
+	}
+
+	return &srvDelegate{
+		resolver:        resolver,
+		config:          config,
+		clients:         clients,
+		resultsDelegate: resultsDelegate,
+		logger:          logger.Session("srv-discovery", lager.Data{"name": config.Name}),
+		lock:            &sync.Mutex{},
+	}
+}
+
+func (d *srvDelegate) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	addresses, err := d.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.clients.ClientsFor(addresses), nil
+}
+
+func (d *srvDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	if d.resultsDelegate != nil {
+		d.resultsDelegate.AuctionCompleted(results)
+	}
+}
+
+func (d *srvDelegate) resolve() (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.LookupTimeout)
+	defer cancel()
+
+	_, records, err := d.resolver.LookupSRV(ctx, d.config.Service, d.config.Proto, d.config.Name)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if err != nil {
+		if d.addresses == nil {
+			d.logger.Error("failed-to-resolve-cells", err)
+			return nil, err
+		}
+		d.logger.Error("failed-to-re-resolve-cells", err)
+		return d.addresses, nil
+	}
+
+	addresses := map[string]string{}
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		guid := strings.SplitN(target, ".", 2)[0]
+		addresses[guid] = fmt.Sprintf("%s://%s", d.config.Scheme, net.JoinHostPort(target, fmt.Sprint(record.Port)))
+	}
+
+	d.addresses = addresses
+	return addresses, nil
+}
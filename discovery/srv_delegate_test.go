@@ -0,0 +1,109 @@
+package discovery_test
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/routes"
+	"github.com/cloudfoundry-incubator/auction/discovery"
+	discoveryfakes "github.com/cloudfoundry-incubator/auction/discovery/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SRVDelegate", func() {
+	var resolver *discoveryfakes.FakeResolver
+	var server *httptest.Server
+	var port uint16
+	var delegate auctiontypes.AuctionRunnerDelegate
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("test")
+		rep := &fakes.FakeSimulationCellRep{}
+		rep.StateReturns(auctiontypes.CellState{Zone: "Z1"}, nil)
+		handler, err := rata.NewRouter(routes.Routes, auction_http_handlers.New(rep, logger))
+		Expect(err).NotTo(HaveOccurred())
+		server = httptest.NewServer(handler)
+
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		_, portString, err := net.SplitHostPort(serverURL.Host)
+		Expect(err).NotTo(HaveOccurred())
+		parsedPort, err := strconv.Atoi(portString)
+		Expect(err).NotTo(HaveOccurred())
+		port = uint16(parsedPort)
+
+		resolver = &discoveryfakes.FakeResolver{}
+		resolver.LookupSRVReturns("", []*net.SRV{
+			{Target: "localhost.", Port: port},
+		}, nil)
+
+		clients := discovery.NewClientCache(discovery.TransportConfig{Timeout: time.Second}, logger)
+		delegate = discovery.NewSRVDelegate(resolver, discovery.SRVConfig{
+			Service: "cell",
+			Proto:   "tcp",
+			Name:    "cells.example.com",
+		}, clients, nil, logger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("looks up the configured record", func() {
+		_, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, service, proto, name := resolver.LookupSRVArgsForCall(0)
+		Expect(service).To(Equal("cell"))
+		Expect(proto).To(Equal("tcp"))
+		Expect(name).To(Equal("cells.example.com"))
+	})
+
+	It("builds a client for each record, named by the first label of its target", func() {
+		cellReps, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cellReps).To(HaveLen(1))
+
+		state, err := cellReps["localhost"].State()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Zone).To(Equal("Z1"))
+	})
+
+	It("keeps clients between auctions", func() {
+		first, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resolver.LookupSRVCallCount()).To(Equal(2))
+		Expect(second["localhost"]).To(BeIdenticalTo(first["localhost"]))
+	})
+
+	It("keeps the last cells when a lookup fails", func() {
+		_, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+
+		resolver.LookupSRVReturns("", nil, errors.New("SERVFAIL"))
+		cellReps, err := delegate.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cellReps).To(HaveKey("localhost"))
+	})
+
+	It("errors when no lookup has succeeded", func() {
+		resolver.LookupSRVReturns("", nil, errors.New("SERVFAIL"))
+		_, err := delegate.FetchCellReps()
+		Expect(err).To(HaveOccurred())
+	})
+})
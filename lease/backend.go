@@ -0,0 +1,17 @@
+package lease
+
+import "time"
+
+/*
+A Backend stores a single lease shared by a set of would-be owners.
+
+Acquire claims the lease for owner if it is unheld, expired, or already held by
+owner.  Renew extends a lease that owner still holds, and fails if another owner
+has claimed it since.  Release gives up the lease if owner holds it.
+*/
+//go:generate counterfeiter -o fakes/fake_backend.go . Backend
+type Backend interface {
+	Acquire(owner string, ttl time.Duration) (bool, error)
+	Renew(owner string, ttl time.Duration) (bool, error)
+	Release(owner string) error
+}
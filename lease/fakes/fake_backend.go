@@ -0,0 +1,142 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/lease"
+)
+
+type FakeBackend struct {
+	AcquireStub        func(owner string, ttl time.Duration) (bool, error)
+	acquireMutex       sync.RWMutex
+	acquireArgsForCall []struct {
+		owner string
+		ttl   time.Duration
+	}
+	acquireReturns struct {
+		result1 bool
+		result2 error
+	}
+	RenewStub        func(owner string, ttl time.Duration) (bool, error)
+	renewMutex       sync.RWMutex
+	renewArgsForCall []struct {
+		owner string
+		ttl   time.Duration
+	}
+	renewReturns struct {
+		result1 bool
+		result2 error
+	}
+	ReleaseStub        func(owner string) error
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		owner string
+	}
+	releaseReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeBackend) Acquire(owner string, ttl time.Duration) (bool, error) {
+	fake.acquireMutex.Lock()
+	fake.acquireArgsForCall = append(fake.acquireArgsForCall, struct {
+		owner string
+		ttl   time.Duration
+	}{owner, ttl})
+	fake.acquireMutex.Unlock()
+	if fake.AcquireStub != nil {
+		return fake.AcquireStub(owner, ttl)
+	} else {
+		return fake.acquireReturns.result1, fake.acquireReturns.result2
+	}
+}
+
+func (fake *FakeBackend) AcquireCallCount() int {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return len(fake.acquireArgsForCall)
+}
+
+func (fake *FakeBackend) AcquireArgsForCall(i int) (string, time.Duration) {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return fake.acquireArgsForCall[i].owner, fake.acquireArgsForCall[i].ttl
+}
+
+func (fake *FakeBackend) AcquireReturns(result1 bool, result2 error) {
+	fake.AcquireStub = nil
+	fake.acquireReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) Renew(owner string, ttl time.Duration) (bool, error) {
+	fake.renewMutex.Lock()
+	fake.renewArgsForCall = append(fake.renewArgsForCall, struct {
+		owner string
+		ttl   time.Duration
+	}{owner, ttl})
+	fake.renewMutex.Unlock()
+	if fake.RenewStub != nil {
+		return fake.RenewStub(owner, ttl)
+	} else {
+		return fake.renewReturns.result1, fake.renewReturns.result2
+	}
+}
+
+func (fake *FakeBackend) RenewCallCount() int {
+	fake.renewMutex.RLock()
+	defer fake.renewMutex.RUnlock()
+	return len(fake.renewArgsForCall)
+}
+
+func (fake *FakeBackend) RenewArgsForCall(i int) (string, time.Duration) {
+	fake.renewMutex.RLock()
+	defer fake.renewMutex.RUnlock()
+	return fake.renewArgsForCall[i].owner, fake.renewArgsForCall[i].ttl
+}
+
+func (fake *FakeBackend) RenewReturns(result1 bool, result2 error) {
+	fake.RenewStub = nil
+	fake.renewReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) Release(owner string) error {
+	fake.releaseMutex.Lock()
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		owner string
+	}{owner})
+	fake.releaseMutex.Unlock()
+	if fake.ReleaseStub != nil {
+		return fake.ReleaseStub(owner)
+	} else {
+		return fake.releaseReturns.result1
+	}
+}
+
+func (fake *FakeBackend) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *FakeBackend) ReleaseArgsForCall(i int) string {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return fake.releaseArgsForCall[i].owner
+}
+
+func (fake *FakeBackend) ReleaseReturns(result1 error) {
+	fake.ReleaseStub = nil
+	fake.releaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ lease.Backend = new(FakeBackend)
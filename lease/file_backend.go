@@ -0,0 +1,115 @@
+package lease
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+)
+
+type fileRecord struct {
+	Owner     string    `json:"owner"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type fileBackend struct {
+	path  string
+	clock clock.Clock
+}
+
+/*
+NewFileBackend returns a Backend that keeps the lease in a file on the local
+filesystem.  Every read-modify-write of the file happens under an exclusive
+flock, so it coordinates auctioneers on the same host (or sharing a filesystem
+with working flock semantics).  The lock is only held for the duration of the
+update; the owner recorded in the file holds the lease until it expires.
+*/
+func NewFileBackend(path string, clock clock.Clock) Backend {
+	return &fileBackend{
+		path:  path,
+		clock: clock,
+	}
+}
+
+func (b *fileBackend) Acquire(owner string, ttl time.Duration) (bool, error) {
+	return b.update(func(record *fileRecord, now time.Time) bool {
+		if record.Owner != "" && record.Owner != owner && now.Before(record.ExpiresAt) {
+			return false
+		}
+		record.Owner = owner
+		record.ExpiresAt = now.Add(ttl)
+		return true
+	})
+}
+
+func (b *fileBackend) Renew(owner string, ttl time.Duration) (bool, error) {
+	return b.update(func(record *fileRecord, now time.Time) bool {
+		if record.Owner != owner {
+			return false
+		}
+		record.ExpiresAt = now.Add(ttl)
+		return true
+	})
+}
+
+func (b *fileBackend) Release(owner string) error {
+	_, err := b.update(func(record *fileRecord, now time.Time) bool {
+		if record.Owner != owner {
+			return false
+		}
+		*record = fileRecord{}
+		return true
+	})
+	return err
+}
+
+func (b *fileBackend) update(modify func(*fileRecord, time.Time) bool) (bool, error) {
+	file, err := os.OpenFile(b.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		return false, err
+	}
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return false, err
+	}
+
+	record := fileRecord{}
+	if len(contents) > 0 {
+		err = json.Unmarshal(contents, &record)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if !modify(&record, b.clock.Now()) {
+		return false, nil
+	}
+
+	contents, err = json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+
+	err = file.Truncate(0)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = file.WriteAt(contents, 0)
+	if err != nil {
+		return false, err
+	}
+
+	return true, file.Sync()
+}
@@ -0,0 +1,107 @@
+package lease_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	"github.com/cloudfoundry-incubator/auction/lease"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileBackend", func() {
+	var tmpDir string
+	var clock *fakeclock.FakeClock
+	var backend, otherBackend lease.Backend
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "lease")
+		Expect(err).NotTo(HaveOccurred())
+
+		clock = fakeclock.NewFakeClock(time.Now())
+		path := filepath.Join(tmpDir, "auctioneer.lease")
+		backend = lease.NewFileBackend(path, clock)
+		otherBackend = lease.NewFileBackend(path, clock)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("grants an unheld lease", func() {
+		Expect(backend.Acquire("a", time.Minute)).To(BeTrue())
+	})
+
+	Context("when the lease is held", func() {
+		BeforeEach(func() {
+			Expect(backend.Acquire("a", time.Minute)).To(BeTrue())
+		})
+
+		It("does not grant it to another owner", func() {
+			Expect(otherBackend.Acquire("b", time.Minute)).To(BeFalse())
+		})
+
+		It("lets the holder acquire it again", func() {
+			Expect(backend.Acquire("a", time.Minute)).To(BeTrue())
+		})
+
+		It("lets the holder renew it", func() {
+			clock.Increment(50 * time.Second)
+			Expect(backend.Renew("a", time.Minute)).To(BeTrue())
+
+			clock.Increment(50 * time.Second)
+			Expect(otherBackend.Acquire("b", time.Minute)).To(BeFalse())
+		})
+
+		It("does not let another owner renew it", func() {
+			Expect(otherBackend.Renew("b", time.Minute)).To(BeFalse())
+		})
+
+		Context("when it expires", func() {
+			BeforeEach(func() {
+				clock.Increment(time.Minute)
+			})
+
+			It("grants it to another owner", func() {
+				Expect(otherBackend.Acquire("b", time.Minute)).To(BeTrue())
+			})
+
+			It("does not let the old holder renew it once another owner has it", func() {
+				Expect(otherBackend.Acquire("b", time.Minute)).To(BeTrue())
+				Expect(backend.Renew("a", time.Minute)).To(BeFalse())
+			})
+		})
+
+		Context("when the holder releases it", func() {
+			It("grants it to another owner", func() {
+				Expect(backend.Release("a")).To(Succeed())
+				Expect(otherBackend.Acquire("b", time.Minute)).To(BeTrue())
+			})
+		})
+
+		Context("when another owner releases it", func() {
+			It("leaves it with the holder", func() {
+				Expect(otherBackend.Release("b")).To(Succeed())
+				Expect(otherBackend.Acquire("b", time.Minute)).To(BeFalse())
+			})
+		})
+	})
+
+	Context("when the lease file is corrupt", func() {
+		BeforeEach(func() {
+			err := ioutil.WriteFile(filepath.Join(tmpDir, "auctioneer.lease"), []byte("{"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("errors", func() {
+			_, err := backend.Acquire("a", time.Minute)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package lease
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+var ErrLeaseLost = errors.New("lease lost")
+
+type Config struct {
+	Owner string
+	TTL   time.Duration
+
+	// RetryInterval is how often a standby tries to acquire the lease, and
+	// how often the holder renews it.  It defaults to a third of the TTL.
+	RetryInterval time.Duration
+}
+
+type leaseRunner struct {
+	backend Backend
+	config  Config
+	runner  ifrit.Runner
+	clock   clock.Clock
+	logger  lager.Logger
+}
+
+/*
+NewRunner returns an ifrit.Runner that stands by until it acquires the lease
+and then runs the wrapped runner for as long as it holds it.
+
+Failing to renew is tolerated until the lease would have expired.  Once the
+lease is lost the wrapped runner is interrupted and Run returns ErrLeaseLost.
+*/
+func NewRunner(backend Backend, config Config, runner ifrit.Runner, clock clock.Clock, logger lager.Logger) ifrit.Runner {
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = config.TTL / 3
+	}
+
+	return &leaseRunner{
+		backend: backend,
+		config:  config,
+		runner:  runner,
+		clock:   clock,
+		logger:  logger.Session("lease", lager.Data{"owner": config.Owner}),
+	}
+}
+
+func (l *leaseRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	ticker := l.clock.NewTicker(l.config.RetryInterval)
+	defer ticker.Stop()
+
+	for !l.acquire() {
+		select {
+		case <-ticker.C():
+		case <-signals:
+			return nil
+		}
+	}
+
+	expiresAt := l.clock.Now().Add(l.config.TTL)
+	l.logger.Info("acquired-lease", lager.Data{"expires-at": expiresAt})
+
+	process := ifrit.Background(l.runner)
+	exited := process.Wait()
+
+	for {
+		select {
+		case signal := <-signals:
+			process.Signal(signal)
+			err := <-exited
+			l.release()
+			return err
+
+		case err := <-exited:
+			l.release()
+			return err
+
+		case <-ticker.C():
+			renewed, err := l.backend.Renew(l.config.Owner, l.config.TTL)
+			if err == nil && renewed {
+				expiresAt = l.clock.Now().Add(l.config.TTL)
+				continue
+			}
+
+			if err != nil && l.clock.Now().Before(expiresAt) {
+				l.logger.Error("failed-to-renew-lease", err, lager.Data{"expires-at": expiresAt})
+				continue
+			}
+
+			l.logger.Error("lost-lease", err)
+			process.Signal(os.Interrupt)
+			<-exited
+			return ErrLeaseLost
+		}
+	}
+}
+
+func (l *leaseRunner) acquire() bool {
+	acquired, err := l.backend.Acquire(l.config.Owner, l.config.TTL)
+	if err != nil {
+		l.logger.Error("failed-to-acquire-lease", err)
+		return false
+	}
+	return acquired
+}
+
+func (l *leaseRunner) release() {
+	err := l.backend.Release(l.config.Owner)
+	if err != nil {
+		l.logger.Error("failed-to-release-lease", err)
+		return
+	}
+	l.logger.Info("released-lease")
+}
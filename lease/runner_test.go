@@ -0,0 +1,135 @@
+package lease_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/auction/lease"
+	"github.com/cloudfoundry-incubator/auction/lease/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Runner", func() {
+	var backend *fakes.FakeBackend
+	var clock *fakeclock.FakeClock
+	var started, stopped chan struct{}
+	var process ifrit.Process
+
+	BeforeEach(func() {
+		backend = &fakes.FakeBackend{}
+		backend.AcquireReturns(true, nil)
+		backend.RenewReturns(true, nil)
+
+		clock = fakeclock.NewFakeClock(time.Now())
+		started = make(chan struct{})
+		stopped = make(chan struct{})
+	})
+
+	JustBeforeEach(func() {
+		wrapped := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+			close(ready)
+			close(started)
+			<-signals
+			close(stopped)
+			return nil
+		})
+
+		config := lease.Config{Owner: "me", TTL: 30 * time.Second, RetryInterval: 10 * time.Second}
+		process = ifrit.Invoke(lease.NewRunner(backend, config, wrapped, clock, lagertest.NewTestLogger("test")))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("runs the wrapped runner once it acquires the lease", func() {
+		Eventually(started).Should(BeClosed())
+
+		owner, ttl := backend.AcquireArgsForCall(0)
+		Expect(owner).To(Equal("me"))
+		Expect(ttl).To(Equal(30 * time.Second))
+	})
+
+	It("renews the lease", func() {
+		Eventually(started).Should(BeClosed())
+
+		clock.Increment(10 * time.Second)
+		Eventually(backend.RenewCallCount).Should(Equal(1))
+	})
+
+	It("stops the wrapped runner and releases the lease when signaled", func() {
+		Eventually(started).Should(BeClosed())
+
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(stopped).To(BeClosed())
+		Expect(backend.ReleaseCallCount()).To(Equal(1))
+	})
+
+	Context("when the lease is held elsewhere", func() {
+		BeforeEach(func() {
+			backend.AcquireReturns(false, nil)
+		})
+
+		It("stands by, retrying on the interval", func() {
+			Eventually(backend.AcquireCallCount).Should(Equal(1))
+			Consistently(started).ShouldNot(BeClosed())
+
+			backend.AcquireReturns(true, nil)
+			clock.Increment(10 * time.Second)
+
+			Eventually(started).Should(BeClosed())
+			Expect(backend.AcquireCallCount()).To(Equal(2))
+		})
+
+		It("exits cleanly when signaled", func() {
+			Eventually(backend.AcquireCallCount).Should(Equal(1))
+
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(backend.ReleaseCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the lease is taken by another owner", func() {
+		BeforeEach(func() {
+			backend.RenewReturns(false, nil)
+		})
+
+		It("stops the wrapped runner and exits with ErrLeaseLost", func() {
+			Eventually(started).Should(BeClosed())
+
+			clock.Increment(10 * time.Second)
+			Eventually(process.Wait()).Should(Receive(Equal(lease.ErrLeaseLost)))
+			Expect(stopped).To(BeClosed())
+		})
+	})
+
+	Context("when renewing fails", func() {
+		BeforeEach(func() {
+			backend.RenewReturns(false, errors.New("boom"))
+		})
+
+		It("keeps running until the lease would have expired", func() {
+			Eventually(started).Should(BeClosed())
+
+			clock.Increment(10 * time.Second)
+			Eventually(backend.RenewCallCount).Should(Equal(1))
+			clock.Increment(10 * time.Second)
+			Eventually(backend.RenewCallCount).Should(Equal(2))
+			Consistently(stopped).ShouldNot(BeClosed())
+
+			clock.Increment(10 * time.Second)
+			Eventually(process.Wait()).Should(Receive(Equal(lease.ErrLeaseLost)))
+			Expect(stopped).To(BeClosed())
+		})
+	})
+})
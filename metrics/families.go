@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// family is one named metric, with a series per combination of label values,
+// rendered in the Prometheus text exposition format.  families are not safe
+// for concurrent use; the emitter serializes access to them.
+type family struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	buckets    []float64
+
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+
+	// histograms only
+	bucketCounts []uint64
+	count        uint64
+}
+
+func newCounter(name, help string, labelNames ...string) *family {
+	return &family{name: name, help: help, kind: "counter", labelNames: labelNames, series: map[string]*series{}}
+}
+
+func newGauge(name, help string, labelNames ...string) *family {
+	return &family{name: name, help: help, kind: "gauge", labelNames: labelNames, series: map[string]*series{}}
+}
+
+func newHistogram(name, help string, buckets []float64, labelNames ...string) *family {
+	return &family{name: name, help: help, kind: "histogram", labelNames: labelNames, buckets: buckets, series: map[string]*series{}}
+}
+
+func (f *family) with(labelValues ...string) *series {
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: labelValues, bucketCounts: make([]uint64, len(f.buckets))}
+		f.series[key] = s
+	}
+	return s
+}
+
+func (f *family) add(delta float64, labelValues ...string) {
+	f.with(labelValues...).value += delta
+}
+
+func (f *family) set(value float64, labelValues ...string) {
+	f.with(labelValues...).value = value
+}
+
+func (f *family) observe(value float64, labelValues ...string) {
+	s := f.with(labelValues...)
+	for i, upperBound := range f.buckets {
+		if value <= upperBound {
+			s.bucketCounts[i]++
+		}
+	}
+	s.count++
+	s.value += value
+}
+
+func (f *family) reset() {
+	f.series = map[string]*series{}
+}
+
+func (f *family) writeTo(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.labels(s.labelValues), formatFloat(s.value))
+			continue
+		}
+
+		for i, upperBound := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", formatFloat(upperBound)), s.bucketCounts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labels(s.labelValues), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labels(s.labelValues), s.count)
+	}
+}
+
+func (f *family) labels(labelValues []string, extra ...string) string {
+	pairs := []string{}
+	for i, name := range f.labelNames {
+		pairs = append(pairs, name+`="`+labelValueEscaper.Replace(labelValues[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+labelValueEscaper.Replace(extra[i+1])+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
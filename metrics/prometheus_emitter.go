@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+var waitBuckets = []float64{.1, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+var attemptBuckets = []float64{1, 2, 3, 5, 10, 20}
+
+type scrapedCell struct {
+	guid string
+	rep  auctiontypes.CellRep
+}
+
+/*
+PrometheusEmitter is an AuctionMetricEmitterDelegate that aggregates what the
+auction runner reports and serves it, as an http.Handler, in the Prometheus
+text format.
+
+Queue depth is read from the reporter passed to ScrapeQueue, and cell capacity
+from the reps passed to ScrapeCell, each time the metrics are served.  Reps can
+mount an emitter that only scrapes themselves.
+*/
+type PrometheusEmitter struct {
+	logger lager.Logger
+
+	stateFetchDuration        *family
+	lrpAuctions               *family
+	taskAuctions              *family
+	placementErrors           *family
+	waitDuration              *family
+	attempts                  *family
+	queuedAuctions            *family
+	zoneCells                 *family
+	zoneAvailable             *family
+	zoneTotal                 *family
+	circuitBreakerTransitions *family
+
+	queue auctiontypes.QueueStatusReporter
+	cells []scrapedCell
+	lock  *sync.Mutex
+}
+
+func NewPrometheusEmitter(logger lager.Logger) *PrometheusEmitter {
+	return &PrometheusEmitter{
+		logger: logger.Session("prometheus-emitter"),
+
+		stateFetchDuration: newHistogram("auction_state_fetch_duration_seconds", "Time taken to fetch the state of every cell before an auction.", latencyBuckets),
+		lrpAuctions:        newCounter("auction_lrp_auctions_total", "Completed LRP start auctions by outcome.", "outcome"),
+		taskAuctions:       newCounter("auction_task_auctions_total", "Completed task auctions by outcome.", "outcome"),
+		placementErrors:    newCounter("auction_placement_errors_total", "Failed auctions by kind and placement error.", "kind", "error"),
+		waitDuration:       newHistogram("auction_wait_duration_seconds", "Time successful auctions spent queued before being placed.", waitBuckets, "kind"),
+		attempts:           newHistogram("auction_attempts", "Attempts taken by completed auctions.", attemptBuckets, "kind"),
+		queuedAuctions:     newGauge("auction_queued_auctions", "Auctions waiting for the next auction round.", "kind"),
+		zoneCells:          newGauge("auction_zone_cells", "Cells that took part in the last auction, by zone.", "zone"),
+		zoneAvailable:      newGauge("auction_zone_available_capacity", "Free capacity of the cells in each zone.", "zone", "resource"),
+		zoneTotal:          newGauge("auction_zone_total_capacity", "Total capacity of the cells in each zone.", "zone", "resource"),
+
+		circuitBreakerTransitions: newCounter("auction_circuit_breaker_transitions_total", "Cell circuit breaker transitions by the state entered.", "to"),
+
+		lock: &sync.Mutex{},
+	}
+}
+
+// ScrapeQueue reports reporter's queue depth, typically the auction runner's.
+func (e *PrometheusEmitter) ScrapeQueue(reporter auctiontypes.QueueStatusReporter) {
+	e.lock.Lock()
+	e.queue = reporter
+	e.lock.Unlock()
+}
+
+// ScrapeCell reports rep's capacity under its zone.
+func (e *PrometheusEmitter) ScrapeCell(guid string, rep auctiontypes.CellRep) {
+	e.lock.Lock()
+	e.cells = append(e.cells, scrapedCell{guid: guid, rep: rep})
+	e.lock.Unlock()
+}
+
+func (e *PrometheusEmitter) FetchStatesCompleted(duration time.Duration) {
+	e.lock.Lock()
+	e.stateFetchDuration.observe(duration.Seconds())
+	e.lock.Unlock()
+}
+
+func (e *PrometheusEmitter) AuctionCompleted(results auctiontypes.AuctionResults) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.lrpAuctions.add(float64(len(results.SuccessfulLRPs)), string(auctiontypes.AuctionSucceeded))
+	e.lrpAuctions.add(float64(len(results.FailedLRPs)), string(auctiontypes.AuctionFailed))
+	e.lrpAuctions.add(float64(len(results.AlreadyRunningLRPs)), string(auctiontypes.AuctionAlreadyRunning))
+	e.taskAuctions.add(float64(len(results.SuccessfulTasks)), string(auctiontypes.AuctionSucceeded))
+	e.taskAuctions.add(float64(len(results.FailedTasks)), string(auctiontypes.AuctionFailed))
+	e.taskAuctions.add(float64(len(results.AlreadyRunningTasks)), string(auctiontypes.AuctionAlreadyRunning))
+
+	for _, lrpAuction := range results.SuccessfulLRPs {
+		e.waitDuration.observe(lrpAuction.WaitDuration.Seconds(), "lrp")
+		e.attempts.observe(float64(lrpAuction.Attempts), "lrp")
+	}
+	for _, lrpAuction := range results.FailedLRPs {
+		e.placementErrors.add(1, "lrp", lrpAuction.PlacementError)
+		e.attempts.observe(float64(lrpAuction.Attempts), "lrp")
+	}
+	for _, taskAuction := range results.SuccessfulTasks {
+		e.waitDuration.observe(taskAuction.WaitDuration.Seconds(), "task")
+		e.attempts.observe(float64(taskAuction.Attempts), "task")
+	}
+	for _, taskAuction := range results.FailedTasks {
+		e.placementErrors.add(1, "task", taskAuction.PlacementError)
+		e.attempts.observe(float64(taskAuction.Attempts), "task")
+	}
+}
+
+func (e *PrometheusEmitter) ZoneCapacitiesFetched(capacities map[string]auctiontypes.ZoneCapacity) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.zoneCells.reset()
+	e.zoneAvailable.reset()
+	e.zoneTotal.reset()
+	for zone, capacity := range capacities {
+		e.recordZoneCapacity(zone, capacity)
+	}
+}
+
+func (e *PrometheusEmitter) CircuitBreakerTransitioned(cellGuid string, from, to auctiontypes.CircuitBreakerState) {
+	e.lock.Lock()
+	e.circuitBreakerTransitions.add(1, string(to))
+	e.lock.Unlock()
+}
+
+func (e *PrometheusEmitter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.scrape()
+
+	e.lock.Lock()
+	buffer := &bytes.Buffer{}
+	for _, f := range []*family{
+		e.stateFetchDuration,
+		e.lrpAuctions,
+		e.taskAuctions,
+		e.placementErrors,
+		e.waitDuration,
+		e.attempts,
+		e.queuedAuctions,
+		e.zoneCells,
+		e.zoneAvailable,
+		e.zoneTotal,
+		e.circuitBreakerTransitions,
+	} {
+		f.writeTo(buffer)
+	}
+	e.lock.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buffer.Bytes())
+}
+
+func (e *PrometheusEmitter) scrape() {
+	e.lock.Lock()
+	queue := e.queue
+	cells := e.cells
+	e.lock.Unlock()
+
+	if queue != nil {
+		status := queue.QueueStatus()
+		e.lock.Lock()
+		e.queuedAuctions.set(float64(status.LRPAuctions), "lrp")
+		e.queuedAuctions.set(float64(status.TaskAuctions), "task")
+		e.lock.Unlock()
+	}
+
+	if len(cells) == 0 {
+		return
+	}
+
+	capacities := map[string]auctiontypes.ZoneCapacity{}
+	for _, cell := range cells {
+		state, err := cell.rep.State()
+		if err != nil {
+			e.logger.Error("failed-to-scrape-cell", err, lager.Data{"cell-guid": cell.guid})
+			continue
+		}
+
+		capacity := capacities[state.Zone]
+		capacity.Add(state)
+		capacities[state.Zone] = capacity
+	}
+
+	e.ZoneCapacitiesFetched(capacities)
+}
+
+// recordZoneCapacity must be called with the lock held
+func (e *PrometheusEmitter) recordZoneCapacity(zone string, capacity auctiontypes.ZoneCapacity) {
+	e.zoneCells.set(float64(capacity.Cells), zone)
+	e.zoneAvailable.set(float64(capacity.AvailableResources.MemoryMB), zone, "memory_mb")
+	e.zoneAvailable.set(float64(capacity.AvailableResources.DiskMB), zone, "disk_mb")
+	e.zoneAvailable.set(float64(capacity.AvailableResources.Containers), zone, "containers")
+	e.zoneTotal.set(float64(capacity.TotalResources.MemoryMB), zone, "memory_mb")
+	e.zoneTotal.set(float64(capacity.TotalResources.DiskMB), zone, "disk_mb")
+	e.zoneTotal.set(float64(capacity.TotalResources.Containers), zone, "containers")
+}
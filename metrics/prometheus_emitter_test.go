@@ -0,0 +1,123 @@
+package metrics_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/metrics"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrometheusEmitter", func() {
+	var emitter *metrics.PrometheusEmitter
+
+	BeforeEach(func() {
+		emitter = metrics.NewPrometheusEmitter(lagertest.NewTestLogger("test"))
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		emitter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(ContainSubstring("text/plain"))
+
+		body, err := ioutil.ReadAll(recorder.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(body)
+	}
+
+	It("describes every metric", func() {
+		body := scrape()
+		Expect(body).To(ContainSubstring("# TYPE auction_state_fetch_duration_seconds histogram\n"))
+		Expect(body).To(ContainSubstring("# TYPE auction_lrp_auctions_total counter\n"))
+		Expect(body).To(ContainSubstring("# TYPE auction_zone_available_capacity gauge\n"))
+	})
+
+	It("buckets state fetch latencies", func() {
+		emitter.FetchStatesCompleted(30 * time.Millisecond)
+		emitter.FetchStatesCompleted(2 * time.Second)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("auction_state_fetch_duration_seconds_bucket{le=\"0.025\"} 0\n"))
+		Expect(body).To(ContainSubstring("auction_state_fetch_duration_seconds_bucket{le=\"0.05\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_state_fetch_duration_seconds_bucket{le=\"+Inf\"} 2\n"))
+		Expect(body).To(ContainSubstring("auction_state_fetch_duration_seconds_sum 2.03\n"))
+		Expect(body).To(ContainSubstring("auction_state_fetch_duration_seconds_count 2\n"))
+	})
+
+	It("counts auctions by outcome and placement error, and records waits and attempts", func() {
+		emitter.AuctionCompleted(auctiontypes.AuctionResults{
+			SuccessfulLRPs: []auctiontypes.LRPAuction{
+				{DesiredLRP: models.DesiredLRP{ProcessGuid: "pg-1"}, AuctionRecord: auctiontypes.AuctionRecord{Attempts: 1, WaitDuration: 2 * time.Second}},
+			},
+			FailedLRPs: []auctiontypes.LRPAuction{
+				{DesiredLRP: models.DesiredLRP{ProcessGuid: "pg-2"}, AuctionRecord: auctiontypes.AuctionRecord{Attempts: 3, PlacementError: "insufficient resources"}},
+			},
+			FailedTasks: []auctiontypes.TaskAuction{
+				{Task: models.Task{TaskGuid: "tg-1"}, AuctionRecord: auctiontypes.AuctionRecord{Attempts: 1, PlacementError: "found no compatible cell"}},
+			},
+		})
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("auction_lrp_auctions_total{outcome=\"succeeded\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_lrp_auctions_total{outcome=\"failed\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_task_auctions_total{outcome=\"failed\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_placement_errors_total{kind=\"lrp\",error=\"insufficient resources\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_placement_errors_total{kind=\"task\",error=\"found no compatible cell\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_wait_duration_seconds_sum{kind=\"lrp\"} 2\n"))
+		Expect(body).To(ContainSubstring("auction_attempts_bucket{kind=\"lrp\",le=\"2\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_attempts_count{kind=\"lrp\"} 2\n"))
+	})
+
+	It("reports the free capacity of each zone from the last state fetch", func() {
+		emitter.ZoneCapacitiesFetched(map[string]auctiontypes.ZoneCapacity{
+			"z1": {Cells: 2, AvailableResources: auctiontypes.Resources{MemoryMB: 50, DiskMB: 60, Containers: 7}},
+		})
+		emitter.ZoneCapacitiesFetched(map[string]auctiontypes.ZoneCapacity{
+			"z2": {Cells: 1, AvailableResources: auctiontypes.Resources{MemoryMB: 10}},
+		})
+
+		body := scrape()
+		Expect(body).NotTo(ContainSubstring("zone=\"z1\""))
+		Expect(body).To(ContainSubstring("auction_zone_cells{zone=\"z2\"} 1\n"))
+		Expect(body).To(ContainSubstring("auction_zone_available_capacity{zone=\"z2\",resource=\"memory_mb\"} 10\n"))
+	})
+
+	It("scrapes the queue depth", func() {
+		queue := &fakes.FakeQueueStatusReporter{}
+		queue.QueueStatusReturns(auctiontypes.QueueStatus{LRPAuctions: 4, TaskAuctions: 2})
+		emitter.ScrapeQueue(queue)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("auction_queued_auctions{kind=\"lrp\"} 4\n"))
+		Expect(body).To(ContainSubstring("auction_queued_auctions{kind=\"task\"} 2\n"))
+	})
+
+	It("scrapes the capacity of cells", func() {
+		rep := &fakes.FakeSimulationCellRep{}
+		rep.StateReturns(auctiontypes.CellState{
+			Zone:               "z1",
+			AvailableResources: auctiontypes.Resources{MemoryMB: 30, DiskMB: 40, Containers: 5},
+			TotalResources:     auctiontypes.Resources{MemoryMB: 100, DiskMB: 100, Containers: 10},
+		}, nil)
+		emitter.ScrapeCell("cell", rep)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("auction_zone_available_capacity{zone=\"z1\",resource=\"disk_mb\"} 40\n"))
+		Expect(body).To(ContainSubstring("auction_zone_total_capacity{zone=\"z1\",resource=\"containers\"} 10\n"))
+	})
+
+	It("escapes label values", func() {
+		emitter.ZoneCapacitiesFetched(map[string]auctiontypes.ZoneCapacity{"a \"quoted\"\nzone": {Cells: 1}})
+		Expect(scrape()).To(ContainSubstring(`auction_zone_cells{zone="a \"quoted\"\nzone"} 1`))
+	})
+})
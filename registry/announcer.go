@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"os"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+/*
+Announcer runs alongside a rep.  It registers the rep with the registry, then
+pushes a delta every interval, or as soon as StateChanged is called.  Deltas
+with no changes serve as heartbeats.  It deregisters the rep when signaled.
+*/
+type Announcer struct {
+	client   RegistrationClient
+	rep      auctiontypes.CellRep
+	cellGuid string
+	address  string
+	interval time.Duration
+	clock    clock.Clock
+	logger   lager.Logger
+
+	changed    chan struct{}
+	registered bool
+	lastState  auctiontypes.CellState
+}
+
+func NewAnnouncer(
+	client RegistrationClient,
+	rep auctiontypes.CellRep,
+	cellGuid string,
+	address string,
+	interval time.Duration,
+	clock clock.Clock,
+	logger lager.Logger,
+) *Announcer {
+	return &Announcer{
+		client:   client,
+		rep:      rep,
+		cellGuid: cellGuid,
+		address:  address,
+		interval: interval,
+		clock:    clock,
+		logger:   logger.Session("announcer", lager.Data{"cell-guid": cellGuid}),
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// StateChanged asks the announcer to push the rep's state now, rather than
+// waiting for the next heartbeat.
+func (a *Announcer) StateChanged() {
+	select {
+	case a.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (a *Announcer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	ticker := a.clock.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	a.announce()
+	close(ready)
+
+	for {
+		select {
+		case <-ticker.C():
+			a.announce()
+		case <-a.changed:
+			a.announce()
+		case <-signals:
+			err := a.client.Deregister(a.cellGuid)
+			if err != nil {
+				a.logger.Error("failed-to-deregister", err)
+			}
+			return nil
+		}
+	}
+}
+
+func (a *Announcer) announce() {
+	state, err := a.rep.State()
+	if err != nil {
+		a.logger.Error("failed-to-fetch-state", err)
+		return
+	}
+
+	if !a.registered {
+		a.register(state)
+		return
+	}
+
+	delta, ok := Diff(a.cellGuid, a.lastState, state)
+	if !ok {
+		a.register(state)
+		return
+	}
+
+	err = a.client.Update(delta)
+	switch err {
+	case nil:
+		a.lastState = state
+	case ErrUnknownCell, ErrGenerationMismatch:
+		a.logger.Info("registering-again", lager.Data{"reason": err.Error()})
+		a.register(state)
+	default:
+		a.logger.Error("failed-to-push-state", err)
+	}
+}
+
+func (a *Announcer) register(state auctiontypes.CellState) {
+	err := a.client.Register(Registration{
+		CellGuid: a.cellGuid,
+		Address:  a.address,
+		State:    state,
+	})
+	if err != nil {
+		a.logger.Error("failed-to-register", err)
+		a.registered = false
+		return
+	}
+
+	a.registered = true
+	a.lastState = state
+}
@@ -0,0 +1,88 @@
+package registry_test
+
+import (
+	"os"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/registry"
+	registryfakes "github.com/cloudfoundry-incubator/auction/registry/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Announcer", func() {
+	var clock *fakeclock.FakeClock
+	var rep *fakes.FakeSimulationCellRep
+	var client *registryfakes.FakeRegistrationClient
+	var announcer *registry.Announcer
+	var process ifrit.Process
+	var state auctiontypes.CellState
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Now())
+		client = &registryfakes.FakeRegistrationClient{}
+
+		state = auctiontypes.CellState{Zone: "Z0", Generation: 1}
+		rep = &fakes.FakeSimulationCellRep{}
+		rep.StateReturns(state, nil)
+
+		announcer = registry.NewAnnouncer(client, rep, "cell", "http://cell", 10*time.Second, clock, lagertest.NewTestLogger("test"))
+		process = ifrit.Invoke(announcer)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("registers the rep before becoming ready", func() {
+		Expect(client.RegisterCallCount()).To(Equal(1))
+		Expect(client.RegisterArgsForCall(0)).To(Equal(registry.Registration{
+			CellGuid: "cell",
+			Address:  "http://cell",
+			State:    state,
+		}))
+	})
+
+	It("heartbeats every interval", func() {
+		clock.Increment(10 * time.Second)
+		Eventually(client.UpdateCallCount).Should(Equal(1))
+		Expect(client.UpdateArgsForCall(0)).To(Equal(registry.Heartbeat("cell", state)))
+	})
+
+	It("pushes a delta as soon as the state changes", func() {
+		newState := state
+		newState.LRPs = []auctiontypes.LRP{{ProcessGuid: "pg"}}
+		newState.Generation = 2
+		rep.StateReturns(newState, nil)
+
+		announcer.StateChanged()
+		Eventually(client.UpdateCallCount).Should(Equal(1))
+
+		delta := client.UpdateArgsForCall(0)
+		Expect(delta.BaseGeneration).To(Equal(uint64(1)))
+		Expect(delta.Generation).To(Equal(uint64(2)))
+		Expect(delta.AddedLRPs).To(Equal(newState.LRPs))
+	})
+
+	It("registers again when the registry has forgotten the rep", func() {
+		client.UpdateReturns(registry.ErrUnknownCell)
+
+		clock.Increment(10 * time.Second)
+		Eventually(client.RegisterCallCount).Should(Equal(2))
+	})
+
+	It("deregisters when signaled", func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(client.DeregisterCallCount()).To(Equal(1))
+		Expect(client.DeregisterArgsForCall(0)).To(Equal("cell"))
+	})
+})
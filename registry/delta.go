@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+// Registration announces a cell.  Address is where the auctioneer reaches the
+// cell to Perform work; State is the cell's full state.
+type Registration struct {
+	CellGuid string
+	Address  string
+	State    auctiontypes.CellState
+}
+
+/*
+StateDelta moves a registered cell's state from BaseGeneration to Generation.
+
+A delta with no changes and BaseGeneration equal to Generation is a heartbeat.
+Zone and RootFSProviders cannot change through a delta; cells whose zone or
+rootfs providers change register again.
+*/
+type StateDelta struct {
+	CellGuid       string
+	BaseGeneration uint64
+	Generation     uint64
+
+	AvailableResources auctiontypes.Resources
+	TotalResources     auctiontypes.Resources
+	Evacuating         bool
+
+	AddedLRPs    []auctiontypes.LRP  `json:",omitempty"`
+	RemovedLRPs  []string            `json:",omitempty"`
+	AddedTasks   []auctiontypes.Task `json:",omitempty"`
+	RemovedTasks []string            `json:",omitempty"`
+}
+
+func Heartbeat(cellGuid string, state auctiontypes.CellState) StateDelta {
+	return StateDelta{
+		CellGuid:           cellGuid,
+		BaseGeneration:     state.Generation,
+		Generation:         state.Generation,
+		AvailableResources: state.AvailableResources,
+		TotalResources:     state.TotalResources,
+		Evacuating:         state.Evacuating,
+	}
+}
+
+// Diff returns the delta from old to new.  It returns false if the change
+// cannot be expressed as a delta, and the cell must register again.
+func Diff(cellGuid string, old, new auctiontypes.CellState) (StateDelta, bool) {
+	if old.Zone != new.Zone || !reflect.DeepEqual(old.RootFSProviders, new.RootFSProviders) {
+		return StateDelta{}, false
+	}
+
+	delta := Heartbeat(cellGuid, new)
+	delta.BaseGeneration = old.Generation
+
+	oldLRPs := map[string]bool{}
+	for _, lrp := range old.LRPs {
+		oldLRPs[lrp.Identifier()] = true
+	}
+	newLRPs := map[string]bool{}
+	for _, lrp := range new.LRPs {
+		newLRPs[lrp.Identifier()] = true
+		if !oldLRPs[lrp.Identifier()] {
+			delta.AddedLRPs = append(delta.AddedLRPs, lrp)
+		}
+	}
+	for _, lrp := range old.LRPs {
+		if !newLRPs[lrp.Identifier()] {
+			delta.RemovedLRPs = append(delta.RemovedLRPs, lrp.Identifier())
+		}
+	}
+
+	oldTasks := map[string]bool{}
+	for _, task := range old.Tasks {
+		oldTasks[task.TaskGuid] = true
+	}
+	newTasks := map[string]bool{}
+	for _, task := range new.Tasks {
+		newTasks[task.TaskGuid] = true
+		if !oldTasks[task.TaskGuid] {
+			delta.AddedTasks = append(delta.AddedTasks, task)
+		}
+	}
+	for _, task := range old.Tasks {
+		if !newTasks[task.TaskGuid] {
+			delta.RemovedTasks = append(delta.RemovedTasks, task.TaskGuid)
+		}
+	}
+
+	return delta, true
+}
+
+// Apply returns state moved forward by delta.  It does not check generations.
+func Apply(state auctiontypes.CellState, delta StateDelta) auctiontypes.CellState {
+	removedLRPs := map[string]bool{}
+	for _, identifier := range delta.RemovedLRPs {
+		removedLRPs[identifier] = true
+	}
+	lrps := []auctiontypes.LRP{}
+	for _, lrp := range state.LRPs {
+		if !removedLRPs[lrp.Identifier()] {
+			lrps = append(lrps, lrp)
+		}
+	}
+	lrps = append(lrps, delta.AddedLRPs...)
+
+	removedTasks := map[string]bool{}
+	for _, taskGuid := range delta.RemovedTasks {
+		removedTasks[taskGuid] = true
+	}
+	tasks := []auctiontypes.Task{}
+	for _, task := range state.Tasks {
+		if !removedTasks[task.TaskGuid] {
+			tasks = append(tasks, task)
+		}
+	}
+	tasks = append(tasks, delta.AddedTasks...)
+
+	state.LRPs = lrps
+	state.Tasks = tasks
+	state.AvailableResources = delta.AvailableResources
+	state.TotalResources = delta.TotalResources
+	state.Evacuating = delta.Evacuating
+	state.Generation = delta.Generation
+
+	return state
+}
@@ -0,0 +1,75 @@
+package registry_test
+
+import (
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/registry"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Delta", func() {
+	var old, new auctiontypes.CellState
+
+	BeforeEach(func() {
+		old = auctiontypes.CellState{
+			RootFSProviders: auctiontypes.RootFSProviders{
+				models.PreloadedRootFSScheme: auctiontypes.NewFixedSetRootFSProvider("lucid64"),
+			},
+			AvailableResources: auctiontypes.Resources{MemoryMB: 70, DiskMB: 70, Containers: 8},
+			TotalResources:     auctiontypes.Resources{MemoryMB: 100, DiskMB: 100, Containers: 10},
+			LRPs: []auctiontypes.LRP{
+				{ProcessGuid: "pg-1", Index: 0, MemoryMB: 10, DiskMB: 10},
+				{ProcessGuid: "pg-2", Index: 0, MemoryMB: 10, DiskMB: 10},
+			},
+			Tasks:      []auctiontypes.Task{{TaskGuid: "tg-1", MemoryMB: 10, DiskMB: 10}},
+			Zone:       "Z0",
+			Generation: 3,
+		}
+
+		new = old
+		new.LRPs = []auctiontypes.LRP{
+			{ProcessGuid: "pg-2", Index: 0, MemoryMB: 10, DiskMB: 10},
+			{ProcessGuid: "pg-3", Index: 1, MemoryMB: 20, DiskMB: 20},
+		}
+		new.Tasks = []auctiontypes.Task{}
+		new.AvailableResources = auctiontypes.Resources{MemoryMB: 70, DiskMB: 70, Containers: 8}
+		new.Generation = 5
+	})
+
+	It("diffs the LRPs and tasks that were added and removed", func() {
+		delta, ok := registry.Diff("cell", old, new)
+		Expect(ok).To(BeTrue())
+
+		Expect(delta.CellGuid).To(Equal("cell"))
+		Expect(delta.BaseGeneration).To(Equal(uint64(3)))
+		Expect(delta.Generation).To(Equal(uint64(5)))
+		Expect(delta.AddedLRPs).To(Equal([]auctiontypes.LRP{{ProcessGuid: "pg-3", Index: 1, MemoryMB: 20, DiskMB: 20}}))
+		Expect(delta.RemovedLRPs).To(Equal([]string{old.LRPs[0].Identifier()}))
+		Expect(delta.AddedTasks).To(BeEmpty())
+		Expect(delta.RemovedTasks).To(Equal([]string{"tg-1"}))
+	})
+
+	It("applies a diff to reproduce the new state", func() {
+		delta, _ := registry.Diff("cell", old, new)
+		applied := registry.Apply(old, delta)
+
+		Expect(applied.Generation).To(Equal(new.Generation))
+		Expect(applied.AvailableResources).To(Equal(new.AvailableResources))
+		Expect(applied.LRPs).To(ConsistOf(new.LRPs))
+		Expect(applied.Tasks).To(BeEmpty())
+		Expect(applied.Zone).To(Equal("Z0"))
+	})
+
+	It("makes heartbeats that change nothing", func() {
+		heartbeat := registry.Heartbeat("cell", old)
+		Expect(registry.Apply(old, heartbeat)).To(Equal(old))
+	})
+
+	It("refuses to diff across a zone change", func() {
+		new.Zone = "Z1"
+		_, ok := registry.Diff("cell", old, new)
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,133 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/auction/registry"
+)
+
+type FakeRegistrationClient struct {
+	RegisterStub        func(registry.Registration) error
+	registerMutex       sync.RWMutex
+	registerArgsForCall []struct {
+		arg1 registry.Registration
+	}
+	registerReturns struct {
+		result1 error
+	}
+	UpdateStub        func(registry.StateDelta) error
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		arg1 registry.StateDelta
+	}
+	updateReturns struct {
+		result1 error
+	}
+	DeregisterStub        func(cellGuid string) error
+	deregisterMutex       sync.RWMutex
+	deregisterArgsForCall []struct {
+		cellGuid string
+	}
+	deregisterReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeRegistrationClient) Register(arg1 registry.Registration) error {
+	fake.registerMutex.Lock()
+	fake.registerArgsForCall = append(fake.registerArgsForCall, struct {
+		arg1 registry.Registration
+	}{arg1})
+	fake.registerMutex.Unlock()
+	if fake.RegisterStub != nil {
+		return fake.RegisterStub(arg1)
+	} else {
+		return fake.registerReturns.result1
+	}
+}
+
+func (fake *FakeRegistrationClient) RegisterCallCount() int {
+	fake.registerMutex.RLock()
+	defer fake.registerMutex.RUnlock()
+	return len(fake.registerArgsForCall)
+}
+
+func (fake *FakeRegistrationClient) RegisterArgsForCall(i int) registry.Registration {
+	fake.registerMutex.RLock()
+	defer fake.registerMutex.RUnlock()
+	return fake.registerArgsForCall[i].arg1
+}
+
+func (fake *FakeRegistrationClient) RegisterReturns(result1 error) {
+	fake.RegisterStub = nil
+	fake.registerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRegistrationClient) Update(arg1 registry.StateDelta) error {
+	fake.updateMutex.Lock()
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		arg1 registry.StateDelta
+	}{arg1})
+	fake.updateMutex.Unlock()
+	if fake.UpdateStub != nil {
+		return fake.UpdateStub(arg1)
+	} else {
+		return fake.updateReturns.result1
+	}
+}
+
+func (fake *FakeRegistrationClient) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *FakeRegistrationClient) UpdateArgsForCall(i int) registry.StateDelta {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return fake.updateArgsForCall[i].arg1
+}
+
+func (fake *FakeRegistrationClient) UpdateReturns(result1 error) {
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRegistrationClient) Deregister(cellGuid string) error {
+	fake.deregisterMutex.Lock()
+	fake.deregisterArgsForCall = append(fake.deregisterArgsForCall, struct {
+		cellGuid string
+	}{cellGuid})
+	fake.deregisterMutex.Unlock()
+	if fake.DeregisterStub != nil {
+		return fake.DeregisterStub(cellGuid)
+	} else {
+		return fake.deregisterReturns.result1
+	}
+}
+
+func (fake *FakeRegistrationClient) DeregisterCallCount() int {
+	fake.deregisterMutex.RLock()
+	defer fake.deregisterMutex.RUnlock()
+	return len(fake.deregisterArgsForCall)
+}
+
+func (fake *FakeRegistrationClient) DeregisterArgsForCall(i int) string {
+	fake.deregisterMutex.RLock()
+	defer fake.deregisterMutex.RUnlock()
+	return fake.deregisterArgsForCall[i].cellGuid
+}
+
+func (fake *FakeRegistrationClient) DeregisterReturns(result1 error) {
+	fake.DeregisterStub = nil
+	fake.deregisterReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ registry.RegistrationClient = new(FakeRegistrationClient)
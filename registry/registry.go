@@ -0,0 +1,210 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+)
+
+var ErrUnknownCell = errors.New("unknown cell")
+var ErrGenerationMismatch = errors.New("generation mismatch")
+
+//go:generate counterfeiter -o fakes/fake_registration_client.go . RegistrationClient
+type RegistrationClient interface {
+	Register(Registration) error
+	Update(StateDelta) error
+	Deregister(cellGuid string) error
+}
+
+// CellRepFactory builds the CellRep used to Perform work on a newly
+// registered cell, typically an auction_http_client for its Address.  The
+// CellRep must only talk to the registered cell, as one whose http.Client is
+// from mutual_tls.NewCellClient does, so that the registered Address can't
+// send work to anyone else.
+type CellRepFactory func(Registration) (auctiontypes.CellRep, error)
+
+type ResultsDelegate interface {
+	AuctionCompleted(auctiontypes.AuctionResults)
+}
+
+type registeredCell struct {
+	client   auctiontypes.CellRep
+	state    auctiontypes.CellState
+	lastSeen time.Time
+
+	// set when work has been sent to the cell since its state was last pushed
+	dirty bool
+}
+
+/*
+Registry keeps the state that cells push to it, and implements
+AuctionRunnerDelegate so the auction runner can schedule from that state rather
+than fetching it from every cell.
+
+Cells that have not registered or heartbeated within heartbeatTTL are dropped
+from auctions.  Once work has been sent to a cell, its pushed state is out of
+date until the cell pushes again; in the meantime the cell's state is fetched
+directly, as it would be without the registry.
+*/
+type Registry struct {
+	cellRepFactory  CellRepFactory
+	resultsDelegate ResultsDelegate
+	heartbeatTTL    time.Duration
+	clock           clock.Clock
+	logger          lager.Logger
+
+	cells map[string]*registeredCell
+	lock  *sync.Mutex
+}
+
+func New(
+	cellRepFactory CellRepFactory,
+	resultsDelegate ResultsDelegate,
+	heartbeatTTL time.Duration,
+	clock clock.Clock,
+	logger lager.Logger,
+) *Registry {
+	return &Registry{
+		cellRepFactory:  cellRepFactory,
+		resultsDelegate: resultsDelegate,
+		heartbeatTTL:    heartbeatTTL,
+		clock:           clock,
+		logger:          logger.Session("registry"),
+		cells:           map[string]*registeredCell{},
+		lock:            &sync.Mutex{},
+	}
+}
+
+func (r *Registry) Register(registration Registration) error {
+	client, err := r.cellRepFactory(registration)
+	if err != nil {
+		r.logger.Error("failed-to-build-cell-rep", err, lager.Data{"cell-guid": registration.CellGuid})
+		return err
+	}
+
+	r.lock.Lock()
+	r.cells[registration.CellGuid] = &registeredCell{
+		client:   client,
+		state:    registration.State,
+		lastSeen: r.clock.Now(),
+	}
+	r.lock.Unlock()
+
+	r.logger.Info("registered-cell", lager.Data{
+		"cell-guid":  registration.CellGuid,
+		"address":    registration.Address,
+		"generation": registration.State.Generation,
+	})
+	return nil
+}
+
+func (r *Registry) Update(delta StateDelta) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cell, ok := r.cells[delta.CellGuid]
+	if !ok {
+		return ErrUnknownCell
+	}
+
+	if delta.BaseGeneration != cell.state.Generation {
+		return ErrGenerationMismatch
+	}
+
+	cell.state = Apply(cell.state, delta)
+	cell.lastSeen = r.clock.Now()
+	cell.dirty = false
+	return nil
+}
+
+func (r *Registry) Deregister(cellGuid string) error {
+	r.lock.Lock()
+	delete(r.cells, cellGuid)
+	r.lock.Unlock()
+
+	r.logger.Info("deregistered-cell", lager.Data{"cell-guid": cellGuid})
+	return nil
+}
+
+func (r *Registry) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.clock.Now()
+	cellReps := map[string]auctiontypes.CellRep{}
+	for guid, cell := range r.cells {
+		if now.Sub(cell.lastSeen) > r.heartbeatTTL {
+			r.logger.Info("expired-cell", lager.Data{"cell-guid": guid, "last-seen": cell.lastSeen})
+			delete(r.cells, guid)
+			continue
+		}
+
+		cellReps[guid] = &pushedCellRep{
+			registry: r,
+			guid:     guid,
+			client:   cell.client,
+		}
+	}
+
+	return cellReps, nil
+}
+
+func (r *Registry) AuctionCompleted(results auctiontypes.AuctionResults) {
+	if r.resultsDelegate != nil {
+		r.resultsDelegate.AuctionCompleted(results)
+	}
+}
+
+func (r *Registry) pushedState(guid string) (auctiontypes.CellState, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cell, ok := r.cells[guid]
+	if !ok || cell.dirty {
+		return auctiontypes.CellState{}, false
+	}
+	return cell.state, true
+}
+
+func (r *Registry) markDirty(guid string) {
+	r.lock.Lock()
+	if cell, ok := r.cells[guid]; ok {
+		cell.dirty = true
+	}
+	r.lock.Unlock()
+}
+
+// pushedCellRep answers State from the registry while the pushed state is
+// current, and passes everything else through to the cell.
+type pushedCellRep struct {
+	registry *Registry
+	guid     string
+	client   auctiontypes.CellRep
+}
+
+func (c *pushedCellRep) State() (auctiontypes.CellState, error) {
+	return c.StateContext(context.Background())
+}
+
+func (c *pushedCellRep) StateContext(ctx context.Context) (auctiontypes.CellState, error) {
+	state, ok := c.registry.pushedState(c.guid)
+	if ok {
+		return state, nil
+	}
+	return auctiontypes.StateWithContext(ctx, c.client)
+}
+
+func (c *pushedCellRep) Perform(work auctiontypes.Work) (auctiontypes.Work, error) {
+	return c.PerformContext(context.Background(), work)
+}
+
+func (c *pushedCellRep) PerformContext(ctx context.Context, work auctiontypes.Work) (auctiontypes.Work, error) {
+	c.registry.markDirty(c.guid)
+	return auctiontypes.PerformWithContext(ctx, c.client, work)
+}
@@ -0,0 +1,131 @@
+package registry_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes/fakes"
+	"github.com/cloudfoundry-incubator/auction/registry"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var clock *fakeclock.FakeClock
+	var cellRep *fakes.FakeSimulationCellRep
+	var reg *registry.Registry
+	var state auctiontypes.CellState
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Now())
+		cellRep = &fakes.FakeSimulationCellRep{}
+		cellRep.StateReturns(auctiontypes.CellState{Zone: "Z0", Generation: 9}, nil)
+
+		factory := func(registry.Registration) (auctiontypes.CellRep, error) {
+			return cellRep, nil
+		}
+		reg = registry.New(factory, nil, 30*time.Second, clock, lagertest.NewTestLogger("test"))
+
+		state = auctiontypes.CellState{
+			AvailableResources: auctiontypes.Resources{MemoryMB: 100, DiskMB: 100, Containers: 10},
+			TotalResources:     auctiontypes.Resources{MemoryMB: 100, DiskMB: 100, Containers: 10},
+			Zone:               "Z0",
+			Generation:         1,
+		}
+		err := reg.Register(registry.Registration{CellGuid: "cell", Address: "http://cell", State: state})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	fetchState := func() auctiontypes.CellState {
+		cellReps, err := reg.FetchCellReps()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cellReps).To(HaveKey("cell"))
+
+		state, err := cellReps["cell"].State()
+		Expect(err).NotTo(HaveOccurred())
+		return state
+	}
+
+	It("serves the pushed state without asking the cell", func() {
+		Expect(fetchState()).To(Equal(state))
+		Expect(cellRep.StateCallCount()).To(Equal(0))
+	})
+
+	It("applies deltas to the pushed state", func() {
+		newState := state
+		newState.LRPs = []auctiontypes.LRP{{ProcessGuid: "pg", MemoryMB: 10, DiskMB: 10}}
+		newState.AvailableResources = auctiontypes.Resources{MemoryMB: 90, DiskMB: 90, Containers: 9}
+		newState.Generation = 2
+
+		delta, _ := registry.Diff("cell", state, newState)
+		Expect(reg.Update(delta)).To(Succeed())
+
+		updated := fetchState()
+		Expect(updated.Generation).To(Equal(uint64(2)))
+		Expect(updated.LRPs).To(Equal(newState.LRPs))
+		Expect(updated.AvailableResources).To(Equal(newState.AvailableResources))
+	})
+
+	It("rejects deltas from a different base generation", func() {
+		delta := registry.Heartbeat("cell", state)
+		delta.BaseGeneration = 7
+		Expect(reg.Update(delta)).To(Equal(registry.ErrGenerationMismatch))
+	})
+
+	It("rejects deltas for unknown cells", func() {
+		Expect(reg.Update(registry.Heartbeat("other-cell", state))).To(Equal(registry.ErrUnknownCell))
+	})
+
+	It("drops cells that stop heartbeating", func() {
+		clock.Increment(20 * time.Second)
+		Expect(reg.Update(registry.Heartbeat("cell", state))).To(Succeed())
+
+		clock.Increment(20 * time.Second)
+		Expect(reg.FetchCellReps()).To(HaveKey("cell"))
+
+		clock.Increment(20 * time.Second)
+		Expect(reg.FetchCellReps()).To(BeEmpty())
+	})
+
+	It("drops deregistered cells", func() {
+		Expect(reg.Deregister("cell")).To(Succeed())
+		Expect(reg.FetchCellReps()).To(BeEmpty())
+	})
+
+	Context("once work has been sent to the cell", func() {
+		BeforeEach(func() {
+			cellReps, err := reg.FetchCellReps()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = cellReps["cell"].Perform(auctiontypes.Work{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cellRep.PerformCallCount()).To(Equal(1))
+		})
+
+		It("asks the cell for its state until it pushes again", func() {
+			Expect(fetchState().Generation).To(Equal(uint64(9)))
+			Expect(cellRep.StateCallCount()).To(Equal(1))
+
+			Expect(reg.Update(registry.Heartbeat("cell", state))).To(Succeed())
+			Expect(fetchState().Generation).To(Equal(uint64(1)))
+			Expect(cellRep.StateCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the cell rep cannot be built", func() {
+		It("does not register the cell", func() {
+			factory := func(registry.Registration) (auctiontypes.CellRep, error) {
+				return nil, errors.New("bad address")
+			}
+			reg = registry.New(factory, nil, 30*time.Second, clock, lagertest.NewTestLogger("test"))
+
+			Expect(reg.Register(registry.Registration{CellGuid: "cell", State: state})).NotTo(Succeed())
+			Expect(reg.FetchCellReps()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
+)
+
+var rounds = flag.String("rounds", "", "a round recorded by auctionrunner.FileRoundRecorder, or a directory of them")
+var verbose = flag.Bool("verbose", false, "print every placement that changed, not just the counts")
+
+func main() {
+	flag.Parse()
+
+	if *rounds == "" {
+		fmt.Fprintln(os.Stderr, "need -rounds")
+		os.Exit(2)
+	}
+
+	paths, err := roundPaths(*rounds)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	workPool := workpool.NewWorkPool(50)
+	defer workPool.Stop()
+
+	totalAuctions, totalChanged := 0, 0
+	for _, path := range paths {
+		round, err := auctionrunner.ReadRound(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", path, err)
+			os.Exit(2)
+		}
+
+		replayed := auctionrunner.Replay(round, workPool, clock.NewClock())
+		diffs := auctionrunner.DiffPlacements(round.Placements, replayed)
+
+		auctions := len(round.Request.LRPs) + len(round.Request.Tasks)
+		totalAuctions += auctions
+		totalChanged += len(diffs)
+
+		fmt.Printf("%s: %d cells, %d auctions, %d placements changed\n", path, len(round.Cells), auctions, len(diffs))
+		if *verbose {
+			for _, diff := range diffs {
+				fmt.Printf("  %s %s: %s -> %s\n", diff.Kind, diff.Identifier, placement(diff.Recorded), placement(diff.Replayed))
+			}
+		}
+	}
+
+	fmt.Printf("%d rounds, %d auctions, %d placements changed\n", len(paths), totalAuctions, totalChanged)
+	if totalChanged > 0 {
+		os.Exit(1)
+	}
+}
+
+func roundPaths(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	return auctionrunner.RoundFiles(path)
+}
+
+func placement(cellGuid string) string {
+	if cellGuid == "" {
+		return "(not placed)"
+	}
+	return cellGuid
+}
@@ -44,6 +44,8 @@ func (a *auctionRunnerDelegate) AuctionCompleted(work auctiontypes.AuctionResult
 	a.workResults.FailedTasks = append(a.workResults.FailedTasks, work.FailedTasks...)
 	a.workResults.SuccessfulLRPs = append(a.workResults.SuccessfulLRPs, work.SuccessfulLRPs...)
 	a.workResults.SuccessfulTasks = append(a.workResults.SuccessfulTasks, work.SuccessfulTasks...)
+	a.workResults.AlreadyRunningLRPs = append(a.workResults.AlreadyRunningLRPs, work.AlreadyRunningLRPs...)
+	a.workResults.AlreadyRunningTasks = append(a.workResults.AlreadyRunningTasks, work.AlreadyRunningTasks...)
 }
 
 func (a *auctionRunnerDelegate) ResultSize() int {
@@ -53,7 +55,9 @@ func (a *auctionRunnerDelegate) ResultSize() int {
 	return len(a.workResults.FailedLRPs) +
 		len(a.workResults.FailedTasks) +
 		len(a.workResults.SuccessfulLRPs) +
-		len(a.workResults.SuccessfulTasks)
+		len(a.workResults.SuccessfulTasks) +
+		len(a.workResults.AlreadyRunningLRPs) +
+		len(a.workResults.AlreadyRunningTasks)
 }
 
 func (a *auctionRunnerDelegate) Results() auctiontypes.AuctionResults {
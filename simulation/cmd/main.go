@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_client"
+	"github.com/cloudfoundry-incubator/auction/simulation/scenario"
+	"github.com/cloudfoundry-incubator/auction/simulation/timeline"
+	"github.com/cloudfoundry-incubator/auction/simulation/util"
+	"github.com/cloudfoundry-incubator/auction/simulation/visualization"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/pivotal-golang/lager"
+)
+
+const InProcess = "inprocess"
+const HTTP = "http"
+
+var scenarioPath = flag.String("scenario", "", "scenario file to run, JSON or, ending in .yml or .yaml, YAML")
+var timelinePath = flag.String("timeline", "", "timeline file to play on a fake clock instead of a scenario, JSON or YAML; in-process only")
+var communicationMode = flag.String("communicationMode", InProcess, "one of inprocess or http")
+var timeout = flag.Duration("timeout", time.Second, "timeout when waiting for responses from remote calls")
+var resultsTimeout = flag.Duration("resultsTimeout", time.Minute, "timeout when waiting for an experiment's auctions to finish")
+var workers = flag.Int("workers", 500, "number of concurrent communication worker pools")
+var repNodeBinary = flag.String("repNodeBinary", "", "simulation/repnode binary to launch in http communicationMode; built from source if empty")
+var httpPortBase = flag.Int("httpPortBase", 30000, "port of the first rep in http communicationMode; each rep listens on the next")
+var reportName = flag.String("reportName", "", "path of the reports, without extension; defaults to the scenario's name")
+var disableSVGReport = flag.Bool("disableSVGReport", false, "don't write the SVG report")
+var verbose = flag.Bool("verbose", false, "log the auction runner and reps to stderr")
+var seed = flag.Int64("seed", 0, "seed to draw the scenario's randomness from; overrides the scenario's seed, and zero picks one from the time unless the scenario has one.  Seeded runs schedule deterministically, so they can be reproduced; unseeded ones schedule concurrently, as in production")
+
+func main() {
+	flag.Parse()
+
+	if (*scenarioPath == "") == (*timelinePath == "") {
+		fmt.Fprintln(os.Stderr, "need one of -scenario or -timeline")
+		os.Exit(2)
+	}
+
+	logger := lager.NewLogger("simulation")
+	if *verbose {
+		logger.RegisterSink(lager.NewWriterSink(os.Stderr, lager.DEBUG))
+	} else {
+		logger.RegisterSink(lager.NewWriterSink(os.Stderr, lager.ERROR))
+	}
+
+	if *timelinePath != "" {
+		err := playTimeline(*timelinePath, logger)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	// the visualization reports its own failures through gomega
+	gomega.RegisterFailHandler(func(message string, _ ...int) {
+		log.Fatalln(message)
+	})
+
+	spec, err := scenario.Load(*scenarioPath)
+	if err != nil {
+		log.Fatalln("failed to load scenario:", err)
+	}
+
+	if *reportName == "" {
+		*reportName = spec.Name
+	}
+
+	switch {
+	case *seed != 0:
+		util.Seed(*seed)
+	case spec.Seed != 0:
+		util.Seed(spec.Seed)
+	}
+
+	err = run(spec, logger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func run(spec scenario.Scenario, logger lager.Logger) error {
+	fmt.Printf("Running %s in %s communicationMode with seed %d, %s scheduling\n", spec.Name, *communicationMode, util.CurrentSeed(), visualization.SchedulingMode(util.Seeded()))
+
+	var cells map[string]auctiontypes.SimulationCellRep
+	switch *communicationMode {
+	case InProcess:
+		cells = scenario.BuildInProcessCells(spec.Cells)
+	case HTTP:
+		var sessions []*gexec.Session
+		var err error
+		cells, sessions, err = launchExternalHTTPReps(spec.Cells, logger)
+		defer func() {
+			for _, sess := range sessions {
+				sess.Kill().Wait()
+			}
+			gexec.CleanupBuildArtifacts()
+		}()
+		if err != nil {
+			return fmt.Errorf("failed to launch reps: %s", err)
+		}
+	default:
+		return fmt.Errorf("unknown communication mode: %s", *communicationMode)
+	}
+
+	var svgReport *visualization.SVGReport
+	if !*disableSVGReport {
+		columns, rows := spec.Grid()
+		svgReport = visualization.StartSVGReport(*reportName+".svg", columns, rows, spec.Cells.Size())
+		svgReport.DrawHeader(fmt.Sprintf("%s (%s)", spec.Name, *communicationMode), util.CurrentSeed(), util.Seeded())
+	}
+
+	simulation := scenario.New(spec, cells, *workers, *resultsTimeout, logger)
+	reports := []*visualization.Report{}
+	for i, experiment := range spec.Experiments {
+		fmt.Printf("\n%s\n", experimentName(i, experiment))
+
+		report, err := simulation.RunExperiment(experiment)
+		if err != nil {
+			return fmt.Errorf("%s failed: %s", experimentName(i, experiment), err)
+		}
+
+		visualization.PrintReport(report)
+		if svgReport != nil {
+			column, row := spec.Position(i)
+			svgReport.DrawReportCard(column, row, report)
+		}
+		reports = append(reports, report)
+	}
+
+	if svgReport != nil {
+		svgReport.Done()
+		_, err := exec.LookPath("rsvg-convert")
+		if err == nil {
+			exec.Command("rsvg-convert", "-h", "2000", "--background-color=#fff", *reportName+".svg", "-o", *reportName+".png").Run()
+		}
+	}
+
+	data, err := json.Marshal(reports)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reports: %s", err)
+	}
+	return ioutil.WriteFile(*reportName+".json", data, 0644)
+}
+
+func playTimeline(path string, logger lager.Logger) error {
+	if *communicationMode != InProcess {
+		return fmt.Errorf("timelines only run in %s communicationMode", InProcess)
+	}
+
+	spec, err := timeline.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load timeline: %s", err)
+	}
+
+	if *reportName == "" {
+		*reportName = spec.Name
+	}
+
+	switch {
+	case *seed != 0:
+		util.Seed(*seed)
+	case spec.Seed != 0:
+		util.Seed(spec.Seed)
+	}
+
+	fmt.Printf("Playing %s with seed %d, %s scheduling\n", spec.Name, util.CurrentSeed(), visualization.SchedulingMode(util.Seeded()))
+
+	report, err := timeline.New(spec, *workers, *resultsTimeout, logger).Run()
+	if err != nil {
+		return err
+	}
+	timeline.PrintReport(report)
+
+	if !*disableSVGReport {
+		err = timeline.WriteSVG(*reportName+".svg", report)
+		if err != nil {
+			return fmt.Errorf("failed to write the svg report: %s", err)
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %s", err)
+	}
+	return ioutil.WriteFile(*reportName+".json", data, 0644)
+}
+
+func experimentName(i int, experiment scenario.Experiment) string {
+	if experiment.Name == "" {
+		return fmt.Sprintf("Experiment %d", i+1)
+	}
+	return experiment.Name
+}
+
+func launchExternalHTTPReps(fleet scenario.Fleet, logger lager.Logger) (map[string]auctiontypes.SimulationCellRep, []*gexec.Session, error) {
+	binary := *repNodeBinary
+	if binary == "" {
+		var err error
+		binary, err = gexec.Build("github.com/cloudfoundry-incubator/auction/simulation/repnode")
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cells := map[string]auctiontypes.SimulationCellRep{}
+	sessions := []*gexec.Session{}
+
+	client := &http.Client{
+		Timeout: *timeout,
+	}
+	for i, cell := range fleet.Cells() {
+		repGuid := cell.Guid
+		httpAddr := fmt.Sprintf("127.0.0.1:%d", *httpPortBase+i)
+
+		command := exec.Command(binary, append(scenario.RepNodeArgs(cell), "-httpAddr", httpAddr)...)
+
+		var sessionOut io.Writer
+		if *verbose {
+			sessionOut = os.Stderr
+		}
+		sess, err := gexec.Start(command, sessionOut, sessionOut)
+		if err != nil {
+			return nil, sessions, err
+		}
+		sessions = append(sessions, sess)
+
+		err = waitUntilListening(sess, *timeout*10)
+		if err != nil {
+			return nil, sessions, fmt.Errorf("%s: %s", repGuid, err)
+		}
+
+		cells[repGuid] = auction_http_client.New(client, repGuid, "http://"+httpAddr, logger)
+	}
+
+	return cells, sessions, nil
+}
+
+func waitUntilListening(sess *gexec.Session, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for !strings.Contains(string(sess.Out.Contents()), "listening") {
+		if sess.ExitCode() != -1 {
+			return fmt.Errorf("exited with status %d", sess.ExitCode())
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("not listening after %s", timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/cloudfoundry-incubator/auction/simulation/simulationrep"
 
@@ -12,11 +16,17 @@ import (
 	"github.com/tedsuo/rata"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_server"
 	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_handlers"
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls"
+	"github.com/cloudfoundry-incubator/auction/metrics"
 	cf_lager "github.com/cloudfoundry-incubator/cf-lager"
 	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/grouper"
 	"github.com/tedsuo/ifrit/http_server"
 	"github.com/tedsuo/ifrit/sigmon"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var memoryMB = flag.Int("memoryMB", 100, "total available memory in MB")
@@ -24,7 +34,14 @@ var diskMB = flag.Int("diskMB", 100, "total available disk in MB")
 var containers = flag.Int("containers", 100, "total available containers")
 var repGuid = flag.String("repGuid", "", "rep-guid")
 var httpAddr = flag.String("httpAddr", "", "http server addres")
+var grpcAddr = flag.String("grpcAddr", "", "grpc server address")
 var zone = flag.String("zone", "Z0", "availability zone")
+var stacks = flag.String("stack", "lucid64", "stacks of the rep's preloaded rootfses, separated by commas")
+var arbitraryRootFS = flag.String("arbitraryRootFS", "", "rootfs schemes, such as docker, the rep runs any rootfs of, separated by commas")
+var caFile = flag.String("caFile", "", "CA certificate that auctioneer certificates must be signed by; enables mutual TLS")
+var certFile = flag.String("certFile", "", "rep certificate, naming the rep-guid")
+var auctioneerNames = flag.String("auctioneerName", "auctioneer", "names in the certificates of the auctioneers the rep accepts, separated by commas")
+var keyFile = flag.String("keyFile", "", "rep private key")
 
 func main() {
 	flag.Parse()
@@ -33,28 +50,76 @@ func main() {
 		panic("need rep-guid")
 	}
 
-	if *httpAddr == "" {
-		panic("need http addr")
+	if *httpAddr == "" && *grpcAddr == "" {
+		panic("need http or grpc addr")
 	}
 
-	simulationRep := simulationrep.New("lucid64", *zone, auctiontypes.Resources{
+	rootFSProviders := simulationrep.RootFSProviders(splitList(*stacks), splitList(*arbitraryRootFS))
+	simulationRep := simulationrep.NewWithRootFSProviders(rootFSProviders, *zone, auctiontypes.Resources{
 		MemoryMB:   *memoryMB,
 		DiskMB:     *diskMB,
 		Containers: *containers,
 	})
 
-	logger, _ := cf_lager.New("repnode-http")
-	handlers := auction_http_handlers.New(simulationRep, logger.Session(*repGuid))
-	router, err := rata.NewRouter(routes.Routes, handlers)
-	if err != nil {
-		log.Fatalln("failed to make router:", err)
+	logger, _ := cf_lager.New("repnode")
+	members := grouper.Members{}
+
+	var tlsConfig *tls.Config
+	if *caFile != "" {
+		var err error
+		tlsConfig, err = mutual_tls.Config{
+			CACertFile: *caFile,
+			CertFile:   *certFile,
+			KeyFile:    *keyFile,
+		}.ServerTLSConfig(mutual_tls.AllowNames(splitList(*auctioneerNames)...))
+		if err != nil {
+			log.Fatalln("failed to load tls config:", err)
+		}
+	}
+
+	if *httpAddr != "" {
+		handlers := auction_http_handlers.New(simulationRep, logger.Session(*repGuid))
+		router, err := rata.NewRouter(routes.Routes, handlers)
+		if err != nil {
+			log.Fatalln("failed to make router:", err)
+		}
+
+		metricsEmitter := metrics.NewPrometheusEmitter(logger)
+		metricsEmitter.ScrapeCell(*repGuid, simulationRep)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricsEmitter)
+		mux.Handle("/", router)
+
+		var httpServer ifrit.Runner
+		if tlsConfig != nil {
+			httpServer = http_server.NewTLSServer(*httpAddr, mux, tlsConfig)
+		} else {
+			httpServer = http_server.New(*httpAddr, mux)
+		}
+		members = append(members, grouper.Member{Name: "http", Runner: httpServer})
+	}
+
+	if *grpcAddr != "" {
+		serverOptions := []grpc.ServerOption{}
+		if tlsConfig != nil {
+			serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		grpcServer := auction_grpc_server.NewRunner(*grpcAddr, simulationRep, logger.Session(*repGuid), serverOptions...)
+		members = append(members, grouper.Member{Name: "grpc", Runner: grpcServer})
 	}
-	httpServer := http_server.New(*httpAddr, router)
 
-	monitor := ifrit.Invoke(sigmon.New(httpServer))
+	monitor := ifrit.Invoke(sigmon.New(grouper.NewParallel(os.Interrupt, members)))
 	fmt.Println("rep node listening")
-	err = <-monitor.Wait()
+	err := <-monitor.Wait()
 	if err != nil {
 		println("EXITED WITH ERROR: ", err.Error())
 	}
 }
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
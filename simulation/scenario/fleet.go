@@ -0,0 +1,241 @@
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/simulation/simulationrep"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	"gopkg.in/yaml.v2"
+)
+
+const DefaultCellCount = 100
+const DefaultStack = "lucid64"
+
+var DefaultZones = []string{"Z0", "Z1"}
+
+// ZoneLayoutAlternate puts a group's cells in its zones in turn;
+// ZoneLayoutBlocks splits the group into one run of cells per zone.
+const (
+	ZoneLayoutAlternate = "alternate"
+	ZoneLayoutBlocks    = "blocks"
+)
+
+// CellGroup is Count cells with the same resources that can run the same
+// rootfses: the preloaded Stacks and any rootfs with one of the
+// ArbitraryRootFS schemes, such as docker.  They are spread over Zones as
+// ZoneLayout says.
+type CellGroup struct {
+	Count           int      `json:"count" yaml:"count"`
+	MemoryMB        int      `json:"memory_mb" yaml:"memory_mb"`
+	DiskMB          int      `json:"disk_mb" yaml:"disk_mb"`
+	Containers      int      `json:"containers" yaml:"containers"`
+	Stacks          []string `json:"stacks" yaml:"stacks"`
+	ArbitraryRootFS []string `json:"arbitrary_rootfs" yaml:"arbitrary_rootfs"`
+	Zones           []string `json:"zones" yaml:"zones"`
+	ZoneLayout      string   `json:"zone_layout" yaml:"zone_layout"`
+}
+
+// Fleet is the cells of a scenario, numbered from REP-1 through its Groups in
+// order.  The fleet's own fields are the defaults for its groups, and without
+// Groups the fleet is a single group.  Zero values default to the Ginkgo
+// simulation's fleet: 100 cells with 100MB of memory and disk and 100
+// containers, running lucid64, alternating between Z0 and Z1.
+//
+// A group that names neither stacks nor arbitrary rootfses runs the fleet's;
+// otherwise it runs only those it names.
+type Fleet struct {
+	CellGroup `yaml:",inline"`
+	Groups    []CellGroup `json:"groups" yaml:"groups"`
+}
+
+// CellSpec is one cell of a fleet
+type CellSpec struct {
+	Guid            string
+	Zone            string
+	Resources       auctiontypes.Resources
+	Stacks          []string
+	ArbitraryRootFS []string
+}
+
+func (c CellSpec) RootFSProviders() auctiontypes.RootFSProviders {
+	return simulationrep.RootFSProviders(c.Stacks, c.ArbitraryRootFS)
+}
+
+func DefaultFleet() Fleet {
+	return Fleet{}.WithDefaults()
+}
+
+// LoadFleet reads a fleet on its own, as it would appear under a scenario's
+// cells, from a JSON or YAML file.
+func LoadFleet(path string) (Fleet, error) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Fleet{}, err
+	}
+
+	var fleet Fleet
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(payload, &fleet)
+	default:
+		err = json.Unmarshal(payload, &fleet)
+	}
+	if err != nil {
+		return Fleet{}, err
+	}
+
+	fleet = fleet.WithDefaults()
+	err = fleet.Validate()
+	if err != nil {
+		return Fleet{}, err
+	}
+
+	return fleet, nil
+}
+
+// WithDefaults fills in the fleet's zero fields, and then its groups' from the
+// fleet's
+func (f Fleet) WithDefaults() Fleet {
+	base := f.CellGroup
+	if base.Count == 0 && len(f.Groups) == 0 {
+		base.Count = DefaultCellCount
+	}
+	if base.MemoryMB == 0 {
+		base.MemoryMB = 100
+	}
+	if base.DiskMB == 0 {
+		base.DiskMB = 100
+	}
+	if base.Containers == 0 {
+		base.Containers = 100
+	}
+	if len(base.Stacks) == 0 && len(base.ArbitraryRootFS) == 0 {
+		base.Stacks = []string{DefaultStack}
+	}
+	if len(base.Zones) == 0 {
+		base.Zones = DefaultZones
+	}
+	if base.ZoneLayout == "" {
+		base.ZoneLayout = ZoneLayoutAlternate
+	}
+
+	groups := []CellGroup{}
+	for _, group := range f.Groups {
+		if group.MemoryMB == 0 {
+			group.MemoryMB = base.MemoryMB
+		}
+		if group.DiskMB == 0 {
+			group.DiskMB = base.DiskMB
+		}
+		if group.Containers == 0 {
+			group.Containers = base.Containers
+		}
+		if len(group.Stacks) == 0 && len(group.ArbitraryRootFS) == 0 {
+			group.Stacks = base.Stacks
+			group.ArbitraryRootFS = base.ArbitraryRootFS
+		}
+		if len(group.Zones) == 0 {
+			group.Zones = base.Zones
+		}
+		if group.ZoneLayout == "" {
+			group.ZoneLayout = base.ZoneLayout
+		}
+		groups = append(groups, group)
+	}
+
+	return Fleet{CellGroup: base, Groups: groups}
+}
+
+func (f Fleet) Validate() error {
+	if f.Size() <= 0 {
+		return errors.New("fleet needs at least one cell")
+	}
+
+	for i, group := range f.groups() {
+		if group.Count <= 0 || group.MemoryMB <= 0 || group.DiskMB <= 0 || group.Containers <= 0 {
+			return fmt.Errorf("cell group %d needs positive count, memory, disk and containers", i+1)
+		}
+		if len(group.Zones) == 0 {
+			return fmt.Errorf("cell group %d has no zones", i+1)
+		}
+		if group.ZoneLayout != ZoneLayoutAlternate && group.ZoneLayout != ZoneLayoutBlocks {
+			return fmt.Errorf("cell group %d has unknown zone layout %q", i+1, group.ZoneLayout)
+		}
+	}
+
+	return nil
+}
+
+// Size is the number of cells in the fleet
+func (f Fleet) Size() int {
+	size := 0
+	for _, group := range f.groups() {
+		size += group.Count
+	}
+	return size
+}
+
+// Cells lists the fleet's cells in order
+func (f Fleet) Cells() []CellSpec {
+	cells := []CellSpec{}
+	for _, group := range f.groups() {
+		for i := 0; i < group.Count; i++ {
+			cells = append(cells, CellSpec{
+				Guid: CellGuid(len(cells)),
+				Zone: group.zone(i),
+				Resources: auctiontypes.Resources{
+					MemoryMB:   group.MemoryMB,
+					DiskMB:     group.DiskMB,
+					Containers: group.Containers,
+				},
+				Stacks:          group.Stacks,
+				ArbitraryRootFS: group.ArbitraryRootFS,
+			})
+		}
+	}
+	return cells
+}
+
+// DefaultRootFS is the first stack any of the fleet's cells runs, which
+// workloads run unless they say otherwise, or empty if no cell runs a stack
+func (f Fleet) DefaultRootFS() string {
+	for _, group := range f.groups() {
+		if len(group.Stacks) > 0 {
+			return models.PreloadedRootFS(group.Stacks[0])
+		}
+	}
+	return ""
+}
+
+func (f Fleet) groups() []CellGroup {
+	if len(f.Groups) == 0 {
+		return []CellGroup{f.CellGroup}
+	}
+	return f.Groups
+}
+
+func (g CellGroup) zone(i int) string {
+	if g.ZoneLayout == ZoneLayoutBlocks {
+		return g.Zones[i*len(g.Zones)/g.Count]
+	}
+	return g.Zones[i%len(g.Zones)]
+}
+
+// RepNodeArgs are the simulation/repnode flags for a rep that simulates cell
+func RepNodeArgs(cell CellSpec) []string {
+	return []string{
+		"-repGuid", cell.Guid,
+		"-memoryMB", fmt.Sprintf("%d", cell.Resources.MemoryMB),
+		"-diskMB", fmt.Sprintf("%d", cell.Resources.DiskMB),
+		"-containers", fmt.Sprintf("%d", cell.Resources.Containers),
+		"-zone", cell.Zone,
+		"-stack", strings.Join(cell.Stacks, ","),
+		"-arbitraryRootFS", strings.Join(cell.ArbitraryRootFS, ","),
+	}
+}
@@ -0,0 +1,193 @@
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Scenario is a fleet of simulated cells and the experiments to run against
+// it, one after the other.  Every experiment starts from empty cells.
+//
+// Seed, if not zero, is the seed all of the scenario's randomness is drawn
+// from, so that it places the same work on the same cells every time it is
+// run in-process.
+type Scenario struct {
+	Name        string       `json:"name" yaml:"name"`
+	Seed        int64        `json:"seed" yaml:"seed"`
+	Cells       Fleet        `json:"cells" yaml:"cells"`
+	Experiments []Experiment `json:"experiments" yaml:"experiments"`
+}
+
+// Experiment places some LRP instances on the first Cells cells of the fleet
+// directly, then auctions Workloads among them.  Zero Cells uses the whole
+// fleet.  Shuffle auctions the workloads' instances in a random order rather
+// than workload by workload.
+//
+// Column and Row place the experiment's report card in the SVG report; by
+// default cards are laid out four to a row in the order of the experiments.
+type Experiment struct {
+	Name     string                `json:"name" yaml:"name"`
+	Cells    int                   `json:"cells" yaml:"cells"`
+	Initial  []InitialDistribution `json:"initial" yaml:"initial"`
+	Workload []Workload            `json:"workload" yaml:"workload"`
+	Shuffle  bool                  `json:"shuffle" yaml:"shuffle"`
+
+	Column *int `json:"column" yaml:"column"`
+	Row    *int `json:"row" yaml:"row"`
+}
+
+// InitialDistribution puts Instances instances of their own apps, each using
+// MemoryMB, on NumCells cells starting at the zero-based FirstCell.  Zero
+// NumCells means every remaining cell in the experiment.  If MaxInstances is
+// greater than Instances, each cell gets a random number of instances between
+// the two.
+type InitialDistribution struct {
+	FirstCell    int `json:"first_cell" yaml:"first_cell"`
+	NumCells     int `json:"num_cells" yaml:"num_cells"`
+	Instances    int `json:"instances" yaml:"instances"`
+	MaxInstances int `json:"max_instances" yaml:"max_instances"`
+	MemoryMB     int `json:"memory_mb" yaml:"memory_mb"`
+}
+
+// Workload is Instances LRP start auctions, each using MemoryMB.  With a
+// ProcessGuid they are instances of that one app; with Colors each is an
+// instance of a randomly picked one of those apps; otherwise each is its own
+// app.  RootFS, such as preloaded:cflinuxfs2 or docker:///busybox, defaults
+// to the fleet's first stack.
+type Workload struct {
+	Instances   int      `json:"instances" yaml:"instances"`
+	MemoryMB    int      `json:"memory_mb" yaml:"memory_mb"`
+	ProcessGuid string   `json:"process_guid" yaml:"process_guid"`
+	Colors      []string `json:"colors" yaml:"colors"`
+	RootFS      string   `json:"rootfs" yaml:"rootfs"`
+}
+
+// Load reads a scenario from a JSON file or, if it ends in .yml or .yaml, a
+// YAML file, fills in the fleet's defaults and validates it.
+func Load(path string) (Scenario, error) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	var scenario Scenario
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(payload, &scenario)
+	default:
+		err = json.Unmarshal(payload, &scenario)
+	}
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	if scenario.Name == "" {
+		base := filepath.Base(path)
+		scenario.Name = base[:len(base)-len(filepath.Ext(base))]
+	}
+	scenario.Cells = scenario.Cells.WithDefaults()
+
+	err = scenario.Validate()
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	return scenario, nil
+}
+
+func (s Scenario) Validate() error {
+	err := s.Cells.Validate()
+	if err != nil {
+		return err
+	}
+	if len(s.Experiments) == 0 {
+		return errors.New("scenario has no experiments")
+	}
+
+	for i, experiment := range s.Experiments {
+		err := experiment.validate(s.Cells)
+		if err != nil {
+			return fmt.Errorf("experiment %d (%s): %s", i+1, experiment.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (e Experiment) validate(fleet Fleet) error {
+	if e.Cells < 0 || e.Cells > fleet.Size() {
+		return fmt.Errorf("uses %d cells but the fleet has %d", e.Cells, fleet.Size())
+	}
+
+	numCells := e.NumCells(fleet)
+	for _, initial := range e.Initial {
+		if initial.FirstCell < 0 || initial.NumCells < 0 || initial.FirstCell+initial.NumCells > numCells {
+			return fmt.Errorf("initial distribution over cells %d to %d is outside the experiment's %d cells", initial.FirstCell, initial.FirstCell+initial.NumCells-1, numCells)
+		}
+		if initial.Instances < 0 || initial.MemoryMB < 0 {
+			return errors.New("initial distribution has negative instances or memory")
+		}
+	}
+
+	if len(e.Workload) == 0 {
+		return errors.New("has no workload")
+	}
+	for _, workload := range e.Workload {
+		if workload.Instances <= 0 || workload.MemoryMB <= 0 {
+			return errors.New("workload needs positive instances and memory")
+		}
+		if workload.ProcessGuid != "" && len(workload.Colors) > 0 {
+			return errors.New("workload has both a process guid and colors")
+		}
+		if workload.RootFS == "" && fleet.DefaultRootFS() == "" {
+			return errors.New("workload needs a rootfs, as the fleet has no stacks")
+		}
+	}
+
+	return nil
+}
+
+// NumCells is how many of the fleet's cells the experiment uses
+func (e Experiment) NumCells(fleet Fleet) int {
+	if e.Cells == 0 {
+		return fleet.Size()
+	}
+	return e.Cells
+}
+
+// Grid is the number of columns and rows of report cards needed to lay out
+// every experiment
+func (s Scenario) Grid() (int, int) {
+	columns, rows := 0, 0
+	for i, experiment := range s.Experiments {
+		column, row := experiment.position(i)
+		if column+1 > columns {
+			columns = column + 1
+		}
+		if row+1 > rows {
+			rows = row + 1
+		}
+	}
+	return columns, rows
+}
+
+// Position is where the i'th experiment's report card goes in the SVG report
+func (s Scenario) Position(i int) (int, int) {
+	return s.Experiments[i].position(i)
+}
+
+func (e Experiment) position(i int) (int, int) {
+	column, row := i%4, i/4
+	if e.Column != nil {
+		column = *e.Column
+	}
+	if e.Row != nil {
+		row = *e.Row
+	}
+	return column, row
+}
@@ -0,0 +1,420 @@
+package scenario_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/simulation/scenario"
+	"github.com/cloudfoundry-incubator/auction/simulation/util"
+	"github.com/cloudfoundry-incubator/auction/simulation/visualization"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scenario", func() {
+	var dir string
+
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "scenario")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("Load", func() {
+		It("loads JSON scenarios", func() {
+			path := write("cold.json", `{
+				"name": "cold starts",
+				"cells": {"count": 4, "memory_mb": 256, "zones": ["a", "b", "c"]},
+				"experiments": [{"name": "unique", "cells": 2, "workload": [{"instances": 10, "memory_mb": 8}]}]
+			}`)
+
+			loaded, err := scenario.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(loaded.Name).To(Equal("cold starts"))
+			Expect(loaded.Cells).To(Equal(scenario.Fleet{
+				CellGroup: scenario.CellGroup{
+					Count:      4,
+					MemoryMB:   256,
+					DiskMB:     100,
+					Containers: 100,
+					Stacks:     []string{"lucid64"},
+					Zones:      []string{"a", "b", "c"},
+					ZoneLayout: scenario.ZoneLayoutAlternate,
+				},
+				Groups: []scenario.CellGroup{},
+			}))
+			Expect(loaded.Experiments).To(Equal([]scenario.Experiment{
+				{Name: "unique", Cells: 2, Workload: []scenario.Workload{{Instances: 10, MemoryMB: 8}}},
+			}))
+		})
+
+		It("loads YAML scenarios, named after the file by default", func() {
+			path := write("deploy.yml", `
+experiments:
+  - initial:
+      - {first_cell: 1, num_cells: 2, instances: 5, max_instances: 7, memory_mb: 1}
+    workload:
+      - {instances: 3, memory_mb: 1, process_guid: red}
+      - {instances: 3, memory_mb: 2, colors: [blue, green]}
+    shuffle: true
+    column: 2
+`)
+
+			loaded, err := scenario.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			column := 2
+			Expect(loaded.Name).To(Equal("deploy"))
+			Expect(loaded.Cells.Size()).To(Equal(100))
+			Expect(loaded.Cells.Zones).To(Equal([]string{"Z0", "Z1"}))
+			Expect(loaded.Experiments).To(Equal([]scenario.Experiment{
+				{
+					Initial: []scenario.InitialDistribution{{FirstCell: 1, NumCells: 2, Instances: 5, MaxInstances: 7, MemoryMB: 1}},
+					Workload: []scenario.Workload{
+						{Instances: 3, MemoryMB: 1, ProcessGuid: "red"},
+						{Instances: 3, MemoryMB: 2, Colors: []string{"blue", "green"}},
+					},
+					Shuffle: true,
+					Column:  &column,
+				},
+			}))
+		})
+
+		It("rejects experiments that don't fit the fleet", func() {
+			path := write("big.json", `{
+				"cells": {"count": 2},
+				"experiments": [{"cells": 3, "workload": [{"instances": 1, "memory_mb": 1}]}]
+			}`)
+
+			_, err := scenario.Load(path)
+			Expect(err).To(MatchError("experiment 1 (): uses 3 cells but the fleet has 2"))
+		})
+
+		It("rejects initial distributions outside the experiment", func() {
+			path := write("outside.json", `{
+				"experiments": [{"cells": 3, "initial": [{"first_cell": 2, "num_cells": 2, "instances": 1, "memory_mb": 1}], "workload": [{"instances": 1, "memory_mb": 1}]}]
+			}`)
+
+			_, err := scenario.Load(path)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects workloads with both a process guid and colors", func() {
+			path := write("both.json", `{
+				"experiments": [{"workload": [{"instances": 1, "memory_mb": 1, "process_guid": "red", "colors": ["blue"]}]}]
+			}`)
+
+			_, err := scenario.Load(path)
+			Expect(err).To(MatchError("experiment 1 (): workload has both a process guid and colors"))
+		})
+
+		It("rejects scenarios without experiments", func() {
+			path := write("empty.yml", `name: empty`)
+
+			_, err := scenario.Load(path)
+			Expect(err).To(MatchError("scenario has no experiments"))
+		})
+
+		It("rejects workloads without a rootfs when no cell runs a stack", func() {
+			path := write("docker.yml", `
+cells: {arbitrary_rootfs: [docker]}
+experiments:
+  - workload: [{instances: 1, memory_mb: 1}]
+`)
+
+			_, err := scenario.Load(path)
+			Expect(err).To(MatchError("experiment 1 (): workload needs a rootfs, as the fleet has no stacks"))
+		})
+	})
+
+	Describe("fleets", func() {
+		It("fills in a group's zero fields from the fleet's", func() {
+			fleet := scenario.Fleet{
+				CellGroup: scenario.CellGroup{MemoryMB: 256, Zones: []string{"a", "b"}},
+				Groups: []scenario.CellGroup{
+					{Count: 2},
+					{Count: 3, MemoryMB: 1024, Containers: 250, ArbitraryRootFS: []string{"docker"}, ZoneLayout: scenario.ZoneLayoutBlocks},
+				},
+			}.WithDefaults()
+			Expect(fleet.Validate()).To(Succeed())
+			Expect(fleet.Size()).To(Equal(5))
+
+			cells := fleet.Cells()
+			Expect(cells).To(HaveLen(5))
+
+			Expect(cells[0]).To(Equal(scenario.CellSpec{
+				Guid:      "REP-1",
+				Zone:      "a",
+				Resources: auctiontypes.Resources{MemoryMB: 256, DiskMB: 100, Containers: 100},
+				Stacks:    []string{"lucid64"},
+			}))
+			Expect(cells[1].Zone).To(Equal("b"))
+
+			Expect(cells[2]).To(Equal(scenario.CellSpec{
+				Guid:            "REP-3",
+				Zone:            "a",
+				Resources:       auctiontypes.Resources{MemoryMB: 1024, DiskMB: 100, Containers: 250},
+				ArbitraryRootFS: []string{"docker"},
+			}))
+			Expect([]string{cells[3].Zone, cells[4].Zone}).To(Equal([]string{"a", "b"}))
+		})
+
+		It("rejects unknown zone layouts", func() {
+			fleet := scenario.Fleet{CellGroup: scenario.CellGroup{ZoneLayout: "random"}}.WithDefaults()
+			Expect(fleet.Validate()).To(MatchError(`cell group 1 has unknown zone layout "random"`))
+		})
+
+		It("loads fleets on their own", func() {
+			path := write("fleet.yml", `
+groups:
+  - {count: 3, stacks: [lucid64, cflinuxfs2]}
+  - {count: 1, memory_mb: 400, zones: [Z2]}
+`)
+
+			fleet, err := scenario.LoadFleet(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fleet.Size()).To(Equal(4))
+			Expect(fleet.DefaultRootFS()).To(Equal("preloaded:lucid64"))
+
+			cells := fleet.Cells()
+			Expect(cells[2].Stacks).To(Equal([]string{"lucid64", "cflinuxfs2"}))
+			Expect(cells[3].Zone).To(Equal("Z2"))
+			Expect(cells[3].Resources.MemoryMB).To(Equal(400))
+			Expect(scenario.RepNodeArgs(cells[2])).To(ContainElement("lucid64,cflinuxfs2"))
+		})
+	})
+
+	Describe("laying out report cards", func() {
+		It("lays experiments out four to a row unless they say where they go", func() {
+			column, row := 0, 3
+			loaded := scenario.Scenario{Experiments: make([]scenario.Experiment, 6)}
+			loaded.Experiments[5].Column = &column
+			loaded.Experiments[5].Row = &row
+
+			x, y := loaded.Position(4)
+			Expect([]int{x, y}).To(Equal([]int{0, 1}))
+			x, y = loaded.Position(5)
+			Expect([]int{x, y}).To(Equal([]int{0, 3}))
+
+			columns, rows := loaded.Grid()
+			Expect([]int{columns, rows}).To(Equal([]int{4, 4}))
+		})
+	})
+
+	Describe("configuring the auction runner", func() {
+		AfterEach(func() {
+			util.SeedFromTime()
+		})
+
+		It("leaves the scheduler on its default path without a seed", func() {
+			Expect(scenario.AuctionRunnerConfig([]string{"cell-a", "cell-b"})).To(Equal(auctionrunner.Config{}))
+		})
+
+		It("schedules deterministically, settling ties in an order drawn from the seed, given one", func() {
+			guids := []string{"cell-a", "cell-b", "cell-c", "cell-d", "cell-e", "cell-f"}
+			order := func() []string {
+				util.Reset()
+				config := scenario.AuctionRunnerConfig(guids)
+				Expect(config.Deterministic).To(BeTrue())
+
+				sorted := append([]string{}, guids...)
+				sort.Slice(sorted, func(i, j int) bool {
+					return config.TieBreak(&auctionrunner.Cell{Guid: sorted[i]}, &auctionrunner.Cell{Guid: sorted[j]})
+				})
+				return sorted
+			}
+
+			util.Seed(3)
+			first := order()
+			Expect(order()).To(Equal(first))
+			Expect(first).To(ConsistOf(guids))
+		})
+	})
+
+	Describe("running experiments", func() {
+		var fleet scenario.Fleet
+		var simulation *scenario.Simulation
+
+		BeforeEach(func() {
+			fleet = scenario.DefaultFleet()
+			fleet.CellGroup.Count = 4
+		})
+
+		JustBeforeEach(func() {
+			loaded := scenario.Scenario{Cells: fleet}
+			simulation = scenario.New(loaded, scenario.BuildInProcessCells(fleet), 10, 10*time.Second, lagertest.NewTestLogger("test"))
+		})
+
+		It("auctions the workload among the experiment's cells, on top of the initial instances", func() {
+			report, err := simulation.RunExperiment(scenario.Experiment{
+				Cells:   2,
+				Initial: []scenario.InitialDistribution{{FirstCell: 1, NumCells: 1, Instances: 10, MemoryMB: 1}},
+				Workload: []scenario.Workload{
+					{Instances: 4, MemoryMB: 10, ProcessGuid: "red"},
+					{Instances: 6, MemoryMB: 1},
+				},
+				Shuffle: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.NumAuctions).To(Equal(10))
+			Expect(report.AuctionResults.SuccessfulLRPs).To(HaveLen(10))
+			Expect(report.Cells).To(HaveLen(2))
+			Expect(report.CellStates["REP-1"].Zone).To(Equal("Z0"))
+			Expect(report.CellStates["REP-2"].Zone).To(Equal("Z1"))
+
+			placed := len(report.InstancesByRep["REP-1"]) + len(report.InstancesByRep["REP-2"])
+			Expect(placed).To(Equal(20))
+		})
+
+		It("starts every experiment from empty cells", func() {
+			experiment := scenario.Experiment{Workload: []scenario.Workload{{Instances: 8, MemoryMB: 1}}}
+
+			_, err := simulation.RunExperiment(experiment)
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := simulation.RunExperiment(experiment)
+			Expect(err).NotTo(HaveOccurred())
+
+			placed := 0
+			for _, instances := range report.InstancesByRep {
+				placed += len(instances)
+			}
+			Expect(placed).To(Equal(8))
+		})
+
+		It("schedules concurrently, as in production, without a seed", func() {
+			report, err := simulation.RunExperiment(scenario.Experiment{Workload: []scenario.Workload{{Instances: 8, MemoryMB: 1}}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(util.Seeded()).To(BeFalse())
+			Expect(report.Deterministic).To(BeFalse())
+			Expect(report.Seed).To(Equal(util.CurrentSeed()))
+		})
+
+		Context("with a seed", func() {
+			var experiment scenario.Experiment
+
+			BeforeEach(func() {
+				fleet.CellGroup.Count = 12
+				experiment = scenario.Experiment{
+					Initial: []scenario.InitialDistribution{{Instances: 1, MaxInstances: 20, MemoryMB: 1}},
+					Workload: []scenario.Workload{
+						{Instances: 40, MemoryMB: 1, Colors: []string{"red", "blue", "green"}},
+						{Instances: 30, MemoryMB: 2},
+					},
+					Shuffle: true,
+				}
+			})
+
+			AfterEach(func() {
+				util.SeedFromTime()
+			})
+
+			run := func(seed int64) *visualization.Report {
+				util.Seed(seed)
+				simulation := scenario.New(scenario.Scenario{Cells: fleet}, scenario.BuildInProcessCells(fleet), 10, 10*time.Second, lagertest.NewTestLogger("test"))
+				report, err := simulation.RunExperiment(experiment)
+				Expect(err).NotTo(HaveOccurred())
+				return report
+			}
+
+			It("places the same work on the same cells every time", func() {
+				first := run(7)
+				Expect(first.Seed).To(Equal(int64(7)))
+				Expect(first.Deterministic).To(BeTrue())
+
+				Expect(run(7).InstancesByRep).To(Equal(first.InstancesByRep))
+				Expect(run(8).InstancesByRep).NotTo(Equal(first.InstancesByRep))
+			})
+
+			It("places the same work however many experiments ran before", func() {
+				first := run(7)
+
+				_, err := simulation.RunExperiment(experiment)
+				Expect(err).NotTo(HaveOccurred())
+				again, err := simulation.RunExperiment(experiment)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(again.InstancesByRep).To(Equal(first.InstancesByRep))
+			})
+		})
+
+		Context("with cells that run different rootfses", func() {
+			BeforeEach(func() {
+				fleet = scenario.Fleet{Groups: []scenario.CellGroup{
+					{Count: 2},
+					{Count: 2, MemoryMB: 400, ArbitraryRootFS: []string{"docker"}},
+				}}.WithDefaults()
+			})
+
+			It("places each workload only on cells that can run its rootfs", func() {
+				report, err := simulation.RunExperiment(scenario.Experiment{
+					Workload: []scenario.Workload{
+						{Instances: 6, MemoryMB: 10, RootFS: "docker:///busybox"},
+						{Instances: 4, MemoryMB: 10},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.AuctionResults.SuccessfulLRPs).To(HaveLen(10))
+				Expect(report.CellStates["REP-3"].TotalResources.MemoryMB).To(Equal(400))
+
+				onLucid := len(report.InstancesByRep["REP-1"]) + len(report.InstancesByRep["REP-2"])
+				onDocker := len(report.InstancesByRep["REP-3"]) + len(report.InstancesByRep["REP-4"])
+				Expect(onLucid).To(Equal(4))
+				Expect(onDocker).To(Equal(6))
+			})
+		})
+
+		It("auctions every instance of workloads that share process guids", func() {
+			report, err := simulation.RunExperiment(scenario.Experiment{
+				Workload: []scenario.Workload{
+					{Instances: 3, MemoryMB: 1, ProcessGuid: "red"},
+					{Instances: 3, MemoryMB: 1, ProcessGuid: "red"},
+					{Instances: 4, MemoryMB: 1, Colors: []string{"red", "blue"}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.NumAuctions).To(Equal(10))
+			Expect(report.AuctionResults.SuccessfulLRPs).To(HaveLen(10))
+
+			identifiers := map[string]bool{}
+			for _, lrp := range report.AuctionResults.SuccessfulLRPs {
+				identifiers[lrp.Identifier()] = true
+			}
+			Expect(identifiers).To(HaveLen(10))
+		})
+
+		It("reports the auctions that could not be placed", func() {
+			report, err := simulation.RunExperiment(scenario.Experiment{
+				Cells:    1,
+				Workload: []scenario.Workload{{Instances: 3, MemoryMB: 40}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.AuctionResults.SuccessfulLRPs).To(HaveLen(2))
+			Expect(report.AuctionResults.FailedLRPs).To(HaveLen(1))
+		})
+	})
+})
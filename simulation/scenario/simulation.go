@@ -0,0 +1,189 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/simulation/simulationrep"
+	"github.com/cloudfoundry-incubator/auction/simulation/util"
+	"github.com/cloudfoundry-incubator/auction/simulation/visualization"
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+// BuildInProcessCells builds an in-memory simulation rep for every cell of
+// the fleet
+func BuildInProcessCells(fleet Fleet) map[string]auctiontypes.SimulationCellRep {
+	cells := map[string]auctiontypes.SimulationCellRep{}
+	for _, cell := range fleet.Cells() {
+		cells[cell.Guid] = simulationrep.NewWithRootFSProviders(cell.RootFSProviders(), cell.Zone, cell.Resources)
+	}
+	return cells
+}
+
+// Simulation runs a scenario's experiments against its fleet's cells, which
+// may be in-process or remote.
+type Simulation struct {
+	scenario       Scenario
+	cells          map[string]auctiontypes.SimulationCellRep
+	workers        int
+	resultsTimeout time.Duration
+	logger         lager.Logger
+}
+
+func New(scenario Scenario, cells map[string]auctiontypes.SimulationCellRep, workers int, resultsTimeout time.Duration, logger lager.Logger) *Simulation {
+	return &Simulation{
+		scenario:       scenario,
+		cells:          cells,
+		workers:        workers,
+		resultsTimeout: resultsTimeout,
+		logger:         logger,
+	}
+}
+
+// RunExperiment resets the cells, places the experiment's initial instances
+// on them, and auctions its workload through an auction runner.  It returns
+// once every auction has a result, or fails after the results timeout.
+//
+// The workload is drawn from util.R, restarted from its seed.  If a seed was
+// given the scheduler runs deterministically, settling ties in an order also
+// drawn from util.R, so an experiment run twice with the same seed places the
+// same work on the same cells; otherwise it runs concurrently, as in
+// production.  The report says which.
+func (s *Simulation) RunExperiment(experiment Experiment) (*visualization.Report, error) {
+	workPool := workpool.NewWorkPool(s.workers)
+	defer workPool.Stop()
+
+	s.resetCells(workPool)
+	util.Reset()
+
+	numCells := experiment.NumCells(s.scenario.Cells)
+	delegate := newRunnerDelegate(s.cells, numCells)
+	config := AuctionRunnerConfig(delegate.cellGuids())
+	runner := auctionrunner.NewWithConfig(delegate, metricEmitterDelegate{}, clock.NewClock(), workPool, s.logger, config)
+	runnerProcess := ifrit.Invoke(runner)
+	defer func() {
+		runnerProcess.Signal(os.Interrupt)
+		<-runnerProcess.Wait()
+	}()
+
+	s.placeInitialInstances(workPool, experiment.InitialInstances(s.scenario.Cells))
+
+	starts := experiment.StartAuctions(s.scenario.Cells)
+	t := time.Now()
+	runner.ScheduleLRPsForAuctions(starts)
+
+	for delegate.ResultSize() < len(starts) {
+		if time.Since(t) > s.resultsTimeout {
+			return nil, fmt.Errorf("got %d of %d results after %s", delegate.ResultSize(), len(starts), s.resultsTimeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	duration := time.Since(t)
+
+	cells, _ := delegate.FetchCellReps()
+	return visualization.NewReport(util.CurrentSeed(), config.Deterministic, len(starts), cells, delegate.Results(), duration), nil
+}
+
+func (s *Simulation) resetCells(workPool *workpool.WorkPool) {
+	wg := &sync.WaitGroup{}
+	wg.Add(len(s.cells))
+	for _, cell := range s.cells {
+		cell := cell
+		workPool.Submit(func() {
+			cell.Reset()
+			wg.Done()
+		})
+	}
+	wg.Wait()
+}
+
+func (s *Simulation) placeInitialInstances(workPool *workpool.WorkPool, distributions map[int][]auctiontypes.LRP) {
+	rootFS := s.scenario.Cells.DefaultRootFS()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(distributions))
+	for index, instances := range distributions {
+		cell := s.cells[CellGuid(index)]
+		instances := instances
+		workPool.Submit(func() {
+			cell.Perform(workForInstances(instances, rootFS))
+			wg.Done()
+		})
+	}
+	wg.Wait()
+}
+
+// runnerDelegate hands the auction runner the first numCells cells of the
+// fleet and collects the results
+type runnerDelegate struct {
+	cells       map[string]auctiontypes.CellRep
+	workResults auctiontypes.AuctionResults
+	lock        *sync.Mutex
+}
+
+func newRunnerDelegate(cells map[string]auctiontypes.SimulationCellRep, numCells int) *runnerDelegate {
+	subset := map[string]auctiontypes.CellRep{}
+	for i := 0; i < numCells; i++ {
+		subset[CellGuid(i)] = cells[CellGuid(i)]
+	}
+
+	return &runnerDelegate{
+		cells: subset,
+		lock:  &sync.Mutex{},
+	}
+}
+
+func (d *runnerDelegate) cellGuids() []string {
+	guids := []string{}
+	for guid := range d.cells {
+		guids = append(guids, guid)
+	}
+	return guids
+}
+
+func (d *runnerDelegate) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	return d.cells, nil
+}
+
+func (d *runnerDelegate) AuctionCompleted(work auctiontypes.AuctionResults) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.workResults.FailedLRPs = append(d.workResults.FailedLRPs, work.FailedLRPs...)
+	d.workResults.FailedTasks = append(d.workResults.FailedTasks, work.FailedTasks...)
+	d.workResults.SuccessfulLRPs = append(d.workResults.SuccessfulLRPs, work.SuccessfulLRPs...)
+	d.workResults.SuccessfulTasks = append(d.workResults.SuccessfulTasks, work.SuccessfulTasks...)
+	d.workResults.AlreadyRunningLRPs = append(d.workResults.AlreadyRunningLRPs, work.AlreadyRunningLRPs...)
+	d.workResults.AlreadyRunningTasks = append(d.workResults.AlreadyRunningTasks, work.AlreadyRunningTasks...)
+}
+
+func (d *runnerDelegate) ResultSize() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return len(d.workResults.FailedLRPs) +
+		len(d.workResults.FailedTasks) +
+		len(d.workResults.SuccessfulLRPs) +
+		len(d.workResults.SuccessfulTasks) +
+		len(d.workResults.AlreadyRunningLRPs) +
+		len(d.workResults.AlreadyRunningTasks)
+}
+
+func (d *runnerDelegate) Results() auctiontypes.AuctionResults {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.workResults
+}
+
+type metricEmitterDelegate struct{}
+
+func (metricEmitterDelegate) FetchStatesCompleted(time.Duration) {}
+
+func (metricEmitterDelegate) AuctionCompleted(auctiontypes.AuctionResults) {}
@@ -0,0 +1,48 @@
+package scenario
+
+import (
+	"sort"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/simulation/util"
+)
+
+// AuctionRunnerConfig configures an auction runner for a simulation among
+// cellGuids.  Given a seed, it schedules deterministically, settling ties
+// with RandomTieBreak, so that the run can be reproduced; otherwise it leaves
+// the scheduler on its default, concurrent path, as in production.
+func AuctionRunnerConfig(cellGuids []string) auctionrunner.Config {
+	if !util.Seeded() {
+		return auctionrunner.Config{}
+	}
+
+	return auctionrunner.Config{
+		Deterministic: true,
+		TieBreak:      RandomTieBreak(cellGuids),
+	}
+}
+
+// RandomTieBreak settles equal scores between cells in an order drawn from
+// util.R, rather than in Go's map order, so that placements are reproducible
+// but still spread ties around the cells
+func RandomTieBreak(cellGuids []string) auctionrunner.TieBreakPolicy {
+	sorted := append([]string{}, cellGuids...)
+	sort.Strings(sorted)
+
+	ranks := map[string]int{}
+	for rank, index := range util.R.Perm(len(sorted)) {
+		ranks[sorted[index]] = rank
+	}
+
+	return func(candidate, current *auctionrunner.Cell) bool {
+		candidateRank, candidateRanked := ranks[candidate.Guid]
+		currentRank, currentRanked := ranks[current.Guid]
+		if candidateRanked != currentRanked {
+			return candidateRanked
+		}
+		if !candidateRanked {
+			return candidate.Guid < current.Guid
+		}
+		return candidateRank < currentRank
+	}
+}
@@ -0,0 +1,117 @@
+package scenario
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/simulation/util"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+)
+
+// CellGuid is the guid of the index'th cell of a fleet, matching the guids the
+// visualization reports on
+func CellGuid(index int) string {
+	return fmt.Sprintf("REP-%d", index+1)
+}
+
+// InitialInstances is the LRPs each cell starts the experiment with, by cell
+// index
+func (e Experiment) InitialInstances(fleet Fleet) map[int][]auctiontypes.LRP {
+	distributions := map[int][]auctiontypes.LRP{}
+
+	for _, initial := range e.Initial {
+		numCells := initial.NumCells
+		if numCells == 0 {
+			numCells = e.NumCells(fleet) - initial.FirstCell
+		}
+
+		for i := initial.FirstCell; i < initial.FirstCell+numCells; i++ {
+			instances := initial.Instances
+			if initial.MaxInstances > initial.Instances {
+				instances = util.RandomIntIn(initial.Instances, initial.MaxInstances)
+			}
+
+			for j := 0; j < instances; j++ {
+				distributions[i] = append(distributions[i], auctiontypes.LRP{
+					ProcessGuid: util.NewGrayscaleGuid("AAA"),
+					MemoryMB:    initial.MemoryMB,
+					DiskMB:      1,
+				})
+			}
+		}
+	}
+
+	return distributions
+}
+
+// StartAuctions is the LRP start auctions of the experiment's workloads.
+// Instances of a process guid are indexed from zero across every workload, so
+// workloads that share a process guid or colors never start the same instance
+// twice.
+func (e Experiment) StartAuctions(fleet Fleet) []models.LRPStartRequest {
+	starts := []models.LRPStartRequest{}
+	indices := map[string]int{}
+
+	for _, workload := range e.Workload {
+		rootFS := workload.RootFS
+		if rootFS == "" {
+			rootFS = fleet.DefaultRootFS()
+		}
+
+		for i := 0; i < workload.Instances; i++ {
+			var processGuid string
+			switch {
+			case workload.ProcessGuid != "":
+				processGuid = workload.ProcessGuid
+			case len(workload.Colors) > 0:
+				processGuid = workload.Colors[util.R.Intn(len(workload.Colors))]
+			default:
+				processGuid = util.NewGrayscaleGuid("BBB")
+			}
+
+			starts = append(starts, newLRPStartAuction(processGuid, indices[processGuid], workload.MemoryMB, rootFS))
+			indices[processGuid]++
+		}
+	}
+
+	if e.Shuffle {
+		shuffled := make([]models.LRPStartRequest, len(starts))
+		for i, index := range util.R.Perm(len(starts)) {
+			shuffled[i] = starts[index]
+		}
+		starts = shuffled
+	}
+
+	return starts
+}
+
+func newLRPStartAuction(processGuid string, index int, memoryMB int, rootFS string) models.LRPStartRequest {
+	return models.LRPStartRequest{
+		DesiredLRP: models.DesiredLRP{
+			ProcessGuid: processGuid,
+			MemoryMB:    memoryMB,
+			DiskMB:      1,
+			RootFS:      rootFS,
+			Domain:      "domain",
+		},
+		Indices: []uint{uint(index)},
+	}
+}
+
+func workForInstances(lrps []auctiontypes.LRP, rootFS string) auctiontypes.Work {
+	work := auctiontypes.Work{}
+	for _, lrp := range lrps {
+		work.LRPs = append(work.LRPs, auctiontypes.LRPAuction{
+			DesiredLRP: models.DesiredLRP{
+				ProcessGuid: lrp.ProcessGuid,
+				MemoryMB:    lrp.MemoryMB,
+				DiskMB:      lrp.DiskMB,
+				RootFS:      rootFS,
+				Domain:      "domain",
+			},
+
+			Index: lrp.Index,
+		})
+	}
+	return work
+}
@@ -1,6 +1,7 @@
 package simulation_test
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -11,7 +12,12 @@ import (
 	"runtime"
 	"sync"
 
+	"github.com/cloudfoundry-incubator/auction/communication/grpc/auction_grpc_client"
 	"github.com/cloudfoundry-incubator/auction/communication/http/auction_http_client"
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls"
+	"github.com/cloudfoundry-incubator/auction/communication/http/mutual_tls/certauthority"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/pivotal-golang/clock"
 
@@ -20,7 +26,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/auction/auctionrunner"
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
-	"github.com/cloudfoundry-incubator/auction/simulation/simulationrep"
+	"github.com/cloudfoundry-incubator/auction/simulation/scenario"
 	"github.com/cloudfoundry-incubator/auction/simulation/util"
 	"github.com/cloudfoundry-incubator/auction/simulation/visualization"
 	. "github.com/onsi/ginkgo"
@@ -37,25 +43,23 @@ var communicationMode string
 
 const InProcess = "inprocess"
 const HTTP = "http"
+const GRPC = "grpc"
 const lucidStack = "lucid64"
 
-const numCells = 100
-
 var cells map[string]auctiontypes.SimulationCellRep
-
-var repResources = auctiontypes.Resources{
-	MemoryMB:   100.0,
-	DiskMB:     100.0,
-	Containers: 100,
-}
+var fleet scenario.Fleet
+var fleetPath string
 
 var timeout time.Duration
 var workers int
+var seed int64
 
 var svgReport *visualization.SVGReport
 var reports []*visualization.Report
 var reportName string
 var disableSVGReport bool
+var disableTLS bool
+var certsDir string
 
 var sessionsToTerminate []*gexec.Session
 var runnerProcess ifrit.Process
@@ -65,9 +69,12 @@ var runner auctiontypes.AuctionRunner
 var logger lager.Logger
 
 func init() {
-	flag.StringVar(&communicationMode, "communicationMode", "inprocess", "one of inprocess or http")
+	flag.StringVar(&communicationMode, "communicationMode", "inprocess", "one of inprocess, http or grpc")
 	flag.DurationVar(&timeout, "timeout", time.Second, "timeout when waiting for responses from remote calls")
 	flag.IntVar(&workers, "workers", 500, "number of concurrent communication worker pools")
+	flag.BoolVar(&disableTLS, "disableTLS", false, "talk plain http, rather than mutual TLS, in http communicationMode")
+	flag.Int64Var(&seed, "seed", 0, "seed to draw the simulation's randomness from; zero picks one from the time and schedules concurrently, as in production, rather than deterministically")
+	flag.StringVar(&fleetPath, "fleet", "", "JSON or YAML file describing the cells, as in a scenario file; the experiments expect at least 100 cells running lucid64")
 
 	flag.BoolVar(&disableSVGReport, "disableSVGReport", false, "disable displaying SVG reports of the simulation runs")
 	flag.StringVar(&reportName, "reportName", "report", "report name")
@@ -80,7 +87,17 @@ func TestAuction(t *testing.T) {
 
 var _ = BeforeSuite(func() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
-	fmt.Printf("Running in %s communicationMode\n", communicationMode)
+	if seed != 0 {
+		util.Seed(seed)
+	}
+	fmt.Printf("Running in %s communicationMode with seed %d, %s scheduling\n", communicationMode, util.CurrentSeed(), visualization.SchedulingMode(util.Seeded()))
+
+	fleet = scenario.DefaultFleet()
+	if fleetPath != "" {
+		var err error
+		fleet, err = scenario.LoadFleet(fleetPath)
+		Expect(err).NotTo(HaveOccurred())
+	}
 
 	startReport()
 
@@ -93,6 +110,8 @@ var _ = BeforeSuite(func() {
 		cells = buildInProcessReps()
 	case HTTP:
 		cells = launchExternalHTTPReps()
+	case GRPC:
+		cells = launchExternalGRPCReps()
 	default:
 		panic(fmt.Sprintf("unknown communication mode: %s", communicationMode))
 	}
@@ -112,16 +131,22 @@ var _ = BeforeEach(func() {
 	}
 	wg.Wait()
 
-	util.ResetGuids()
+	util.Reset()
+
+	cellGuids := []string{}
+	for guid := range cells {
+		cellGuids = append(cellGuids, guid)
+	}
 
 	runnerDelegate = NewAuctionRunnerDelegate(cells)
 	metricEmitterDelegate := NewAuctionMetricEmitterDelegate()
-	runner = auctionrunner.New(
+	runner = auctionrunner.NewWithConfig(
 		runnerDelegate,
 		metricEmitterDelegate,
 		clock.NewClock(),
 		workPool,
 		logger,
+		scenario.AuctionRunnerConfig(cellGuids),
 	)
 	runnerProcess = ifrit.Invoke(runner)
 })
@@ -140,63 +165,106 @@ var _ = AfterSuite(func() {
 	for _, sess := range sessionsToTerminate {
 		sess.Kill().Wait()
 	}
+
+	if certsDir != "" {
+		os.RemoveAll(certsDir)
+	}
 })
 
 func cellGuid(index int) string {
 	return fmt.Sprintf("REP-%d", index+1)
 }
 
-func zone(index int) string {
-	return fmt.Sprintf("Z%d", index%2)
+func buildInProcessReps() map[string]auctiontypes.SimulationCellRep {
+	return scenario.BuildInProcessCells(fleet)
 }
 
-func buildInProcessReps() map[string]auctiontypes.SimulationCellRep {
+func launchExternalHTTPReps() map[string]auctiontypes.SimulationCellRep {
+	repNodeBinary, err := gexec.Build("github.com/cloudfoundry-incubator/auction/simulation/repnode")
+	Expect(err).NotTo(HaveOccurred())
+
+	var ca *certauthority.CertAuthority
+	var clientTLSConfig *tls.Config
+	if !disableTLS {
+		certsDir, err = ioutil.TempDir("", "simulation-certs")
+		Expect(err).NotTo(HaveOccurred())
+
+		ca, err = certauthority.New(certsDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		certFile, keyFile, err := ca.GenerateClientCert("auctioneer")
+		Expect(err).NotTo(HaveOccurred())
+
+		clientTLSConfig, err = mutual_tls.Config{
+			CACertFile: ca.CACertFile(),
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+		}.ClientTLSConfig()
+		Expect(err).NotTo(HaveOccurred())
+	}
+
 	cells := map[string]auctiontypes.SimulationCellRep{}
 
-	for i := 0; i < numCells; i++ {
-		cells[cellGuid(i)] = simulationrep.New(lucidStack, zone(i), repResources)
+	client := &http.Client{
+		Timeout: timeout,
+	}
+	for i, cell := range fleet.Cells() {
+		repGuid := cell.Guid
+		httpAddr := fmt.Sprintf("127.0.0.1:%d", 30000+i)
+
+		args := append(scenario.RepNodeArgs(cell), "-httpAddr", httpAddr)
+
+		repClient := client
+		repURL := "http://" + httpAddr
+		if ca != nil {
+			certFile, keyFile, err := ca.GenerateServerCert(repGuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			args = append(args, "-caFile", ca.CACertFile(), "-certFile", certFile, "-keyFile", keyFile, "-auctioneerName", "auctioneer")
+			repClient = mutual_tls.NewCellClient(clientTLSConfig, repGuid, timeout)
+			repURL = "https://" + httpAddr
+		}
+
+		sess, err := gexec.Start(exec.Command(repNodeBinary, args...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		sessionsToTerminate = append(sessionsToTerminate, sess)
+		Eventually(sess).Should(gbytes.Say("listening"))
+
+		cells[repGuid] = auction_http_client.New(repClient, repGuid, repURL, logger)
 	}
 
 	return cells
 }
 
-func launchExternalHTTPReps() map[string]auctiontypes.SimulationCellRep {
+func launchExternalGRPCReps() map[string]auctiontypes.SimulationCellRep {
 	repNodeBinary, err := gexec.Build("github.com/cloudfoundry-incubator/auction/simulation/repnode")
 	Expect(err).NotTo(HaveOccurred())
 
 	cells := map[string]auctiontypes.SimulationCellRep{}
 
-	client := &http.Client{
-		Timeout: timeout,
-	}
-	for i := 0; i < numCells; i++ {
-		repGuid := cellGuid(i)
-		httpAddr := fmt.Sprintf("127.0.0.1:%d", 30000+i)
+	for i, cell := range fleet.Cells() {
+		repGuid := cell.Guid
+		grpcAddr := fmt.Sprintf("127.0.0.1:%d", 31000+i)
 
-		serverCmd := exec.Command(
-			repNodeBinary,
-			"-repGuid", repGuid,
-			"-httpAddr", httpAddr,
-			"-memoryMB", fmt.Sprintf("%d", repResources.MemoryMB),
-			"-diskMB", fmt.Sprintf("%d", repResources.DiskMB),
-			"-containers", fmt.Sprintf("%d", repResources.Containers),
-			"-zone", zone(i),
-		)
+		serverCmd := exec.Command(repNodeBinary, append(scenario.RepNodeArgs(cell), "-grpcAddr", grpcAddr)...)
 
 		sess, err := gexec.Start(serverCmd, GinkgoWriter, GinkgoWriter)
 		Expect(err).NotTo(HaveOccurred())
 		sessionsToTerminate = append(sessionsToTerminate, sess)
 		Eventually(sess).Should(gbytes.Say("listening"))
 
-		cells[cellGuid(i)] = auction_http_client.New(client, cellGuid(i), "http://"+httpAddr, logger)
+		conn, err := auction_grpc_client.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+
+		cells[repGuid] = auction_grpc_client.New(conn, repGuid, timeout, logger)
 	}
 
 	return cells
 }
 
 func startReport() {
-	svgReport = visualization.StartSVGReport("./"+reportName+".svg", 4, 3, numCells)
-	svgReport.DrawHeader(communicationMode)
+	svgReport = visualization.StartSVGReport("./"+reportName+".svg", 4, 3, fleet.Size())
+	svgReport.DrawHeader(communicationMode, util.CurrentSeed(), util.Seeded())
 }
 
 func finishReport() {
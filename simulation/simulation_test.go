@@ -105,7 +105,7 @@ var _ = Describe("Auction", func() {
 		duration := time.Since(t)
 
 		cells, _ := runnerDelegate.FetchCellReps()
-		report := visualization.NewReport(len(lrpStartAuctions), cells, runnerDelegate.Results(), duration)
+		report := visualization.NewReport(util.CurrentSeed(), util.Seeded(), len(lrpStartAuctions), cells, runnerDelegate.Results(), duration)
 
 		visualization.PrintReport(report)
 		svgReport.DrawReportCard(i, j, report)
@@ -1,6 +1,7 @@
 package simulationrep
 
 import (
+	"context"
 	"sync"
 
 	"github.com/cloudfoundry-incubator/auction/auctiontypes"
@@ -8,22 +9,31 @@ import (
 )
 
 type SimulationRep struct {
-	stack          string
-	zone           string
-	totalResources auctiontypes.Resources
-	lrps           map[string]auctiontypes.LRP
-	tasks          map[string]auctiontypes.Task
+	rootFSProviders auctiontypes.RootFSProviders
+	zone            string
+	totalResources  auctiontypes.Resources
+	lrps            map[string]auctiontypes.LRP
+	tasks           map[string]auctiontypes.Task
+	evacuating      bool
+	generation      uint64
 
 	lock *sync.Mutex
 }
 
 func New(stack string, zone string, totalResources auctiontypes.Resources) auctiontypes.SimulationCellRep {
+	return NewWithRootFSProviders(RootFSProviders([]string{stack}, nil), zone, totalResources)
+}
+
+// NewWithRootFSProviders builds a rep that can run any rootfs one of
+// rootFSProviders matches
+func NewWithRootFSProviders(rootFSProviders auctiontypes.RootFSProviders, zone string, totalResources auctiontypes.Resources) auctiontypes.SimulationCellRep {
 	return &SimulationRep{
-		stack:          stack,
-		totalResources: totalResources,
-		lrps:           map[string]auctiontypes.LRP{},
-		tasks:          map[string]auctiontypes.Task{},
-		zone:           zone,
+		rootFSProviders: rootFSProviders,
+		totalResources:  totalResources,
+		lrps:            map[string]auctiontypes.LRP{},
+		tasks:           map[string]auctiontypes.Task{},
+		zone:            zone,
+		generation:      1,
 
 		lock: &sync.Mutex{},
 	}
@@ -48,14 +58,14 @@ func (rep *SimulationRep) State() (auctiontypes.CellState, error) {
 	// util.RandomSleep(800, 900)
 
 	return auctiontypes.CellState{
-		RootFSProviders: auctiontypes.RootFSProviders{
-			models.PreloadedRootFSScheme: auctiontypes.NewFixedSetRootFSProvider(rep.stack),
-		},
+		RootFSProviders:    rep.rootFSProviders,
 		AvailableResources: availableResources,
 		TotalResources:     rep.totalResources,
 		LRPs:               lrps,
 		Tasks:              tasks,
 		Zone:               rep.zone,
+		Evacuating:         rep.evacuating,
+		Generation:         rep.generation,
 	}, nil
 }
 
@@ -63,6 +73,11 @@ func (rep *SimulationRep) Perform(work auctiontypes.Work) (auctiontypes.Work, er
 	rep.lock.Lock()
 	defer rep.lock.Unlock()
 
+	if work.Generation != 0 && work.Generation != rep.generation {
+		return auctiontypes.Work{}, auctiontypes.ErrorStaleCellState
+	}
+	rep.generation++
+
 	failedWork := auctiontypes.Work{}
 
 	availableResources := rep.availableResources()
@@ -109,6 +124,35 @@ func (rep *SimulationRep) Perform(work auctiontypes.Work) (auctiontypes.Work, er
 	return failedWork, nil
 }
 
+func (rep *SimulationRep) StateContext(ctx context.Context) (auctiontypes.CellState, error) {
+	err := ctx.Err()
+	if err != nil {
+		return auctiontypes.CellState{}, err
+	}
+	return rep.State()
+}
+
+func (rep *SimulationRep) PerformContext(ctx context.Context, work auctiontypes.Work) (auctiontypes.Work, error) {
+	err := ctx.Err()
+	if err != nil {
+		return auctiontypes.Work{}, err
+	}
+	return rep.Perform(work)
+}
+
+// RootFSProviders provides the preloaded stacks, and any rootfs with one of
+// the arbitrary schemes, such as docker
+func RootFSProviders(stacks []string, arbitrarySchemes []string) auctiontypes.RootFSProviders {
+	providers := auctiontypes.RootFSProviders{}
+	if len(stacks) > 0 {
+		providers[models.PreloadedRootFSScheme] = auctiontypes.NewFixedSetRootFSProvider(stacks...)
+	}
+	for _, scheme := range arbitrarySchemes {
+		providers[scheme] = auctiontypes.ArbitraryRootFSProvider{}
+	}
+	return providers
+}
+
 //simulation only
 
 func (rep *SimulationRep) Reset() error {
@@ -117,6 +161,44 @@ func (rep *SimulationRep) Reset() error {
 
 	rep.lrps = map[string]auctiontypes.LRP{}
 	rep.tasks = map[string]auctiontypes.Task{}
+	rep.evacuating = false
+	rep.generation++
+	return nil
+}
+
+// Evacuate makes the rep report that it is evacuating, so that auctions
+// place no more work on it
+func (rep *SimulationRep) Evacuate() {
+	rep.lock.Lock()
+	defer rep.lock.Unlock()
+
+	rep.evacuating = true
+	rep.generation++
+}
+
+func (rep *SimulationRep) StopLRP(processGuid string, index int) error {
+	rep.lock.Lock()
+	defer rep.lock.Unlock()
+
+	identifier := auctiontypes.IdentifierForLRP(processGuid, index)
+	if _, ok := rep.lrps[identifier]; !ok {
+		return auctiontypes.ErrorNothingToStop
+	}
+	delete(rep.lrps, identifier)
+	rep.generation++
+	return nil
+}
+
+// CompleteTask removes a task the rep has finished running
+func (rep *SimulationRep) CompleteTask(taskGuid string) error {
+	rep.lock.Lock()
+	defer rep.lock.Unlock()
+
+	if _, ok := rep.tasks[taskGuid]; !ok {
+		return auctiontypes.ErrorNothingToStop
+	}
+	delete(rep.tasks, taskGuid)
+	rep.generation++
 	return nil
 }
 
@@ -0,0 +1,150 @@
+package timeline
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ajstarks/svgo"
+
+	"github.com/cloudfoundry-incubator/auction/simulation/visualization"
+)
+
+var chartWidth = 960
+var chartMarginLeft = 70
+var chartMarginRight = 20
+var chartHeaderHeight = 50
+var panelHeight = 160
+var panelSpacing = 50
+var plotWidth = chartWidth - chartMarginLeft - chartMarginRight
+
+var chartFont = "font-family:Helvetica Neue"
+
+type curve struct {
+	name   string
+	color  string
+	values []float64
+}
+
+// WriteSVG draws the report's samples as utilization, pending-queue and
+// wait-time curves over the timeline
+func WriteSVG(path string, report *Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	drawCurves(f, report)
+	return nil
+}
+
+func drawCurves(w io.Writer, report *Report) {
+	s := svg.New(w)
+	s.Start(chartWidth, chartHeaderHeight+3*(panelHeight+panelSpacing))
+	s.Text(chartMarginLeft, 30, fmt.Sprintf("%s, %s, seed %d, %s scheduling", report.Name, report.Duration, report.Seed, visualization.SchedulingMode(report.Deterministic)), "text-anchor:start;font-size:20px;"+chartFont)
+
+	memory, containers := []float64{}, []float64{}
+	pendingLRPs, pendingTasks := []float64{}, []float64{}
+	medians, p95s := []float64{}, []float64{}
+	for _, sample := range report.Samples {
+		memory = append(memory, sample.MemoryUtilization*100)
+		containers = append(containers, sample.ContainerUtilization*100)
+		pendingLRPs = append(pendingLRPs, float64(sample.PendingLRPs))
+		pendingTasks = append(pendingTasks, float64(sample.PendingTasks))
+		medians = append(medians, sample.WaitTimes.Median.Seconds())
+		p95s = append(p95s, sample.WaitTimes.P95.Seconds())
+	}
+
+	y := chartHeaderHeight
+	drawPanel(s, y, report, "Utilization (%)", 100, []curve{
+		{"memory", "#36c", memory},
+		{"containers", "#e80", containers},
+	})
+	y += panelHeight + panelSpacing
+	drawPanel(s, y, report, "Pending", 0, []curve{
+		{"lrps", "#36c", pendingLRPs},
+		{"tasks", "#e80", pendingTasks},
+	})
+	y += panelHeight + panelSpacing
+	drawPanel(s, y, report, "Wait time (s)", 0, []curve{
+		{"median", "#36c", medians},
+		{"p95", "#c33", p95s},
+	})
+
+	s.End()
+}
+
+// drawPanel plots the curves against the samples' elapsed times, up to maxY
+// or, if it is zero, the largest value
+func drawPanel(s *svg.SVG, top int, report *Report, title string, maxY float64, curves []curve) {
+	if maxY == 0 {
+		for _, c := range curves {
+			for _, v := range c.values {
+				if v > maxY {
+					maxY = v
+				}
+			}
+		}
+		if maxY == 0 {
+			maxY = 1
+		}
+	}
+
+	s.Translate(chartMarginLeft, top)
+	s.Rect(0, 0, plotWidth, panelHeight, "fill:#f7f7f7")
+	s.Text(0, -8, title, "text-anchor:start;font-size:14px;"+chartFont)
+	legendX := plotWidth
+	for i := len(curves) - 1; i >= 0; i-- {
+		s.Text(legendX, -8, curves[i].name, fmt.Sprintf("text-anchor:end;font-size:12px;fill:%s;%s", curves[i].color, chartFont))
+		legendX -= 80
+	}
+	s.Text(-6, 10, fmt.Sprintf("%.4g", maxY), "text-anchor:end;font-size:10px;"+chartFont)
+	s.Text(-6, panelHeight, "0", "text-anchor:end;font-size:10px;"+chartFont)
+
+	tick := tickInterval(report.Duration)
+	for t := time.Duration(0); t <= report.Duration; t += tick {
+		x := elapsedX(t, report.Duration)
+		s.Line(x, panelHeight, x, panelHeight+4, "stroke:#999")
+		s.Text(x, panelHeight+16, tickLabel(t), "text-anchor:middle;font-size:10px;"+chartFont)
+	}
+
+	for _, c := range curves {
+		xs, ys := []int{}, []int{}
+		for i, v := range c.values {
+			xs = append(xs, elapsedX(report.Samples[i].Elapsed, report.Duration))
+			ys = append(ys, panelHeight-int(v/maxY*float64(panelHeight)))
+		}
+		if len(xs) > 0 {
+			s.Polyline(xs, ys, fmt.Sprintf("fill:none;stroke:%s;stroke-width:1.5", c.color))
+		}
+	}
+
+	s.Gend()
+}
+
+func elapsedX(elapsed, duration time.Duration) int {
+	if duration == 0 {
+		return 0
+	}
+	return int(float64(elapsed) / float64(duration) * float64(plotWidth))
+}
+
+// tickInterval is the shortest of some round intervals that puts at most 12
+// ticks on the timeline
+func tickInterval(duration time.Duration) time.Duration {
+	for _, interval := range []time.Duration{time.Minute, 5 * time.Minute, 10 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour, 6 * time.Hour, 12 * time.Hour} {
+		if duration/interval <= 12 {
+			return interval
+		}
+	}
+	return 24 * time.Hour
+}
+
+func tickLabel(t time.Duration) string {
+	if t%time.Hour == 0 {
+		return fmt.Sprintf("%dh", t/time.Hour)
+	}
+	return fmt.Sprintf("%dm", t/time.Minute)
+}
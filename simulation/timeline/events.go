@@ -0,0 +1,81 @@
+package timeline
+
+import (
+	"container/heap"
+	"time"
+)
+
+// phase orders events at the same point in the timeline: the cluster
+// changes first, then the converger and the auctioneer act on the changes,
+// and then the report samples the result
+type phase int
+
+const (
+	changePhase phase = iota
+	convergePhase
+	auctionPhase
+	reportPhase
+)
+
+// event fires at a point in the timeline.  Events at the same point and in
+// the same phase fire in the order they were scheduled.
+type event struct {
+	at    time.Duration
+	phase phase
+	seq   int
+	fire  func() error
+}
+
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	if q[i].phase != q[j].phase {
+		return q[i].phase < q[j].phase
+	}
+	return q[i].seq < q[j].seq
+}
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x interface{}) {
+	*q = append(*q, x.(*event))
+}
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+func (s *Simulator) at(at time.Duration, phase phase, fire func() error) {
+	s.seq++
+	heap.Push(&s.events, &event{at: at, phase: phase, seq: s.seq, fire: fire})
+}
+
+// after changes the cluster d from now
+func (s *Simulator) after(d time.Duration, fire func() error) {
+	s.at(s.elapsed()+d, changePhase, fire)
+}
+
+// every fires first and then every interval after it until the end of the
+// timeline, and once more at the end if the intervals don't land on it
+func (s *Simulator) every(first, interval time.Duration, phase phase, fire func() error) {
+	end := time.Duration(s.spec.Duration)
+
+	var tick func(at time.Duration)
+	tick = func(at time.Duration) {
+		s.at(at, phase, func() error {
+			next := at + interval
+			if next > end && at < end {
+				next = end
+			}
+			tick(next)
+			return fire()
+		})
+	}
+	tick(first)
+}
@@ -0,0 +1,209 @@
+package timeline
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/simulation/visualization"
+)
+
+// Report is how a timeline played out: Samples of the cluster every sample
+// interval, Summaries every summary interval, and a Total whose counts and
+// wait times cover the whole timeline.
+type Report struct {
+	Name          string
+	Seed          int64
+	Deterministic bool
+	Duration      time.Duration
+	Rounds        int
+
+	AppsArrived  int
+	AppsDeparted int
+	TasksArrived int
+
+	CellsJoined         int
+	CellsEvacuated      int
+	CellsCrashed        int
+	EvacuationsTimedOut int
+
+	Samples   []Sample
+	Summaries []Sample
+	Total     Sample
+}
+
+// Sample is the state of the cluster at Elapsed, and the placements, failed
+// placements, completed tasks and wait times since the previous sample.
+// Evacuating instances count as pending until their replacements are placed.
+type Sample struct {
+	Elapsed time.Duration
+
+	Cells                int
+	EvacuatingCells      int
+	MemoryUtilization    float64
+	ContainerUtilization float64
+
+	RunningLRPs  int
+	PendingLRPs  int
+	RunningTasks int
+	PendingTasks int
+
+	Placements       int
+	FailedPlacements int
+	CompletedTasks   int
+	WaitTimes        WaitTimes
+}
+
+// WaitTimes are the times between work wanting a cell, because it arrived,
+// or its cell was evacuated or crashed, and the work being placed
+type WaitTimes struct {
+	Count  int
+	Mean   time.Duration
+	Median time.Duration
+	P95    time.Duration
+	Max    time.Duration
+}
+
+type window struct {
+	placements       int
+	failedPlacements int
+	completedTasks   int
+	waitTimes        []time.Duration
+}
+
+func (s *Simulator) record(update func(*window)) {
+	update(&s.sampleWindow)
+	update(&s.summaryWindow)
+	update(&s.totalWindow)
+}
+
+func (s *Simulator) placed(waitTime time.Duration) {
+	s.record(func(w *window) {
+		w.placements++
+		w.waitTimes = append(w.waitTimes, waitTime)
+	})
+}
+
+// snapshot samples the cluster and empties the window
+func (s *Simulator) snapshot(w *window) Sample {
+	sample := Sample{
+		Elapsed:          s.elapsed(),
+		Placements:       w.placements,
+		FailedPlacements: w.failedPlacements,
+		CompletedTasks:   w.completedTasks,
+		WaitTimes:        newWaitTimes(w.waitTimes),
+	}
+	*w = window{}
+
+	var totalMemoryMB, usedMemoryMB, totalContainers, usedContainers int
+	for _, c := range s.cells {
+		state, _ := c.rep.State()
+		totalMemoryMB += state.TotalResources.MemoryMB
+		usedMemoryMB += state.TotalResources.MemoryMB - state.AvailableResources.MemoryMB
+		totalContainers += state.TotalResources.Containers
+		usedContainers += state.TotalResources.Containers - state.AvailableResources.Containers
+
+		sample.Cells++
+		if c.evacuating {
+			sample.EvacuatingCells++
+		}
+	}
+	sample.MemoryUtilization = fraction(usedMemoryMB, totalMemoryMB)
+	sample.ContainerUtilization = fraction(usedContainers, totalContainers)
+
+	for _, a := range s.apps {
+		for _, inst := range a.instances {
+			if inst.state == running {
+				sample.RunningLRPs++
+			} else {
+				sample.PendingLRPs++
+			}
+		}
+	}
+	for _, t := range s.tasks {
+		if t.state == running {
+			sample.RunningTasks++
+		} else {
+			sample.PendingTasks++
+		}
+	}
+
+	return sample
+}
+
+func fraction(used, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) / float64(total)
+}
+
+func newWaitTimes(durations []time.Duration) WaitTimes {
+	if len(durations) == 0 {
+		return WaitTimes{}
+	}
+
+	sorted := append([]time.Duration{}, durations...)
+	sort.Sort(durationSlice(sorted))
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+
+	return WaitTimes{
+		Count:  len(sorted),
+		Mean:   total / time.Duration(len(sorted)),
+		Median: percentile(sorted, 0.5),
+		P95:    percentile(sorted, 0.95),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+type durationSlice []time.Duration
+
+func (d durationSlice) Len() int           { return len(d) }
+func (d durationSlice) Less(i, j int) bool { return d[i] < d[j] }
+func (d durationSlice) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+func PrintReport(report *Report) {
+	fmt.Printf("Played %s of %s in %d auction rounds, seed %d, %s scheduling\n", report.Duration, report.Name, report.Rounds, report.Seed, visualization.SchedulingMode(report.Deterministic))
+	fmt.Printf("  %d apps arrived and %d departed, %d tasks arrived and %d completed\n", report.AppsArrived, report.AppsDeparted, report.TasksArrived, report.Total.CompletedTasks)
+	fmt.Printf("  %d cells joined, %d evacuated (%d timed out) and %d crashed\n", report.CellsJoined, report.CellsEvacuated, report.EvacuationsTimedOut, report.CellsCrashed)
+	fmt.Println()
+
+	fmt.Printf("%10s %6s %6s %6s %15s %15s %8s %8s %10s %10s %10s\n", "elapsed", "cells", "mem", "ctrs", "lrps run/pend", "tasks run/pend", "placed", "failed", "wait p50", "p95", "max")
+	for _, summary := range report.Summaries {
+		printSample(summary.Elapsed.String(), summary)
+	}
+	printSample("total", report.Total)
+}
+
+func printSample(label string, sample Sample) {
+	fmt.Printf("%10s %6d %5.1f%% %5.1f%% %15s %15s %8d %8d %10s %10s %10s\n",
+		label,
+		sample.Cells,
+		sample.MemoryUtilization*100,
+		sample.ContainerUtilization*100,
+		fmt.Sprintf("%d/%d", sample.RunningLRPs, sample.PendingLRPs),
+		fmt.Sprintf("%d/%d", sample.RunningTasks, sample.PendingTasks),
+		sample.Placements,
+		sample.FailedPlacements,
+		roundDuration(sample.WaitTimes.Median),
+		roundDuration(sample.WaitTimes.P95),
+		roundDuration(sample.WaitTimes.Max),
+	)
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d - d%time.Millisecond
+}
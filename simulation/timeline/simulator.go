@@ -0,0 +1,753 @@
+package timeline
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/auctionrunner"
+	"github.com/cloudfoundry-incubator/auction/auctiontypes"
+	"github.com/cloudfoundry-incubator/auction/simulation/scenario"
+	"github.com/cloudfoundry-incubator/auction/simulation/simulationrep"
+	"github.com/cloudfoundry-incubator/auction/simulation/util"
+	"github.com/cloudfoundry-incubator/runtime-schema/models"
+	"github.com/cloudfoundry/gunk/workpool"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+// epoch is where every timeline starts on its fake clock
+var epoch = time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// cellRep is a simulation rep whose work the timeline can finish or stop,
+// and which it can evacuate
+type cellRep interface {
+	auctiontypes.SimulationCellRep
+
+	Evacuate()
+	StopLRP(processGuid string, index int) error
+	CompleteTask(taskGuid string) error
+}
+
+type workState int
+
+const (
+	// unclaimed work waits for the converger to resubmit it
+	unclaimed workState = iota
+	// ready work waits for the next auction round
+	ready
+	running
+)
+
+type cell struct {
+	guid       string
+	rep        cellRep
+	evacuating bool
+	lrps       map[string]*instance
+	tasks      map[string]*task
+}
+
+type app struct {
+	guid      string
+	memoryMB  int
+	diskMB    int
+	rootFS    string
+	instances []*instance
+	departed  bool
+}
+
+// instance is one of an app's LRP instances.  An instance being evacuated is
+// ready to be placed elsewhere while it still runs on its evacuating cell.
+type instance struct {
+	app   *app
+	index int
+	state workState
+	cell  string
+	since time.Time
+}
+
+func (i *instance) identifier() string {
+	return auctiontypes.IdentifierForLRP(i.app.guid, i.index)
+}
+
+// task runs for duration once placed.  Its attempt changes whenever it is
+// lost with its cell, so that the completion of a lost attempt is ignored.
+type task struct {
+	guid     string
+	memoryMB int
+	diskMB   int
+	rootFS   string
+	duration time.Duration
+	state    workState
+	cell     string
+	since    time.Time
+	attempt  int
+}
+
+// Simulator plays a timeline against in-process simulation reps and a real
+// auction runner, one event at a time on a fake clock.  Between events the
+// runner is idle, and each auction round runs to completion before the
+// clock moves on.
+type Simulator struct {
+	spec           Spec
+	workers        int
+	resultsTimeout time.Duration
+	logger         lager.Logger
+
+	clock    *fakeclock.FakeClock
+	events   eventQueue
+	seq      int
+	runner   auctiontypes.AuctionRunner
+	delegate *runnerDelegate
+
+	cells    map[string]*cell
+	numCells int
+	apps     map[string]*app
+	tasks    map[string]*task
+
+	readyLRPs      []*instance
+	unclaimedLRPs  []*instance
+	readyTasks     []*task
+	unclaimedTasks []*task
+
+	sampleWindow  window
+	summaryWindow window
+	totalWindow   window
+	report        *Report
+}
+
+// New builds a simulator for a timeline whose defaults have been filled in.
+// ResultsTimeout bounds how long, in real time, an auction round may take.
+func New(spec Spec, workers int, resultsTimeout time.Duration, logger lager.Logger) *Simulator {
+	return &Simulator{
+		spec:           spec,
+		workers:        workers,
+		resultsTimeout: resultsTimeout,
+		logger:         logger.Session("timeline"),
+	}
+}
+
+// Run plays the timeline from the start.  Its randomness is drawn from
+// util.R, restarted from its seed.  If a seed was given the scheduler runs
+// deterministically, so a timeline run twice with the same seed reports the
+// same curves; otherwise it runs concurrently, as in production.
+func (s *Simulator) Run() (*Report, error) {
+	util.Reset()
+	s.reset()
+
+	workPool := workpool.NewWorkPool(s.workers)
+	defer workPool.Stop()
+
+	for _, spec := range s.spec.Cells.Cells() {
+		s.addCell(spec)
+	}
+
+	s.delegate = newRunnerDelegate()
+	config := scenario.AuctionRunnerConfig(s.cellGuids())
+	s.runner = auctionrunner.NewWithConfig(s.delegate, metricEmitterDelegate{}, s.clock, workPool, s.logger, config)
+	runnerProcess := ifrit.Invoke(s.runner)
+	defer func() {
+		runnerProcess.Signal(os.Interrupt)
+		s.delegate.stop()
+		<-runnerProcess.Wait()
+	}()
+
+	s.scheduleTimeline()
+
+	end := time.Duration(s.spec.Duration)
+	for s.events.Len() > 0 {
+		e := heap.Pop(&s.events).(*event)
+		if e.at > end {
+			break
+		}
+
+		s.clock.Increment(e.at - s.elapsed())
+		err := e.fire()
+		if err != nil {
+			return nil, fmt.Errorf("at %s: %s", e.at, err)
+		}
+		s.retireEvacuatedCells()
+	}
+
+	s.report.Total = s.snapshot(&s.totalWindow)
+	return s.report, nil
+}
+
+func (s *Simulator) reset() {
+	s.clock = fakeclock.NewFakeClock(epoch)
+	s.events = eventQueue{}
+	s.seq = 0
+
+	s.cells = map[string]*cell{}
+	s.numCells = 0
+	s.apps = map[string]*app{}
+	s.tasks = map[string]*task{}
+
+	s.readyLRPs = nil
+	s.unclaimedLRPs = nil
+	s.readyTasks = nil
+	s.unclaimedTasks = nil
+
+	s.sampleWindow = window{}
+	s.summaryWindow = window{}
+	s.totalWindow = window{}
+	s.report = &Report{
+		Name:          s.spec.Name,
+		Seed:          util.CurrentSeed(),
+		Deterministic: util.Seeded(),
+		Duration:      time.Duration(s.spec.Duration),
+	}
+}
+
+func (s *Simulator) elapsed() time.Duration {
+	return s.clock.Now().Sub(epoch)
+}
+
+// cellGuids is every cell the timeline will ever have
+func (s *Simulator) cellGuids() []string {
+	numCells := s.spec.Cells.Size()
+	for _, event := range s.spec.CellEvents {
+		if event.Action == CellJoin {
+			numCells += event.Count
+		}
+	}
+
+	guids := []string{}
+	for i := 0; i < numCells; i++ {
+		guids = append(guids, scenario.CellGuid(i))
+	}
+	return guids
+}
+
+func (s *Simulator) scheduleTimeline() {
+	for _, event := range s.spec.CellEvents {
+		event := event
+		s.at(time.Duration(event.At), changePhase, func() error {
+			s.changeCells(event)
+			return nil
+		})
+	}
+
+	for _, stream := range s.spec.LRPs {
+		stream := stream
+		s.arrive(stream.Arrivals, func() {
+			s.arriveLRP(stream)
+		})
+	}
+
+	for _, stream := range s.spec.Tasks {
+		stream := stream
+		s.arrive(stream.Arrivals, func() {
+			s.arriveTask(stream)
+		})
+	}
+
+	s.every(0, time.Duration(s.spec.AuctionInterval), auctionPhase, s.auction)
+	s.every(time.Duration(s.spec.ConvergenceInterval), time.Duration(s.spec.ConvergenceInterval), convergePhase, s.converge)
+	s.every(0, time.Duration(s.spec.SampleInterval), reportPhase, func() error {
+		s.report.Samples = append(s.report.Samples, s.snapshot(&s.sampleWindow))
+		return nil
+	})
+	s.every(time.Duration(s.spec.SummaryInterval), time.Duration(s.spec.SummaryInterval), reportPhase, func() error {
+		s.report.Summaries = append(s.report.Summaries, s.snapshot(&s.summaryWindow))
+		return nil
+	})
+}
+
+// arrive calls arrival for each of the arrivals: the initial ones at once,
+// then the rest with exponentially distributed gaps
+func (s *Simulator) arrive(arrivals Arrivals, arrival func()) {
+	s.at(time.Duration(arrivals.Start), changePhase, func() error {
+		for i := 0; i < arrivals.Initial; i++ {
+			arrival()
+		}
+		return nil
+	})
+
+	if arrivals.RatePerHour == 0 {
+		return
+	}
+
+	end := time.Duration(arrivals.End)
+	if end == 0 {
+		end = time.Duration(s.spec.Duration)
+	}
+	meanGap := time.Duration(float64(time.Hour) / arrivals.RatePerHour)
+
+	var next func(at time.Duration)
+	next = func(at time.Duration) {
+		if at > end {
+			return
+		}
+		s.at(at, changePhase, func() error {
+			arrival()
+			next(at + exponential(meanGap))
+			return nil
+		})
+	}
+	next(time.Duration(arrivals.Start) + exponential(meanGap))
+}
+
+func (s *Simulator) arriveLRP(stream LRPStream) {
+	a := &app{
+		guid:     util.NewGuid("app"),
+		memoryMB: between(stream.MemoryMB, stream.MaxMemoryMB),
+		diskMB:   stream.DiskMB,
+		rootFS:   stream.RootFS,
+	}
+
+	numInstances := between(stream.Instances, stream.MaxInstances)
+	for i := 0; i < numInstances; i++ {
+		inst := &instance{app: a, index: i, state: ready, since: s.clock.Now()}
+		a.instances = append(a.instances, inst)
+		s.readyLRPs = append(s.readyLRPs, inst)
+	}
+
+	s.apps[a.guid] = a
+	s.report.AppsArrived++
+
+	if stream.Lifetime > 0 {
+		s.after(exponential(time.Duration(stream.Lifetime)), func() error {
+			s.departLRP(a)
+			return nil
+		})
+	}
+}
+
+func (s *Simulator) departLRP(a *app) {
+	a.departed = true
+	for _, inst := range a.instances {
+		if inst.cell != "" {
+			s.stopLRP(inst)
+		}
+	}
+
+	delete(s.apps, a.guid)
+	s.report.AppsDeparted++
+}
+
+func (s *Simulator) arriveTask(stream TaskStream) {
+	t := &task{
+		guid:     util.NewGuid("task"),
+		memoryMB: between(stream.MemoryMB, stream.MaxMemoryMB),
+		diskMB:   stream.DiskMB,
+		rootFS:   stream.RootFS,
+		duration: exponential(time.Duration(stream.Duration)),
+		state:    ready,
+		since:    s.clock.Now(),
+	}
+
+	s.tasks[t.guid] = t
+	s.readyTasks = append(s.readyTasks, t)
+	s.report.TasksArrived++
+}
+
+func (s *Simulator) completeTask(t *task, attempt int) {
+	if t.attempt != attempt || t.state != running {
+		return
+	}
+
+	c := s.cells[t.cell]
+	c.rep.CompleteTask(t.guid)
+	delete(c.tasks, t.guid)
+	delete(s.tasks, t.guid)
+
+	s.record(func(w *window) {
+		w.completedTasks++
+	})
+}
+
+// auction submits the ready work to the auction runner and waits for the
+// round's results.  Work that fails to place is left to the converger.
+func (s *Simulator) auction() error {
+	lrps := []*instance{}
+	starts := []models.LRPStartRequest{}
+	for _, inst := range s.readyLRPs {
+		if inst.app.departed || inst.state != ready {
+			continue
+		}
+		lrps = append(lrps, inst)
+		starts = append(starts, models.LRPStartRequest{
+			DesiredLRP: models.DesiredLRP{
+				ProcessGuid: inst.app.guid,
+				MemoryMB:    inst.app.memoryMB,
+				DiskMB:      inst.app.diskMB,
+				RootFS:      inst.app.rootFS,
+				Domain:      "domain",
+			},
+			Indices: []uint{uint(inst.index)},
+		})
+	}
+
+	tasks := []*task{}
+	taskRequests := []models.Task{}
+	for _, t := range s.readyTasks {
+		if t.state != ready {
+			continue
+		}
+		tasks = append(tasks, t)
+		taskRequests = append(taskRequests, models.Task{
+			TaskGuid: t.guid,
+			MemoryMB: t.memoryMB,
+			DiskMB:   t.diskMB,
+			RootFS:   t.rootFS,
+			Domain:   "domain",
+		})
+	}
+
+	s.readyLRPs = nil
+	s.readyTasks = nil
+	if len(starts) == 0 && len(taskRequests) == 0 {
+		return nil
+	}
+
+	if len(starts) > 0 {
+		s.runner.ScheduleLRPsForAuctions(starts)
+	}
+	if len(taskRequests) > 0 {
+		s.runner.ScheduleTasksForAuctions(taskRequests)
+	}
+
+	results, err := s.delegate.auction(s.cellReps(), s.resultsTimeout)
+	if err != nil {
+		return err
+	}
+	s.report.Rounds++
+
+	// settle the work in the order it was submitted, not the order of the
+	// results, so that the converger resubmits it in the same order every run
+	winners := map[string]string{}
+	for _, auction := range results.SuccessfulLRPs {
+		winners[auction.Identifier()] = auction.Winner
+	}
+	for _, auction := range results.AlreadyRunningLRPs {
+		winners[auction.Identifier()] = auction.Winner
+	}
+	for _, auction := range results.SuccessfulTasks {
+		winners[auction.Identifier()] = auction.Winner
+	}
+	for _, auction := range results.AlreadyRunningTasks {
+		winners[auction.Identifier()] = auction.Winner
+	}
+
+	for _, inst := range lrps {
+		if c, ok := s.cells[winners[inst.identifier()]]; ok {
+			s.placeLRP(inst, c)
+			continue
+		}
+		inst.state = unclaimed
+		s.unclaimedLRPs = append(s.unclaimedLRPs, inst)
+		s.record(func(w *window) {
+			w.failedPlacements++
+		})
+	}
+
+	for _, t := range tasks {
+		if c, ok := s.cells[winners[t.guid]]; ok {
+			s.placeTask(t, c)
+			continue
+		}
+		t.state = unclaimed
+		s.unclaimedTasks = append(s.unclaimedTasks, t)
+		s.record(func(w *window) {
+			w.failedPlacements++
+		})
+	}
+
+	return nil
+}
+
+func (s *Simulator) placeLRP(inst *instance, c *cell) {
+	if inst.cell != "" {
+		// the replacement for an evacuating instance is running
+		s.stopLRP(inst)
+	}
+
+	inst.state = running
+	inst.cell = c.guid
+	c.lrps[inst.identifier()] = inst
+
+	s.placed(s.clock.Now().Sub(inst.since))
+}
+
+func (s *Simulator) stopLRP(inst *instance) {
+	c, ok := s.cells[inst.cell]
+	if ok {
+		c.rep.StopLRP(inst.app.guid, inst.index)
+		delete(c.lrps, inst.identifier())
+	}
+	inst.cell = ""
+}
+
+func (s *Simulator) placeTask(t *task, c *cell) {
+	t.state = running
+	t.cell = c.guid
+	c.tasks[t.guid] = t
+
+	s.placed(s.clock.Now().Sub(t.since))
+
+	attempt := t.attempt
+	s.after(t.duration, func() error {
+		s.completeTask(t, attempt)
+		return nil
+	})
+}
+
+// converge resubmits the work that failed to place or was lost with its
+// cell
+func (s *Simulator) converge() error {
+	for _, inst := range s.unclaimedLRPs {
+		if inst.app.departed || inst.state != unclaimed {
+			continue
+		}
+		inst.state = ready
+		s.readyLRPs = append(s.readyLRPs, inst)
+	}
+
+	for _, t := range s.unclaimedTasks {
+		if t.state != unclaimed {
+			continue
+		}
+		t.state = ready
+		s.readyTasks = append(s.readyTasks, t)
+	}
+
+	s.unclaimedLRPs = nil
+	s.unclaimedTasks = nil
+	return nil
+}
+
+func (s *Simulator) changeCells(event CellEvent) {
+	switch event.Action {
+	case CellJoin:
+		for _, spec := range event.joining(s.spec.Cells).Cells() {
+			s.addCell(spec)
+			s.report.CellsJoined++
+		}
+	case CellEvacuate:
+		for _, c := range s.pickCells(event) {
+			s.evacuate(c)
+			s.report.CellsEvacuated++
+		}
+	case CellCrash:
+		for _, c := range s.pickCells(event) {
+			s.crash(c)
+			s.report.CellsCrashed++
+		}
+	}
+}
+
+// addCell builds a rep for the cell, numbered after every cell built before
+// it
+func (s *Simulator) addCell(spec scenario.CellSpec) {
+	guid := scenario.CellGuid(s.numCells)
+	s.numCells++
+
+	rep := simulationrep.NewWithRootFSProviders(spec.RootFSProviders(), spec.Zone, spec.Resources).(cellRep)
+	s.cells[guid] = &cell{
+		guid:  guid,
+		rep:   rep,
+		lrps:  map[string]*instance{},
+		tasks: map[string]*task{},
+	}
+}
+
+// pickCells is the running cells an evacuation or crash acts on
+func (s *Simulator) pickCells(event CellEvent) []*cell {
+	cells := []*cell{}
+
+	if len(event.Guids) > 0 {
+		for _, guid := range event.Guids {
+			c, ok := s.cells[guid]
+			if !ok || c.evacuating {
+				s.logger.Info("skipping-cell-that-is-not-running", lager.Data{"cell": guid, "action": event.Action})
+				continue
+			}
+			cells = append(cells, c)
+		}
+		return cells
+	}
+
+	candidates := []*cell{}
+	for _, guid := range sortedCellGuids(s.cells) {
+		if !s.cells[guid].evacuating {
+			candidates = append(candidates, s.cells[guid])
+		}
+	}
+	for _, i := range util.R.Perm(len(candidates)) {
+		if len(cells) == event.Count {
+			break
+		}
+		cells = append(cells, candidates[i])
+	}
+	return cells
+}
+
+// evacuate stops auctions placing work on the cell and auctions its
+// instances elsewhere straight away.  Its tasks run to completion, and it
+// leaves the fleet once it is empty or crashes once the evacuation times out.
+func (s *Simulator) evacuate(c *cell) {
+	c.evacuating = true
+	c.rep.Evacuate()
+
+	for _, identifier := range sortedIdentifiers(c.lrps) {
+		inst := c.lrps[identifier]
+		if inst.state == running {
+			inst.state = ready
+			inst.since = s.clock.Now()
+			s.readyLRPs = append(s.readyLRPs, inst)
+		}
+	}
+
+	s.after(time.Duration(s.spec.EvacuationTimeout), func() error {
+		if s.cells[c.guid] == c {
+			s.logger.Info("evacuation-timed-out", lager.Data{"cell": c.guid})
+			s.crash(c)
+			s.report.EvacuationsTimedOut++
+		}
+		return nil
+	})
+}
+
+// crash removes the cell from the fleet, leaving its work for the converger
+func (s *Simulator) crash(c *cell) {
+	delete(s.cells, c.guid)
+
+	for _, identifier := range sortedIdentifiers(c.lrps) {
+		inst := c.lrps[identifier]
+		inst.cell = ""
+		if inst.state == running {
+			inst.state = unclaimed
+			inst.since = s.clock.Now()
+			s.unclaimedLRPs = append(s.unclaimedLRPs, inst)
+		}
+	}
+
+	for _, guid := range sortedTaskGuids(c.tasks) {
+		t := c.tasks[guid]
+		t.cell = ""
+		t.state = unclaimed
+		t.since = s.clock.Now()
+		t.attempt++
+		s.unclaimedTasks = append(s.unclaimedTasks, t)
+	}
+}
+
+func (s *Simulator) retireEvacuatedCells() {
+	for guid, c := range s.cells {
+		if c.evacuating && len(c.lrps) == 0 && len(c.tasks) == 0 {
+			delete(s.cells, guid)
+		}
+	}
+}
+
+func (s *Simulator) cellReps() map[string]auctiontypes.CellRep {
+	reps := map[string]auctiontypes.CellRep{}
+	for guid, c := range s.cells {
+		reps[guid] = c.rep
+	}
+	return reps
+}
+
+func sortedCellGuids(cells map[string]*cell) []string {
+	guids := []string{}
+	for guid := range cells {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+	return guids
+}
+
+func sortedIdentifiers(lrps map[string]*instance) []string {
+	identifiers := []string{}
+	for identifier := range lrps {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+	return identifiers
+}
+
+func sortedTaskGuids(tasks map[string]*task) []string {
+	guids := []string{}
+	for guid := range tasks {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+	return guids
+}
+
+func exponential(mean time.Duration) time.Duration {
+	return time.Duration(util.R.ExpFloat64() * float64(mean))
+}
+
+func between(min, max int) int {
+	if max > min {
+		return util.RandomIntIn(min, max)
+	}
+	return min
+}
+
+// runnerDelegate hands the auction runner the cells for one round at a time:
+// the runner waits in FetchCellReps until the simulator has submitted the
+// round's work, so every round drains all of it
+type runnerDelegate struct {
+	rounds  chan map[string]auctiontypes.CellRep
+	results chan auctiontypes.AuctionResults
+	done    chan struct{}
+}
+
+func newRunnerDelegate() *runnerDelegate {
+	return &runnerDelegate{
+		rounds:  make(chan map[string]auctiontypes.CellRep),
+		results: make(chan auctiontypes.AuctionResults, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (d *runnerDelegate) FetchCellReps() (map[string]auctiontypes.CellRep, error) {
+	select {
+	case cells := <-d.rounds:
+		return cells, nil
+	case <-d.done:
+		return map[string]auctiontypes.CellRep{}, nil
+	}
+}
+
+func (d *runnerDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	select {
+	case d.results <- results:
+	case <-d.done:
+	}
+}
+
+func (d *runnerDelegate) auction(cells map[string]auctiontypes.CellRep, timeout time.Duration) (auctiontypes.AuctionResults, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case d.rounds <- cells:
+	case <-timer.C:
+		return auctiontypes.AuctionResults{}, errors.New("auction runner did not start a round")
+	}
+
+	select {
+	case results := <-d.results:
+		return results, nil
+	case <-timer.C:
+		return auctiontypes.AuctionResults{}, fmt.Errorf("auction round took longer than %s", timeout)
+	}
+}
+
+func (d *runnerDelegate) stop() {
+	close(d.done)
+}
+
+type metricEmitterDelegate struct{}
+
+func (metricEmitterDelegate) FetchStatesCompleted(time.Duration) {}
+
+func (metricEmitterDelegate) AuctionCompleted(auctiontypes.AuctionResults) {}
@@ -0,0 +1,221 @@
+package timeline_test
+
+import (
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cloudfoundry-incubator/auction/simulation/scenario"
+	"github.com/cloudfoundry-incubator/auction/simulation/timeline"
+	"github.com/cloudfoundry-incubator/auction/simulation/util"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Simulator", func() {
+	var spec timeline.Spec
+
+	minutes := func(n float64) timeline.Duration {
+		return timeline.Duration(time.Duration(n * float64(time.Minute)))
+	}
+
+	play := func(spec timeline.Spec) *timeline.Report {
+		spec = spec.WithDefaults()
+		Expect(spec.Validate()).To(Succeed())
+
+		report, err := timeline.New(spec, 10, 10*time.Second, lagertest.NewTestLogger("test")).Run()
+		Expect(err).NotTo(HaveOccurred())
+		return report
+	}
+
+	sampleAt := func(report *timeline.Report, elapsed timeline.Duration) timeline.Sample {
+		for _, sample := range report.Samples {
+			if sample.Elapsed == time.Duration(elapsed) {
+				return sample
+			}
+		}
+		Fail("no sample at " + elapsed.String())
+		return timeline.Sample{}
+	}
+
+	BeforeEach(func() {
+		util.Seed(1)
+		spec = timeline.Spec{
+			Name:     "test",
+			Cells:    scenario.Fleet{CellGroup: scenario.CellGroup{Count: 2}},
+			Duration: minutes(30),
+		}
+	})
+
+	AfterEach(func() {
+		util.Seed(time.Now().UnixNano())
+	})
+
+	It("places the apps that arrive in the next auction round", func() {
+		spec.LRPs = []timeline.LRPStream{{Arrivals: timeline.Arrivals{Initial: 3}, Instances: 2, MemoryMB: 10}}
+
+		report := play(spec)
+
+		Expect(report.AppsArrived).To(Equal(3))
+		Expect(report.Rounds).To(Equal(1))
+
+		first := report.Samples[0]
+		Expect(first.Elapsed).To(BeZero())
+		Expect(first.Cells).To(Equal(2))
+		Expect(first.RunningLRPs).To(Equal(6))
+		Expect(first.PendingLRPs).To(BeZero())
+		Expect(first.MemoryUtilization).To(BeNumerically("~", 60.0/200.0))
+		Expect(first.WaitTimes.Max).To(BeZero())
+
+		Expect(report.Samples).To(HaveLen(31))
+		Expect(report.Total.Placements).To(Equal(6))
+		Expect(report.Total.RunningLRPs).To(Equal(6))
+	})
+
+	It("stops apps when they depart", func() {
+		spec.LRPs = []timeline.LRPStream{{Arrivals: timeline.Arrivals{Initial: 5}, Instances: 2, MemoryMB: 10, Lifetime: minutes(1)}}
+		spec.Duration = timeline.Duration(10 * time.Hour)
+
+		report := play(spec)
+
+		Expect(report.AppsDeparted).To(Equal(5))
+		Expect(report.Total.RunningLRPs).To(BeZero())
+		Expect(report.Total.MemoryUtilization).To(BeZero())
+	})
+
+	It("completes tasks after they have run", func() {
+		spec.Tasks = []timeline.TaskStream{{Arrivals: timeline.Arrivals{Initial: 4}, MemoryMB: 10, Duration: minutes(1)}}
+		spec.Duration = timeline.Duration(10 * time.Hour)
+
+		report := play(spec)
+
+		Expect(report.Samples[0].RunningTasks).To(Equal(4))
+		Expect(report.Total.CompletedTasks).To(Equal(4))
+		Expect(report.Total.RunningTasks).To(BeZero())
+		Expect(report.Total.MemoryUtilization).To(BeZero())
+	})
+
+	It("leaves work that does not fit for the converger to resubmit", func() {
+		spec.Cells.CellGroup.Count = 1
+		spec.Tasks = []timeline.TaskStream{{Arrivals: timeline.Arrivals{Initial: 3}, MemoryMB: 40, Duration: minutes(1)}}
+		spec.Duration = timeline.Duration(10 * time.Hour)
+
+		report := play(spec)
+
+		first := report.Samples[0]
+		Expect(first.RunningTasks).To(Equal(2))
+		Expect(first.PendingTasks).To(Equal(1))
+		Expect(first.FailedPlacements).To(Equal(1))
+
+		Expect(report.Total.CompletedTasks).To(Equal(3))
+		Expect(report.Total.WaitTimes.Max).To(BeNumerically(">=", 30*time.Second))
+	})
+
+	Context("when cells crash", func() {
+		BeforeEach(func() {
+			spec.SampleInterval = timeline.Duration(10 * time.Second)
+			spec.LRPs = []timeline.LRPStream{{Arrivals: timeline.Arrivals{Initial: 1}, Instances: 4, MemoryMB: 10}}
+			spec.CellEvents = []timeline.CellEvent{{At: minutes(10) + timeline.Duration(10*time.Second), Action: timeline.CellCrash, Guids: []string{"REP-1"}}}
+		})
+
+		It("resubmits their work when the converger next runs", func() {
+			report := play(spec)
+
+			Expect(report.CellsCrashed).To(Equal(1))
+
+			crashed := sampleAt(report, minutes(10)+timeline.Duration(20*time.Second))
+			Expect(crashed.Cells).To(Equal(1))
+			Expect(crashed.RunningLRPs).To(Equal(2))
+			Expect(crashed.PendingLRPs).To(Equal(2))
+
+			converged := sampleAt(report, minutes(10)+timeline.Duration(30*time.Second))
+			Expect(converged.RunningLRPs).To(Equal(4))
+			Expect(converged.PendingLRPs).To(BeZero())
+			Expect(converged.WaitTimes.Max).To(Equal(20 * time.Second))
+		})
+	})
+
+	Context("when cells are evacuated", func() {
+		BeforeEach(func() {
+			spec.LRPs = []timeline.LRPStream{{Arrivals: timeline.Arrivals{Initial: 1}, Instances: 4, MemoryMB: 10}}
+			spec.CellEvents = []timeline.CellEvent{{At: minutes(10), Action: timeline.CellEvacuate, Guids: []string{"REP-1"}}}
+		})
+
+		It("places their instances elsewhere straight away and retires them", func() {
+			report := play(spec)
+
+			Expect(report.CellsEvacuated).To(Equal(1))
+
+			evacuated := sampleAt(report, minutes(10))
+			Expect(evacuated.Cells).To(Equal(1))
+			Expect(evacuated.RunningLRPs).To(Equal(4))
+			Expect(evacuated.Placements).To(Equal(2))
+			Expect(evacuated.WaitTimes.Max).To(BeZero())
+			Expect(evacuated.MemoryUtilization).To(BeNumerically("~", 0.4))
+		})
+
+		Context("and their tasks outlast the evacuation timeout", func() {
+			BeforeEach(func() {
+				spec.Cells.CellGroup.Count = 1
+				spec.LRPs = nil
+				spec.Tasks = []timeline.TaskStream{{Arrivals: timeline.Arrivals{Initial: 1}, MemoryMB: 10, Duration: timeline.Duration(100 * time.Hour)}}
+				spec.CellEvents = []timeline.CellEvent{
+					{At: minutes(10), Action: timeline.CellEvacuate, Count: 1},
+					{At: minutes(12), Action: timeline.CellJoin, Count: 1},
+				}
+				spec.EvacuationTimeout = minutes(5)
+			})
+
+			It("treats them as crashed", func() {
+				report := play(spec)
+
+				Expect(report.EvacuationsTimedOut).To(Equal(1))
+				Expect(report.CellsJoined).To(Equal(1))
+
+				Expect(sampleAt(report, minutes(14)).EvacuatingCells).To(Equal(1))
+
+				timedOut := sampleAt(report, minutes(16))
+				Expect(timedOut.Cells).To(Equal(1))
+				Expect(timedOut.EvacuatingCells).To(BeZero())
+				Expect(timedOut.RunningTasks).To(Equal(1))
+			})
+		})
+	})
+
+	It("adds the cells that join", func() {
+		spec.Cells.CellGroup.Count = 1
+		spec.LRPs = []timeline.LRPStream{{Arrivals: timeline.Arrivals{Initial: 3}, MemoryMB: 40}}
+		spec.CellEvents = []timeline.CellEvent{{At: minutes(5), Action: timeline.CellJoin, Count: 1, Group: scenario.CellGroup{MemoryMB: 400}}}
+
+		report := play(spec)
+
+		Expect(sampleAt(report, minutes(4)).PendingLRPs).To(Equal(1))
+
+		joined := sampleAt(report, minutes(6))
+		Expect(joined.Cells).To(Equal(2))
+		Expect(joined.RunningLRPs).To(Equal(3))
+		Expect(joined.MemoryUtilization).To(BeNumerically("~", 120.0/500.0))
+	})
+
+	Describe("randomness", func() {
+		BeforeEach(func() {
+			spec.Cells.CellGroup.Count = 10
+			spec.Duration = timeline.Duration(2 * time.Hour)
+			spec.LRPs = []timeline.LRPStream{{Arrivals: timeline.Arrivals{Initial: 20, RatePerHour: 60}, Instances: 1, MaxInstances: 4, MemoryMB: 5, MaxMemoryMB: 20, Lifetime: minutes(45)}}
+			spec.Tasks = []timeline.TaskStream{{Arrivals: timeline.Arrivals{RatePerHour: 300}, MemoryMB: 5, Duration: minutes(5)}}
+			spec.CellEvents = []timeline.CellEvent{{At: minutes(50), Action: timeline.CellCrash, Count: 2}}
+		})
+
+		It("plays the same timeline every time with the same seed", func() {
+			first := play(spec)
+
+			Expect(first.Seed).To(Equal(int64(1)))
+			Expect(first.Deterministic).To(BeTrue())
+			Expect(play(spec)).To(Equal(first))
+
+			util.Seed(2)
+			Expect(play(spec).Samples).NotTo(Equal(first.Samples))
+		})
+	})
+})
@@ -0,0 +1,330 @@
+package timeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/simulation/scenario"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	CellJoin     = "join"
+	CellEvacuate = "evacuate"
+	CellCrash    = "crash"
+)
+
+// Spec is a fleet of cells and the work that arrives at it over Duration of
+// simulated time.
+//
+// The auctioneer runs a round every AuctionInterval, auctioning the work that
+// arrived since the last one.  Work that fails to place, and work lost when a
+// cell crashes, waits for the converger, which resubmits it every
+// ConvergenceInterval.  Evacuating cells that are still running work after
+// EvacuationTimeout are treated as crashed.
+//
+// The report samples the cluster every SampleInterval and sums it up every
+// SummaryInterval.
+type Spec struct {
+	Name  string         `json:"name" yaml:"name"`
+	Seed  int64          `json:"seed" yaml:"seed"`
+	Cells scenario.Fleet `json:"cells" yaml:"cells"`
+
+	Duration            Duration `json:"duration" yaml:"duration"`
+	AuctionInterval     Duration `json:"auction_interval" yaml:"auction_interval"`
+	ConvergenceInterval Duration `json:"convergence_interval" yaml:"convergence_interval"`
+	EvacuationTimeout   Duration `json:"evacuation_timeout" yaml:"evacuation_timeout"`
+	SampleInterval      Duration `json:"sample_interval" yaml:"sample_interval"`
+	SummaryInterval     Duration `json:"summary_interval" yaml:"summary_interval"`
+
+	LRPs       []LRPStream  `json:"lrps" yaml:"lrps"`
+	Tasks      []TaskStream `json:"tasks" yaml:"tasks"`
+	CellEvents []CellEvent  `json:"cell_events" yaml:"cell_events"`
+}
+
+// Arrivals are Initial arrivals at Start, followed by arrivals at random at
+// RatePerHour until End.  Zero End is the end of the timeline.
+type Arrivals struct {
+	Initial     int      `json:"initial" yaml:"initial"`
+	RatePerHour float64  `json:"rate_per_hour" yaml:"rate_per_hour"`
+	Start       Duration `json:"start" yaml:"start"`
+	End         Duration `json:"end" yaml:"end"`
+}
+
+// LRPStream is apps that arrive with between Instances and MaxInstances
+// instances, each using between MemoryMB and MaxMemoryMB, and run for a random
+// time averaging Lifetime.  Zero Lifetime runs them until the end.  RootFS
+// defaults to the fleet's first stack.
+type LRPStream struct {
+	Arrivals `yaml:",inline"`
+
+	Instances    int      `json:"instances" yaml:"instances"`
+	MaxInstances int      `json:"max_instances" yaml:"max_instances"`
+	MemoryMB     int      `json:"memory_mb" yaml:"memory_mb"`
+	MaxMemoryMB  int      `json:"max_memory_mb" yaml:"max_memory_mb"`
+	DiskMB       int      `json:"disk_mb" yaml:"disk_mb"`
+	RootFS       string   `json:"rootfs" yaml:"rootfs"`
+	Lifetime     Duration `json:"lifetime" yaml:"lifetime"`
+}
+
+// TaskStream is tasks that arrive using between MemoryMB and MaxMemoryMB and
+// run for a random time averaging Duration once placed.
+type TaskStream struct {
+	Arrivals `yaml:",inline"`
+
+	MemoryMB    int      `json:"memory_mb" yaml:"memory_mb"`
+	MaxMemoryMB int      `json:"max_memory_mb" yaml:"max_memory_mb"`
+	DiskMB      int      `json:"disk_mb" yaml:"disk_mb"`
+	RootFS      string   `json:"rootfs" yaml:"rootfs"`
+	Duration    Duration `json:"duration" yaml:"duration"`
+}
+
+// CellEvent changes the fleet At some point in the timeline.  Join adds Count
+// cells built from Group, whose zero fields default to the fleet's.  Evacuate
+// and Crash act on the cells named in Guids or, without Guids, on Count
+// running cells picked at random.
+type CellEvent struct {
+	At     Duration           `json:"at" yaml:"at"`
+	Action string             `json:"action" yaml:"action"`
+	Count  int                `json:"count" yaml:"count"`
+	Guids  []string           `json:"guids" yaml:"guids"`
+	Group  scenario.CellGroup `json:"group" yaml:"group"`
+}
+
+// Duration is a time.Duration written the way time.ParseDuration reads it,
+// such as 90s or 6h
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(payload []byte) error {
+	var s string
+	err := json.Unmarshal(payload, &s)
+	if err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	err := unmarshal(&s)
+	if err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// Load reads a timeline from a JSON file or, if it ends in .yml or .yaml, a
+// YAML file, fills in its defaults and validates it.
+func Load(path string) (Spec, error) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Spec{}, err
+	}
+
+	var spec Spec
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(payload, &spec)
+	default:
+		err = json.Unmarshal(payload, &spec)
+	}
+	if err != nil {
+		return Spec{}, err
+	}
+
+	if spec.Name == "" {
+		base := filepath.Base(path)
+		spec.Name = base[:len(base)-len(filepath.Ext(base))]
+	}
+	spec = spec.WithDefaults()
+
+	err = spec.Validate()
+	if err != nil {
+		return Spec{}, err
+	}
+
+	return spec, nil
+}
+
+// WithDefaults fills in the zero fields of the timeline, its fleet and its
+// streams
+func (s Spec) WithDefaults() Spec {
+	s.Cells = s.Cells.WithDefaults()
+
+	if s.Duration == 0 {
+		s.Duration = Duration(time.Hour)
+	}
+	if s.AuctionInterval == 0 {
+		s.AuctionInterval = Duration(time.Second)
+	}
+	if s.ConvergenceInterval == 0 {
+		s.ConvergenceInterval = Duration(30 * time.Second)
+	}
+	if s.EvacuationTimeout == 0 {
+		s.EvacuationTimeout = Duration(10 * time.Minute)
+	}
+	if s.SampleInterval == 0 {
+		s.SampleInterval = Duration(time.Minute)
+	}
+	if s.SummaryInterval == 0 {
+		s.SummaryInterval = Duration(time.Hour)
+	}
+
+	lrps := []LRPStream{}
+	for _, stream := range s.LRPs {
+		if stream.Instances == 0 {
+			stream.Instances = 1
+		}
+		if stream.DiskMB == 0 {
+			stream.DiskMB = 1
+		}
+		if stream.RootFS == "" {
+			stream.RootFS = s.Cells.DefaultRootFS()
+		}
+		lrps = append(lrps, stream)
+	}
+	s.LRPs = lrps
+
+	tasks := []TaskStream{}
+	for _, stream := range s.Tasks {
+		if stream.DiskMB == 0 {
+			stream.DiskMB = 1
+		}
+		if stream.RootFS == "" {
+			stream.RootFS = s.Cells.DefaultRootFS()
+		}
+		tasks = append(tasks, stream)
+	}
+	s.Tasks = tasks
+
+	return s
+}
+
+func (s Spec) Validate() error {
+	err := s.Cells.Validate()
+	if err != nil {
+		return err
+	}
+
+	if s.Duration <= 0 || s.AuctionInterval <= 0 || s.ConvergenceInterval <= 0 || s.EvacuationTimeout <= 0 || s.SampleInterval <= 0 || s.SummaryInterval <= 0 {
+		return errors.New("timeline needs a positive duration and intervals")
+	}
+	if len(s.LRPs) == 0 && len(s.Tasks) == 0 {
+		return errors.New("timeline has no lrps or tasks")
+	}
+
+	for i, stream := range s.LRPs {
+		err := stream.validate()
+		if err != nil {
+			return fmt.Errorf("lrp stream %d: %s", i+1, err)
+		}
+	}
+	for i, stream := range s.Tasks {
+		err := stream.validate()
+		if err != nil {
+			return fmt.Errorf("task stream %d: %s", i+1, err)
+		}
+	}
+	for i, event := range s.CellEvents {
+		err := event.validate(s.Cells)
+		if err != nil {
+			return fmt.Errorf("cell event %d at %s: %s", i+1, event.At, err)
+		}
+	}
+
+	return nil
+}
+
+func (a Arrivals) validate() error {
+	if a.Initial < 0 || a.RatePerHour < 0 || a.Start < 0 || a.End < 0 {
+		return errors.New("has negative arrivals or times")
+	}
+	if a.Initial == 0 && a.RatePerHour == 0 {
+		return errors.New("has no arrivals")
+	}
+	if a.End != 0 && a.End < a.Start {
+		return errors.New("ends before it starts")
+	}
+	return nil
+}
+
+func (s LRPStream) validate() error {
+	err := s.Arrivals.validate()
+	if err != nil {
+		return err
+	}
+	if s.Instances <= 0 || s.MemoryMB <= 0 || s.DiskMB <= 0 {
+		return errors.New("needs positive instances, memory and disk")
+	}
+	if s.Lifetime < 0 {
+		return errors.New("has a negative lifetime")
+	}
+	if s.RootFS == "" {
+		return errors.New("needs a rootfs, as the fleet has no stacks")
+	}
+	return nil
+}
+
+func (s TaskStream) validate() error {
+	err := s.Arrivals.validate()
+	if err != nil {
+		return err
+	}
+	if s.MemoryMB <= 0 || s.DiskMB <= 0 || s.Duration <= 0 {
+		return errors.New("needs positive memory, disk and duration")
+	}
+	if s.RootFS == "" {
+		return errors.New("needs a rootfs, as the fleet has no stacks")
+	}
+	return nil
+}
+
+func (e CellEvent) validate(fleet scenario.Fleet) error {
+	if e.At < 0 {
+		return errors.New("is at a negative time")
+	}
+
+	switch e.Action {
+	case CellJoin:
+		if e.Count <= 0 {
+			return errors.New("needs a positive count of cells to join")
+		}
+		return e.joining(fleet).Validate()
+	case CellEvacuate, CellCrash:
+		if e.Count <= 0 && len(e.Guids) == 0 {
+			return errors.New("needs a positive count or the guids of the cells")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", e.Action)
+	}
+
+	return nil
+}
+
+// joining is the cells a join adds, as a fleet of their own
+func (e CellEvent) joining(fleet scenario.Fleet) scenario.Fleet {
+	group := e.Group
+	group.Count = e.Count
+	return scenario.Fleet{CellGroup: fleet.CellGroup, Groups: []scenario.CellGroup{group}}.WithDefaults()
+}
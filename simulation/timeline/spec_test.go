@@ -0,0 +1,138 @@
+package timeline_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry-incubator/auction/simulation/timeline"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Spec", func() {
+	var dir string
+
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "timeline")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("Load", func() {
+		It("loads YAML timelines, filling in the defaults", func() {
+			path := write("churn.yml", `
+duration: 6h
+cells: {count: 10}
+lrps:
+  - {initial: 5, rate_per_hour: 30, memory_mb: 8, max_memory_mb: 16, lifetime: 90m}
+tasks:
+  - {rate_per_hour: 600, start: 1h, end: 2h30m, memory_mb: 4, duration: 5m, rootfs: "docker:///busybox"}
+cell_events:
+  - {at: 2h, action: join, count: 2, group: {memory_mb: 400}}
+  - {at: 3h, action: crash, guids: [REP-1]}
+`)
+
+			spec, err := timeline.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(spec.Name).To(Equal("churn"))
+			Expect(spec.Duration).To(Equal(timeline.Duration(6 * time.Hour)))
+			Expect(spec.AuctionInterval).To(Equal(timeline.Duration(time.Second)))
+			Expect(spec.ConvergenceInterval).To(Equal(timeline.Duration(30 * time.Second)))
+			Expect(spec.Cells.Size()).To(Equal(10))
+
+			Expect(spec.LRPs).To(Equal([]timeline.LRPStream{{
+				Arrivals:    timeline.Arrivals{Initial: 5, RatePerHour: 30},
+				Instances:   1,
+				MemoryMB:    8,
+				MaxMemoryMB: 16,
+				DiskMB:      1,
+				RootFS:      "preloaded:lucid64",
+				Lifetime:    timeline.Duration(90 * time.Minute),
+			}}))
+			Expect(spec.Tasks[0].Arrivals).To(Equal(timeline.Arrivals{
+				RatePerHour: 600,
+				Start:       timeline.Duration(time.Hour),
+				End:         timeline.Duration(150 * time.Minute),
+			}))
+			Expect(spec.Tasks[0].RootFS).To(Equal("docker:///busybox"))
+
+			Expect(spec.CellEvents).To(HaveLen(2))
+			Expect(spec.CellEvents[0].Group.MemoryMB).To(Equal(400))
+			Expect(spec.CellEvents[1].Guids).To(Equal([]string{"REP-1"}))
+		})
+
+		It("loads JSON timelines", func() {
+			path := write("tasks.json", `{
+				"name": "tasks",
+				"duration": "30m",
+				"tasks": [{"initial": 10, "memory_mb": 1, "duration": "1m"}]
+			}`)
+
+			spec, err := timeline.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec.Name).To(Equal("tasks"))
+			Expect(spec.Duration).To(Equal(timeline.Duration(30 * time.Minute)))
+			Expect(spec.Tasks[0].Duration).To(Equal(timeline.Duration(time.Minute)))
+		})
+
+		It("rejects durations it can't parse", func() {
+			path := write("bad.yml", `duration: forever`)
+
+			_, err := timeline.Load(path)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects timelines without work", func() {
+			path := write("idle.yml", `duration: 1h`)
+
+			_, err := timeline.Load(path)
+			Expect(err).To(MatchError("timeline has no lrps or tasks"))
+		})
+
+		It("rejects streams without arrivals", func() {
+			path := write("none.yml", `
+lrps:
+  - {memory_mb: 8}
+`)
+
+			_, err := timeline.Load(path)
+			Expect(err).To(MatchError("lrp stream 1: has no arrivals"))
+		})
+
+		It("rejects tasks that don't say how long they run", func() {
+			path := write("forever.yml", `
+tasks:
+  - {initial: 1, memory_mb: 8}
+`)
+
+			_, err := timeline.Load(path)
+			Expect(err).To(MatchError("task stream 1: needs positive memory, disk and duration"))
+		})
+
+		It("rejects unknown cell events", func() {
+			path := write("reboot.yml", `
+lrps:
+  - {initial: 1, memory_mb: 8}
+cell_events:
+  - {at: 10m, action: reboot, count: 1}
+`)
+
+			_, err := timeline.Load(path)
+			Expect(err).To(MatchError(`cell event 1 at 10m0s: unknown action "reboot"`))
+		})
+	})
+})
@@ -11,11 +11,51 @@ import (
 var guidTracker map[string]int
 var lock *sync.Mutex
 var R *rand.Rand
+var seed int64
+var seeded bool
 
 func init() {
 	ResetGuids()
 	lock = &sync.Mutex{}
-	R = rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano())})
+	SeedFromTime()
+}
+
+// Seed restarts R from seed.  Simulations that draw all their randomness from
+// R generate the same workloads every time they are run with the same seed.
+func Seed(s int64) {
+	seed = s
+	seeded = true
+	restart()
+}
+
+// SeedFromTime restarts R from a seed picked from the time, as if no seed had
+// been given
+func SeedFromTime() {
+	seed = time.Now().UnixNano()
+	seeded = false
+	restart()
+}
+
+// Seeded is whether a seed was given, rather than picked from the time
+func Seeded() bool {
+	return seeded
+}
+
+// CurrentSeed is the seed R was last started from; unless Seed is called it
+// is picked from the time
+func CurrentSeed() int64 {
+	return seed
+}
+
+// Reset resets the guids and restarts R from the current seed, so that an
+// experiment draws the same numbers however many experiments ran before it
+func Reset() {
+	ResetGuids()
+	restart()
+}
+
+func restart() {
+	R = rand.New(&lockedSource{src: rand.NewSource(seed)})
 }
 
 func ResetGuids() {
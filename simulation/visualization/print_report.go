@@ -32,7 +32,7 @@ func PrintReport(report *Report) {
 		return
 	}
 
-	fmt.Printf("Finished %d Auctions (%d succeeded, %d failed) among %d Cells in %s\n", report.AuctionsPerformed(), len(report.AuctionResults.SuccessfulLRPs), len(report.AuctionResults.FailedLRPs), len(report.Cells), report.AuctionDuration)
+	fmt.Printf("Finished %d Auctions (%d succeeded, %d failed) among %d Cells in %s, seed %d, %s scheduling\n", report.AuctionsPerformed(), len(report.AuctionResults.SuccessfulLRPs), len(report.AuctionResults.FailedLRPs), len(report.Cells), report.AuctionDuration, report.Seed, SchedulingMode(report.Deterministic))
 	fmt.Println()
 
 	auctionedInstances := map[string]bool{}
@@ -11,6 +11,8 @@ import (
 )
 
 type Report struct {
+	Seed                         int64
+	Deterministic                bool
 	Cells                        map[string]auctiontypes.CellRep
 	NumAuctions                  int
 	AuctionResults               auctiontypes.AuctionResults
@@ -38,9 +40,13 @@ func NewStat(data []float64) Stat {
 	}
 }
 
-func NewReport(numAuctions int, cells map[string]auctiontypes.CellRep, results auctiontypes.AuctionResults, duration time.Duration) *Report {
+// NewReport reports on an auction run whose workload was generated from
+// seed, and whose scheduler ran deterministically or not
+func NewReport(seed int64, deterministic bool, numAuctions int, cells map[string]auctiontypes.CellRep, results auctiontypes.AuctionResults, duration time.Duration) *Report {
 	states := fetchStates(cells)
 	return &Report{
+		Seed:            seed,
+		Deterministic:   deterministic,
 		Cells:           cells,
 		NumAuctions:     numAuctions,
 		AuctionResults:  results,
@@ -50,6 +56,16 @@ func NewReport(numAuctions int, cells map[string]auctiontypes.CellRep, results a
 	}
 }
 
+// SchedulingMode names the way a simulation's scheduler ran: deterministic,
+// for a run that can be reproduced from its seed, or concurrent, as in
+// production
+func SchedulingMode(deterministic bool) string {
+	if deterministic {
+		return "deterministic"
+	}
+	return "concurrent"
+}
+
 func (r *Report) IsAuctionedInstance(inst auctiontypes.LRP) bool {
 	if r.auctionedInstancesByInstGuid == nil {
 		r.auctionedInstancesByInstGuid = map[string]bool{}
@@ -155,6 +171,11 @@ func instancesByRepFromStates(states map[string]auctiontypes.CellState) map[stri
 
 type ByProcessGuid []auctiontypes.LRP
 
-func (a ByProcessGuid) Len() int           { return len(a) }
-func (a ByProcessGuid) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a ByProcessGuid) Less(i, j int) bool { return a[i].ProcessGuid < a[j].ProcessGuid }
+func (a ByProcessGuid) Len() int      { return len(a) }
+func (a ByProcessGuid) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByProcessGuid) Less(i, j int) bool {
+	if a[i].ProcessGuid == a[j].ProcessGuid {
+		return a[i].Index < a[j].Index
+	}
+	return a[i].ProcessGuid < a[j].ProcessGuid
+}
@@ -2,6 +2,7 @@ package visualization
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -62,8 +63,8 @@ func (r *SVGReport) Done() {
 	r.f.Close()
 }
 
-func (r *SVGReport) DrawHeader(communicationMode string) {
-	r.SVG.Text(border, 30, communicationMode, `text-anchor:start;font-size:20px;font-family:Helvetica Neue`)
+func (r *SVGReport) DrawHeader(communicationMode string, seed int64, deterministic bool) {
+	r.SVG.Text(border, 30, fmt.Sprintf("%s, seed %d, %s scheduling", communicationMode, seed, SchedulingMode(deterministic)), `text-anchor:start;font-size:20px;font-family:Helvetica Neue`)
 }
 
 func (r *SVGReport) drawResults() {
@@ -90,28 +91,53 @@ func (r *SVGReport) backgroundColorForZone(zone string) string {
 		return "fill:#ffdddd"
 	case "Z1":
 		return "fill:#ddddff"
+	case "Z2":
+		return "fill:#ddffdd"
 	default:
 		return "fill:#f7f7f7"
 	}
 }
 
+// drawInstances draws a row per cell, as long as the cell has memory: the
+// biggest cell fills the box, and a cell with 100MB as big as the biggest
+// draws each MB as a square.
 func (r *SVGReport) drawInstances(report *Report) {
+	maxMemoryMB := 0
+	for _, state := range report.CellStates {
+		if state.TotalResources.MemoryMB > maxMemoryMB {
+			maxMemoryMB = state.TotalResources.MemoryMB
+		}
+	}
+	if maxMemoryMB == 0 {
+		return
+	}
+	pixelsPerMB := float64(instanceBoxWidth+instanceSpacing) / float64(maxMemoryMB)
+	width := func(memoryMB int) int {
+		w := int(math.Floor(float64(memoryMB)*pixelsPerMB+0.5)) - instanceSpacing
+		if w < 1 {
+			return 1
+		}
+		return w
+	}
+
 	y := border
 	for i := 0; i < len(report.Cells); i++ {
 		guid := cellID(i)
-		x := border
-		bgColor := r.backgroundColorForZone(report.CellStates[cellID(i)].Zone)
-		r.SVG.Rect(x, y, instanceBoxWidth, instanceSize, bgColor)
+		state := report.CellStates[guid]
+		bgColor := r.backgroundColorForZone(state.Zone)
+		r.SVG.Rect(border, y, width(state.TotalResources.MemoryMB), instanceSize, bgColor)
 		instances := report.InstancesByRep[guid]
+		usedMB := 0
 		for _, instance := range instances {
-			instanceWidth := instanceSize*instance.MemoryMB + instanceSpacing*(instance.MemoryMB-1)
+			x := border + int(math.Floor(float64(usedMB)*pixelsPerMB+0.5))
+			instanceWidth := width(instance.MemoryMB)
 			style := instanceStyle(instance.ProcessGuid)
-			if report.IsAuctionedInstance(instance) {
+			if report.IsAuctionedInstance(instance) || instanceWidth <= 2 {
 				r.SVG.Rect(x, y, instanceWidth, instanceSize, style)
 			} else {
 				r.SVG.Rect(x+1, y+1, instanceWidth-2, instanceSize-2, style)
 			}
-			x += instanceWidth + instanceSpacing
+			usedMB += instance.MemoryMB
 		}
 		y += instanceSize + instanceSpacing
 	}